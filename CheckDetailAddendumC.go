@@ -88,6 +88,8 @@ type CheckDetailAddendumC struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCheckDetailAddendumC returns a new CheckDetailAddendumC with default values for non exported fields
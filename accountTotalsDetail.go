@@ -0,0 +1,215 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// AccountTotalsDetail(s) follow the CashLetterHeader and precede the first BundleHeader of a CashLetter.
+//
+// FileHeader
+// CashLetterHeader Record
+// AccountTotalsDetail
+// NonHitTotalsDetail
+// BundleHeader Record
+// ...
+// BundleControl
+// CashLetterControl
+// FileControl
+
+// AccountTotalsDetail Record
+type AccountTotalsDetail struct {
+	// ID is a client defined string used as a reference to this record.
+	ID string `json:"id"`
+	// RecordType defines the type of record.
+	recordType string
+	// DestinationRoutingNumber identifies the institution that receives the totals.
+	// Format: TTTTAAAAC, where:
+	// TTTT: Federal Reserve Prefix
+	// AAAA: ABA Institution Identifier
+	// C: Payor Bank Routing Number Check Digit
+	DestinationRoutingNumber string `json:"destinationRoutingNumber"`
+	// KeyAccountLowAccount is the key account number or the lowest account number in a key account range.
+	KeyAccountLowAccount string `json:"keyAccountLowAccount"`
+	// KeyAccountHighAccount is the key account number or the highest account number in a key account range.
+	KeyAccountHighAccount string `json:"keyAccountHighAccount"`
+	// TotalItemCount identifies the total number of items for the key account or key account range.
+	TotalItemCount int `json:"totalItemCount"`
+	// TotalItemAmount identifies the total amount of items for the key account or key account range.
+	// All amounts fields have two implied decimal points. e.g., 100000 is $1,000.00
+	TotalItemAmount int `json:"totalItemAmount"`
+	// UserField is a field used at the discretion of users of the standard.
+	UserField string `json:"userField"`
+	// reserved is a field reserved for future use.  Reserved should be blank.
+	reserved string
+	// validator is composed for imagecashletter data validation
+	validator
+	// converters is composed for imagecashletter to golang Converters
+	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
+}
+
+// NewAccountTotalsDetail returns a new AccountTotalsDetail with default values for non exported fields
+func NewAccountTotalsDetail() *AccountTotalsDetail {
+	atd := &AccountTotalsDetail{}
+	atd.setRecordType()
+	return atd
+}
+
+func (atd *AccountTotalsDetail) setRecordType() {
+	if atd == nil {
+		return
+	}
+	atd.recordType = "40"
+	atd.reserved = "   "
+}
+
+// Parse takes the input record string and parses the AccountTotalsDetail values
+func (atd *AccountTotalsDetail) Parse(record string) {
+	if utf8.RuneCountInString(record) < 77 {
+		return // line too short
+	}
+	// Character position 1-2, Always "40"
+	atd.setRecordType()
+	// 03-11
+	atd.DestinationRoutingNumber = atd.parseStringField(record[2:11])
+	// 12-29
+	atd.KeyAccountLowAccount = atd.parseStringField(record[11:29])
+	// 30-47
+	atd.KeyAccountHighAccount = atd.parseStringField(record[29:47])
+	// 48-59
+	atd.TotalItemCount = atd.parseNumField(record[47:59])
+	// 60-73
+	atd.TotalItemAmount = atd.parseNumField(record[59:73])
+	// 74-77
+	atd.UserField = atd.parseStringField(record[73:77])
+	// 78-80
+	atd.reserved = "   "
+}
+
+func (atd *AccountTotalsDetail) UnmarshalJSON(data []byte) error {
+	type Alias AccountTotalsDetail
+	aux := struct {
+		*Alias
+	}{
+		(*Alias)(atd),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	atd.setRecordType()
+	return nil
+}
+
+// String writes the AccountTotalsDetail struct to a string.
+func (atd *AccountTotalsDetail) String() string {
+	var buf strings.Builder
+	buf.Grow(80)
+	buf.WriteString(atd.recordType)
+	buf.WriteString(atd.DestinationRoutingNumberField())
+	buf.WriteString(atd.KeyAccountLowAccountField())
+	buf.WriteString(atd.KeyAccountHighAccountField())
+	buf.WriteString(atd.TotalItemCountField())
+	buf.WriteString(atd.TotalItemAmountField())
+	buf.WriteString(atd.UserFieldField())
+	buf.WriteString(atd.reservedField())
+	return buf.String()
+}
+
+// Validate performs imagecashletter format rule checks on the record and returns an error if not Validated
+// The first error encountered is returned and stops the parsing.
+func (atd *AccountTotalsDetail) Validate() error {
+	if err := atd.fieldInclusion(); err != nil {
+		return err
+	}
+	if atd.recordType != "40" {
+		msg := fmt.Sprintf(msgRecordType, 40)
+		return &FieldError{FieldName: "recordType", Value: atd.recordType, Msg: msg}
+	}
+	if err := atd.isNumeric(atd.DestinationRoutingNumber); err != nil {
+		return &FieldError{FieldName: "DestinationRoutingNumber", Value: atd.DestinationRoutingNumber, Msg: err.Error()}
+	}
+	if err := atd.isNumeric(atd.KeyAccountLowAccount); err != nil {
+		return &FieldError{FieldName: "KeyAccountLowAccount", Value: atd.KeyAccountLowAccount, Msg: err.Error()}
+	}
+	if err := atd.isNumeric(atd.KeyAccountHighAccount); err != nil {
+		return &FieldError{FieldName: "KeyAccountHighAccount", Value: atd.KeyAccountHighAccount, Msg: err.Error()}
+	}
+	if err := atd.isAlphanumericSpecial(atd.UserField); err != nil {
+		return &FieldError{FieldName: "UserField", Value: atd.UserField, Msg: err.Error()}
+	}
+	return nil
+}
+
+// fieldInclusion validate mandatory fields are not default values. If fields are
+// invalid the Electronic Exchange will be returned.
+func (atd *AccountTotalsDetail) fieldInclusion() error {
+	if atd.recordType == "" {
+		return &FieldError{FieldName: "recordType",
+			Value: atd.recordType,
+			Msg:   msgFieldInclusion + ", did you use AccountTotalsDetail()?"}
+	}
+	if atd.DestinationRoutingNumber == "" {
+		return &FieldError{FieldName: "DestinationRoutingNumber",
+			Value: atd.DestinationRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use AccountTotalsDetail()?"}
+	}
+	if atd.DestinationRoutingNumberField() == "000000000" {
+		return &FieldError{FieldName: "DestinationRoutingNumber",
+			Value: atd.DestinationRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use AccountTotalsDetail()?"}
+	}
+	if atd.KeyAccountLowAccount == "" {
+		return &FieldError{FieldName: "KeyAccountLowAccount",
+			Value: atd.KeyAccountLowAccount,
+			Msg:   msgFieldInclusion + ", did you use AccountTotalsDetail()?"}
+	}
+	if atd.KeyAccountHighAccount == "" {
+		return &FieldError{FieldName: "KeyAccountHighAccount",
+			Value: atd.KeyAccountHighAccount,
+			Msg:   msgFieldInclusion + ", did you use AccountTotalsDetail()?"}
+	}
+	return nil
+}
+
+// DestinationRoutingNumberField gets the DestinationRoutingNumber field
+func (atd *AccountTotalsDetail) DestinationRoutingNumberField() string {
+	return atd.stringField(atd.DestinationRoutingNumber, 9)
+}
+
+// KeyAccountLowAccountField gets the KeyAccountLowAccount field zero padded
+func (atd *AccountTotalsDetail) KeyAccountLowAccountField() string {
+	return atd.stringField(atd.KeyAccountLowAccount, 18)
+}
+
+// KeyAccountHighAccountField gets the KeyAccountHighAccount field zero padded
+func (atd *AccountTotalsDetail) KeyAccountHighAccountField() string {
+	return atd.stringField(atd.KeyAccountHighAccount, 18)
+}
+
+// TotalItemCountField gets a string of TotalItemCount zero padded
+func (atd *AccountTotalsDetail) TotalItemCountField() string {
+	return atd.numericField(atd.TotalItemCount, 12)
+}
+
+// TotalItemAmountField gets a string of TotalItemAmount zero padded
+func (atd *AccountTotalsDetail) TotalItemAmountField() string {
+	return atd.numericField(atd.TotalItemAmount, 14)
+}
+
+// UserFieldField gets the UserField field
+func (atd *AccountTotalsDetail) UserFieldField() string {
+	return atd.alphaField(atd.UserField, 4)
+}
+
+// reservedField gets reserved - blank space
+func (atd *AccountTotalsDetail) reservedField() string {
+	return atd.alphaField(atd.reserved, 3)
+}
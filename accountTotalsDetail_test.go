@@ -0,0 +1,190 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"strings"
+	"testing"
+)
+
+// mockAccountTotalsDetail creates an AccountTotalsDetail
+func mockAccountTotalsDetail() *AccountTotalsDetail {
+	atd := NewAccountTotalsDetail()
+	atd.DestinationRoutingNumber = "231380104"
+	atd.KeyAccountLowAccount = "123456"
+	atd.KeyAccountHighAccount = "199999"
+	atd.TotalItemCount = 2
+	atd.TotalItemAmount = 200000
+	atd.UserField = ""
+	return atd
+}
+
+// TestMockAccountTotalsDetail creates a AccountTotalsDetail
+func TestMockAccountTotalsDetail(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	if err := v.Validate(); err != nil {
+		t.Error("mockAccountTotalsDetail does not validate and will break other tests: ", err)
+	}
+}
+
+func TestAccountTotalsDetailParseErr(t *testing.T) {
+	var v AccountTotalsDetail
+	v.Parse("asdlahsakjajf")
+	if v.DestinationRoutingNumber != "" {
+		t.Errorf("v.DestinationRoutingNumber=%s", v.DestinationRoutingNumber)
+	}
+}
+
+// TestParseAccountTotalsDetail validates parsing a AccountTotalsDetail
+func TestParseAccountTotalsDetail(t *testing.T) {
+	var line = "4023138010400000000000012345600000000000019999900000000000200000000200000       "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	clh := mockCashLetterHeader()
+	r.addCurrentCashLetter(NewCashLetter(clh))
+	if err := r.parseAccountTotalsDetail(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	record := r.currentCashLetter.GetAccountTotalsDetail()[0]
+
+	if record.recordType != "40" {
+		t.Errorf("RecordType Expected '40' got: %v", record.recordType)
+	}
+	if record.DestinationRoutingNumber != "231380104" {
+		t.Errorf("DestinationRoutingNumber Expected '231380104' got: %v", record.DestinationRoutingNumber)
+	}
+	if record.KeyAccountLowAccount != "000000000000123456" {
+		t.Errorf("KeyAccountLowAccount Expected '000000000000123456' got: %v", record.KeyAccountLowAccount)
+	}
+	if record.KeyAccountHighAccount != "000000000000199999" {
+		t.Errorf("KeyAccountHighAccount Expected '000000000000199999' got: %v", record.KeyAccountHighAccount)
+	}
+	if record.TotalItemCount != 2 {
+		t.Errorf("TotalItemCount Expected '2' got: %v", record.TotalItemCount)
+	}
+	if record.TotalItemAmount != 200000 {
+		t.Errorf("TotalItemAmount Expected '200000' got: %v", record.TotalItemAmount)
+	}
+
+	if record.String() != line {
+		t.Errorf("Strings do not match")
+	}
+}
+
+// TestAccountTotalsDetailParseOutsideCashLetter validation
+func TestAccountTotalsDetailParseOutsideCashLetter(t *testing.T) {
+	var line = "4023138010400000000000012345600000000000019999900000000000200000000200000       "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	if err := r.parseAccountTotalsDetail(); err != nil {
+		if !strings.Contains(err.Error(), msgFileAccountTotalsDetail) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestATDRecordType validation
+func TestATDRecordType(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.recordType = "00"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDDestinationRoutingNumber validation
+func TestATDDestinationRoutingNumber(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.DestinationRoutingNumber = "23138010A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDKeyAccountLowAccount validation
+func TestATDKeyAccountLowAccount(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.KeyAccountLowAccount = "12345A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "KeyAccountLowAccount" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDKeyAccountHighAccount validation
+func TestATDKeyAccountHighAccount(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.KeyAccountHighAccount = "19999A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "KeyAccountHighAccount" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDUserField validation
+func TestATDUserField(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.UserField = "®©"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "UserField" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// Field Inclusion
+
+// TestATDFIRecordType validation
+func TestATDFIRecordType(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.recordType = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDFIDestinationRoutingNumber validation
+func TestATDFIDestinationRoutingNumber(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.DestinationRoutingNumber = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDFIDestinationRoutingNumberZero validation
+func TestATDFIDestinationRoutingNumberZero(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.DestinationRoutingNumber = "000000000"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDFIKeyAccountLowAccount validation
+func TestATDFIKeyAccountLowAccount(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.KeyAccountLowAccount = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "KeyAccountLowAccount" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestATDFIKeyAccountHighAccount validation
+func TestATDFIKeyAccountHighAccount(t *testing.T) {
+	v := mockAccountTotalsDetail()
+	v.KeyAccountHighAccount = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "KeyAccountHighAccount" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
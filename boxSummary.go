@@ -0,0 +1,215 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// BoxSummary(s) follow the last BundleControl of a CashLetter and precede the RoutingNumberSummary and
+// CashLetterControl. Each BoxSummary totals the Bundles shipped in one box.
+//
+// FileHeader
+// CashLetterHeader Record
+// BundleHeader Record
+// ...
+// BundleControl
+// BoxSummary
+// RoutingNumberSummary
+// CashLetterControl
+// FileControl
+
+// BoxSummary Record
+type BoxSummary struct {
+	// ID is a client defined string used as a reference to this record.
+	ID string `json:"id"`
+	// RecordType defines the type of record.
+	recordType string
+	// DestinationRoutingNumber identifies the institution that receives the box.
+	// Format: TTTTAAAAC, where:
+	// TTTT: Federal Reserve Prefix
+	// AAAA: ABA Institution Identifier
+	// C: Payor Bank Routing Number Check Digit
+	DestinationRoutingNumber string `json:"destinationRoutingNumber"`
+	// BoxSequenceNumber is a number assigned by the institution that creates the box. BoxSequenceNumber
+	// is set by CashLetter.Create.
+	BoxSequenceNumber string `json:"boxSequenceNumber"`
+	// BoxBundleCount identifies the total number of bundles within the box.
+	BoxBundleCount int `json:"boxBundleCount"`
+	// BoxNumberID is a number that identifies the box.
+	BoxNumberID string `json:"boxNumberID"`
+	// BoxTotalAmount identifies the total amount of all items within the box.
+	// All amounts fields have two implied decimal points. e.g., 100000 is $1,000.00
+	BoxTotalAmount int `json:"boxTotalAmount"`
+	// reserved is a field reserved for future use.  Reserved should be blank.
+	reserved string
+	// validator is composed for imagecashletter data validation
+	validator
+	// converters is composed for imagecashletter to golang Converters
+	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
+}
+
+// NewBoxSummary returns a new BoxSummary with default values for non exported fields
+func NewBoxSummary() *BoxSummary {
+	bs := &BoxSummary{}
+	bs.setRecordType()
+	return bs
+}
+
+func (bs *BoxSummary) setRecordType() {
+	if bs == nil {
+		return
+	}
+	bs.recordType = "75"
+	bs.reserved = strings.Repeat(" ", 40)
+}
+
+// Parse takes the input record string and parses the BoxSummary values
+func (bs *BoxSummary) Parse(record string) {
+	if utf8.RuneCountInString(record) < 40 {
+		return // line too short
+	}
+	// Character position 1-2, Always "75"
+	bs.setRecordType()
+	// 03-11
+	bs.DestinationRoutingNumber = bs.parseStringField(record[2:11])
+	// 12-14
+	bs.BoxSequenceNumber = bs.parseStringField(record[11:14])
+	// 15-18
+	bs.BoxBundleCount = bs.parseNumField(record[14:18])
+	// 19-26
+	bs.BoxNumberID = bs.parseStringField(record[18:26])
+	// 27-40
+	bs.BoxTotalAmount = bs.parseNumField(record[26:40])
+	// 41-80
+	bs.reserved = strings.Repeat(" ", 40)
+}
+
+func (bs *BoxSummary) UnmarshalJSON(data []byte) error {
+	type Alias BoxSummary
+	aux := struct {
+		*Alias
+	}{
+		(*Alias)(bs),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	bs.setRecordType()
+	return nil
+}
+
+// String writes the BoxSummary struct to a string.
+func (bs *BoxSummary) String() string {
+	var buf strings.Builder
+	buf.Grow(80)
+	buf.WriteString(bs.recordType)
+	buf.WriteString(bs.DestinationRoutingNumberField())
+	buf.WriteString(bs.BoxSequenceNumberField())
+	buf.WriteString(bs.BoxBundleCountField())
+	buf.WriteString(bs.BoxNumberIDField())
+	buf.WriteString(bs.BoxTotalAmountField())
+	buf.WriteString(bs.reservedField())
+	return buf.String()
+}
+
+// Validate performs imagecashletter format rule checks on the record and returns an error if not Validated
+// The first error encountered is returned and stops the parsing.
+func (bs *BoxSummary) Validate() error {
+	if err := bs.fieldInclusion(); err != nil {
+		return err
+	}
+	if bs.recordType != "75" {
+		msg := fmt.Sprintf(msgRecordType, 75)
+		return &FieldError{FieldName: "recordType", Value: bs.recordType, Msg: msg}
+	}
+	if err := bs.isNumeric(bs.DestinationRoutingNumber); err != nil {
+		return &FieldError{FieldName: "DestinationRoutingNumber", Value: bs.DestinationRoutingNumber, Msg: err.Error()}
+	}
+	if err := bs.isNumeric(bs.BoxSequenceNumber); err != nil {
+		return &FieldError{FieldName: "BoxSequenceNumber", Value: bs.BoxSequenceNumber, Msg: err.Error()}
+	}
+	if err := bs.isNumeric(bs.BoxNumberID); err != nil {
+		return &FieldError{FieldName: "BoxNumberID", Value: bs.BoxNumberID, Msg: err.Error()}
+	}
+	return nil
+}
+
+// fieldInclusion validate mandatory fields are not default values. If fields are
+// invalid the Electronic Exchange will be returned.
+func (bs *BoxSummary) fieldInclusion() error {
+	if bs.recordType == "" {
+		return &FieldError{FieldName: "recordType",
+			Value: bs.recordType,
+			Msg:   msgFieldInclusion + ", did you use BoxSummary()?"}
+	}
+	if bs.DestinationRoutingNumber == "" {
+		return &FieldError{FieldName: "DestinationRoutingNumber",
+			Value: bs.DestinationRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use BoxSummary()?"}
+	}
+	if bs.DestinationRoutingNumberField() == "000000000" {
+		return &FieldError{FieldName: "DestinationRoutingNumber",
+			Value: bs.DestinationRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use BoxSummary()?"}
+	}
+	if bs.BoxSequenceNumberField() == "000" {
+		return &FieldError{FieldName: "BoxSequenceNumber",
+			Value: bs.BoxSequenceNumber,
+			Msg:   msgFieldInclusion + ", did you use BoxSummary()?"}
+	}
+	if bs.BoxBundleCount == 0 {
+		return &FieldError{FieldName: "BoxBundleCount",
+			Value: bs.BoxBundleCountField(),
+			Msg:   msgFieldInclusion + ", did you use BoxSummary()?"}
+	}
+	if bs.BoxNumberID == "" {
+		return &FieldError{FieldName: "BoxNumberID",
+			Value: bs.BoxNumberID,
+			Msg:   msgFieldInclusion + ", did you use BoxSummary()?"}
+	}
+	return nil
+}
+
+// DestinationRoutingNumberField gets the DestinationRoutingNumber field
+func (bs *BoxSummary) DestinationRoutingNumberField() string {
+	return bs.stringField(bs.DestinationRoutingNumber, 9)
+}
+
+// BoxSequenceNumberField gets the BoxSequenceNumber field zero padded
+func (bs *BoxSummary) BoxSequenceNumberField() string {
+	return bs.stringField(bs.BoxSequenceNumber, 3)
+}
+
+// BoxBundleCountField gets a string of BoxBundleCount zero padded
+func (bs *BoxSummary) BoxBundleCountField() string {
+	return bs.numericField(bs.BoxBundleCount, 4)
+}
+
+// BoxNumberIDField gets the BoxNumberID field zero padded
+func (bs *BoxSummary) BoxNumberIDField() string {
+	return bs.stringField(bs.BoxNumberID, 8)
+}
+
+// BoxTotalAmountField gets a string of BoxTotalAmount zero padded
+func (bs *BoxSummary) BoxTotalAmountField() string {
+	return bs.numericField(bs.BoxTotalAmount, 14)
+}
+
+// reservedField gets reserved - blank space
+func (bs *BoxSummary) reservedField() string {
+	return bs.alphaField(bs.reserved, 40)
+}
+
+// SetBoxSequenceNumber sets BoxSequenceNumber
+func (bs *BoxSummary) SetBoxSequenceNumber(seq int) string {
+	bs.BoxSequenceNumber = bs.numericField(seq, 3)
+	return bs.BoxSequenceNumber
+}
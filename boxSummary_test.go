@@ -0,0 +1,189 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"strings"
+	"testing"
+)
+
+// mockBoxSummary creates a BoxSummary
+func mockBoxSummary() *BoxSummary {
+	bs := NewBoxSummary()
+	bs.DestinationRoutingNumber = "231380104"
+	bs.SetBoxSequenceNumber(1)
+	bs.BoxBundleCount = 2
+	bs.BoxNumberID = "1234"
+	bs.BoxTotalAmount = 200000
+	return bs
+}
+
+// TestMockBoxSummary creates a BoxSummary
+func TestMockBoxSummary(t *testing.T) {
+	v := mockBoxSummary()
+	if err := v.Validate(); err != nil {
+		t.Error("mockBoxSummary does not validate and will break other tests: ", err)
+	}
+}
+
+func TestBoxSummaryParseErr(t *testing.T) {
+	var v BoxSummary
+	v.Parse("asdlahsakjajf")
+	if v.DestinationRoutingNumber != "" {
+		t.Errorf("v.DestinationRoutingNumber=%s", v.DestinationRoutingNumber)
+	}
+}
+
+// TestParseBoxSummary validates parsing a BoxSummary
+func TestParseBoxSummary(t *testing.T) {
+	var line = "7523138010400100020000123400000000200000                                        "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	clh := mockCashLetterHeader()
+	r.addCurrentCashLetter(NewCashLetter(clh))
+	if err := r.parseBoxSummary(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	record := r.currentCashLetter.GetBoxSummary()[0]
+
+	if record.recordType != "75" {
+		t.Errorf("RecordType Expected '75' got: %v", record.recordType)
+	}
+	if record.DestinationRoutingNumber != "231380104" {
+		t.Errorf("DestinationRoutingNumber Expected '231380104' got: %v", record.DestinationRoutingNumber)
+	}
+	if record.BoxSequenceNumber != "001" {
+		t.Errorf("BoxSequenceNumber Expected '001' got: %v", record.BoxSequenceNumber)
+	}
+	if record.BoxBundleCount != 2 {
+		t.Errorf("BoxBundleCount Expected '2' got: %v", record.BoxBundleCount)
+	}
+	if record.BoxNumberID != "00001234" {
+		t.Errorf("BoxNumberID Expected '00001234' got: %v", record.BoxNumberID)
+	}
+	if record.BoxTotalAmount != 200000 {
+		t.Errorf("BoxTotalAmount Expected '200000' got: %v", record.BoxTotalAmount)
+	}
+
+	if record.String() != line {
+		t.Errorf("Strings do not match")
+	}
+}
+
+// TestBoxSummaryParseOutsideCashLetter validation
+func TestBoxSummaryParseOutsideCashLetter(t *testing.T) {
+	var line = "7523138010400100020000123400000000200000                                        "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	if err := r.parseBoxSummary(); err != nil {
+		if !strings.Contains(err.Error(), msgFileBoxSummary) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestBSRecordType validation
+func TestBSRecordType(t *testing.T) {
+	v := mockBoxSummary()
+	v.recordType = "00"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSDestinationRoutingNumber validation
+func TestBSDestinationRoutingNumber(t *testing.T) {
+	v := mockBoxSummary()
+	v.DestinationRoutingNumber = "23138010A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSBoxSequenceNumber validation
+func TestBSBoxSequenceNumber(t *testing.T) {
+	v := mockBoxSummary()
+	v.BoxSequenceNumber = "00A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "BoxSequenceNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSBoxNumberID validation
+func TestBSBoxNumberID(t *testing.T) {
+	v := mockBoxSummary()
+	v.BoxNumberID = "1234A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "BoxNumberID" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// Field Inclusion
+
+// TestBSFIRecordType validation
+func TestBSFIRecordType(t *testing.T) {
+	v := mockBoxSummary()
+	v.recordType = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSFIDestinationRoutingNumber validation
+func TestBSFIDestinationRoutingNumber(t *testing.T) {
+	v := mockBoxSummary()
+	v.DestinationRoutingNumber = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSFIDestinationRoutingNumberZero validation
+func TestBSFIDestinationRoutingNumberZero(t *testing.T) {
+	v := mockBoxSummary()
+	v.DestinationRoutingNumber = "000000000"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSFIBoxSequenceNumber validation
+func TestBSFIBoxSequenceNumber(t *testing.T) {
+	v := mockBoxSummary()
+	v.BoxSequenceNumber = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "BoxSequenceNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSFIBoxBundleCount validation
+func TestBSFIBoxBundleCount(t *testing.T) {
+	v := mockBoxSummary()
+	v.BoxBundleCount = 0
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "BoxBundleCount" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestBSFIBoxNumberID validation
+func TestBSFIBoxNumberID(t *testing.T) {
+	v := mockBoxSummary()
+	v.BoxNumberID = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "BoxNumberID" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
@@ -44,6 +44,10 @@ type Bundle struct {
 	ID string `json:"id"`
 	// BundleHeader is a Bundle Header Record
 	BundleHeader *BundleHeader `json:"bundleHeader,omitempty"`
+	// Credits are Credit Records which precede the Check Items and Return Items
+	Credits []*Credit `json:"credits,omitempty"`
+	// UserRecords are User Records which follow the BundleHeader and Credits and precede the first item
+	UserRecords UserRecords `json:"userRecords,omitempty"`
 	// Checks are Check Items: Check Detail Records, Check Detail Addendum Records, and Image Views
 	Checks []*CheckDetail `json:"checks,omitempty"`
 	// Returns are Return Items: Return Detail Records, Return Detail Addendum Records, and Image Views
@@ -65,6 +69,9 @@ func (b *Bundle) setRecordType() {
 		return
 	}
 	b.BundleHeader.setRecordType()
+	for i := range b.Credits {
+		b.Credits[i].setRecordType()
+	}
 	for i := range b.Checks {
 		b.Checks[i].setRecordType()
 	}
@@ -107,9 +114,24 @@ func (b *Bundle) build() error {
 	bundleTotalAmount := 0
 	micrValidTotalAmount := 0
 	bundleImagesCount := 0
-	// The current Implementation doe snot support CreditItems as part of a bundle so BundleControl.CreditIndicator = 0
+	// CreditItems are not part of a bundle, Credits are included in the totals with BundleControl.CreditIndicator = 1
 	creditIndicator := 0
 
+	// Credits
+	for _, cr := range b.Credits {
+		if err := cr.Validate(); err != nil {
+			return err
+		}
+		itemCount = itemCount + 1
+		bundleTotalAmount = bundleTotalAmount + cr.ItemAmount
+		creditIndicator = 1
+	}
+	for _, ur := range b.UserRecords {
+		if err := ur.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Forward Items
 	for _, cd := range b.Checks {
 
@@ -170,6 +192,32 @@ func (b *Bundle) GetControl() *BundleControl {
 	return b.BundleControl
 }
 
+// AddCredit appends a Credit to the Bundle
+func (b *Bundle) AddCredit(cr *Credit) {
+	b.Credits = append(b.Credits, cr)
+}
+
+// GetCredits returns a slice of credits for the Bundle
+func (b *Bundle) GetCredits() []*Credit {
+	if b == nil {
+		return nil
+	}
+	return b.Credits
+}
+
+// AddUserRecord appends a UserRecord to the Bundle
+func (b *Bundle) AddUserRecord(ur UserRecord) {
+	b.UserRecords = append(b.UserRecords, ur)
+}
+
+// GetUserRecords returns a slice of UserRecord for the Bundle
+func (b *Bundle) GetUserRecords() UserRecords {
+	if b == nil {
+		return nil
+	}
+	return b.UserRecords
+}
+
 // AddCheckDetail appends a CheckDetail to the Bundle
 func (b *Bundle) AddCheckDetail(cd *CheckDetail) {
 	b.Checks = append(b.Checks, cd)
@@ -229,6 +277,11 @@ func (b *Bundle) ValidateForwardItems(cd *CheckDetail) error {
 			return err
 		}
 	}
+	for _, ur := range cd.UserRecords {
+		if err := ur.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -270,6 +323,11 @@ func (b *Bundle) ValidateReturnItems(rd *ReturnDetail) error {
 			return err
 		}
 	}
+	for _, ur := range rd.UserRecords {
+		if err := ur.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
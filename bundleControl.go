@@ -43,6 +43,8 @@ type BundleControl struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewBundleControl returns a new BundleControl with default values for non exported fields
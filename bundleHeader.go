@@ -89,6 +89,8 @@ type BundleHeader struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewBundleHeader returns a new BundleHeader with default values for non exported fields
@@ -288,3 +288,25 @@ func TestReturnDetailAddendumDCount(t *testing.T) {
 		}
 	}
 }
+
+// TestBundleCredit validates Credits are included in the BundleControl totals
+func TestBundleCredit(t *testing.T) {
+	b := mockBundleChecks()
+	b.AddCredit(mockCredit())
+	if err := b.build(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	bc := b.GetControl()
+	if bc.BundleItemsCount != 2 {
+		t.Errorf("BundleItemsCount: %d", bc.BundleItemsCount)
+	}
+	if bc.BundleTotalAmount != 200000 {
+		t.Errorf("BundleTotalAmount: %d", bc.BundleTotalAmount)
+	}
+	if bc.MICRValidTotalAmount != 100000 {
+		t.Errorf("MICRValidTotalAmount: %d", bc.MICRValidTotalAmount)
+	}
+	if bc.CreditTotalIndicator != 1 {
+		t.Errorf("CreditTotalIndicator: %d", bc.CreditTotalIndicator)
+	}
+}
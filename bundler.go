@@ -0,0 +1,231 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Errors specific to bundling items
+var (
+	msgBundlerItem       = "must be a CheckDetail or ReturnDetail"
+	msgBundlerMaxItems   = "MaxItems %v must not be negative"
+	msgBundlerMaxAmount  = "MaxAmount %v must not be negative"
+	msgBundlerCashLetter = "must have a CashLetterHeader to bundle items"
+	msgBundlerNoItems    = "must have Check Detail or Return Detail to bundle"
+)
+
+// BundleItem is a forward (CheckDetail) or return (ReturnDetail) item which can be bundled by a Bundler.
+type BundleItem interface {
+	PayorBankRoutingNumberField() string
+	PayorBankCheckDigitField() string
+	ItemAmountField() string
+}
+
+// BundleGroupFunc returns the grouping key of an item. Only items with the same key are placed in a Bundle.
+type BundleGroupFunc func(item BundleItem) string
+
+// PayorRoutingPrefixGroup returns a BundleGroupFunc which groups items by the first length digits of the
+// payor bank routing number, e.g. 4 for the Federal Reserve prefix.
+func PayorRoutingPrefixGroup(length int) BundleGroupFunc {
+	return func(item BundleItem) string {
+		routingNumber := item.PayorBankRoutingNumberField() + item.PayorBankCheckDigitField()
+		if length > 0 && length < len(routingNumber) {
+			return routingNumber[:length]
+		}
+		return routingNumber
+	}
+}
+
+// BundlerOption is an option for a Bundler
+type BundlerOption func(*Bundler)
+
+// BundleMaxItemsOption limits the number of items in each Bundle. Zero allows any number of items.
+func BundleMaxItemsOption(n int) BundlerOption {
+	return func(b *Bundler) {
+		b.MaxItems = n
+	}
+}
+
+// BundleMaxAmountOption limits the total amount, in cents, of the items in each Bundle. Zero allows any total
+// amount. An item with an amount over the maximum is placed in a Bundle of its own.
+func BundleMaxAmountOption(amount int) BundlerOption {
+	return func(b *Bundler) {
+		b.MaxAmount = amount
+	}
+}
+
+// BundleGroupOption places only items with the same grouping key in a Bundle.
+func BundleGroupOption(fn BundleGroupFunc) BundlerOption {
+	return func(b *Bundler) {
+		b.Group = fn
+	}
+}
+
+// BundleCycleNumberOption sets the CycleNumber of each BundleHeader.
+func BundleCycleNumberOption(cycleNumber string) BundlerOption {
+	return func(b *Bundler) {
+		b.CycleNumber = cycleNumber
+	}
+}
+
+// Bundler partitions a flat list of CheckDetail and ReturnDetail items into the Bundles of a CashLetter.
+//
+// Items are grouped by their grouping key, in the order each key is first added, and keep the order they were
+// added within a group. A new Bundle is started when adding an item would exceed MaxItems or MaxAmount.
+// CheckDetail and ReturnDetail items are never placed in the same Bundle.
+type Bundler struct {
+	// CashLetterHeader is the header of the CashLetter which is built, BundleHeader fields are generated from it.
+	CashLetterHeader *CashLetterHeader
+	// MaxItems is the maximum number of items in a Bundle, zero for no maximum.
+	MaxItems int
+	// MaxAmount is the maximum total amount of the items in a Bundle, zero for no maximum.
+	MaxAmount int
+	// Group returns the grouping key of an item, nil places all items in the same group.
+	Group BundleGroupFunc
+	// CycleNumber is the CycleNumber of each BundleHeader.
+	CycleNumber string
+
+	items []BundleItem
+}
+
+// NewBundler takes a CashLetterHeader and returns a Bundler
+func NewBundler(clh *CashLetterHeader, opts ...BundlerOption) *Bundler {
+	b := &Bundler{CashLetterHeader: clh}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// AddCheckDetail appends a CheckDetail to the items to bundle
+func (b *Bundler) AddCheckDetail(cd *CheckDetail) {
+	b.items = append(b.items, cd)
+}
+
+// AddReturnDetail appends a ReturnDetail to the items to bundle
+func (b *Bundler) AddReturnDetail(rd *ReturnDetail) {
+	b.items = append(b.items, rd)
+}
+
+// AddItem appends a CheckDetail or ReturnDetail to the items to bundle
+func (b *Bundler) AddItem(item BundleItem) error {
+	switch item.(type) {
+	case *CheckDetail, *ReturnDetail:
+		b.items = append(b.items, item)
+		return nil
+	}
+	return &FieldError{FieldName: "item", Value: fmt.Sprintf("%T", item), Msg: msgBundlerItem}
+}
+
+// Bundles partitions the items into Bundles with a BundleHeader generated from the CashLetterHeader.
+func (b *Bundler) Bundles() ([]*Bundle, error) {
+	if b.CashLetterHeader == nil {
+		return nil, &CashLetterError{FieldName: "CashLetterHeader", Msg: msgBundlerCashLetter}
+	}
+	if b.MaxItems < 0 {
+		return nil, &CashLetterError{CashLetterID: b.CashLetterHeader.CashLetterID, FieldName: "MaxItems",
+			Msg: fmt.Sprintf(msgBundlerMaxItems, b.MaxItems)}
+	}
+	if b.MaxAmount < 0 {
+		return nil, &CashLetterError{CashLetterID: b.CashLetterHeader.CashLetterID, FieldName: "MaxAmount",
+			Msg: fmt.Sprintf(msgBundlerMaxAmount, b.MaxAmount)}
+	}
+	if len(b.items) == 0 {
+		return nil, &CashLetterError{CashLetterID: b.CashLetterHeader.CashLetterID, FieldName: "entries",
+			Msg: msgBundlerNoItems}
+	}
+
+	// group the items, CheckDetail and ReturnDetail are always in different groups
+	var keys []string
+	groups := make(map[string][]BundleItem)
+	for _, item := range b.items {
+		key := "checks"
+		if _, ok := item.(*ReturnDetail); ok {
+			key = "returns"
+		}
+		if b.Group != nil {
+			key = key + "\x00" + b.Group(item)
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	var bundles []*Bundle
+	for _, key := range keys {
+		var current *Bundle
+		itemCount, totalAmount := 0, 0
+		for _, item := range groups[key] {
+			amount := bundleItemAmount(item)
+			if current == nil || (b.MaxItems > 0 && itemCount+1 > b.MaxItems) ||
+				(b.MaxAmount > 0 && itemCount > 0 && totalAmount+amount > b.MaxAmount) {
+				current = NewBundle(b.bundleHeader(item, len(bundles)+1))
+				bundles = append(bundles, current)
+				itemCount, totalAmount = 0, 0
+			}
+			switch item := item.(type) {
+			case *CheckDetail:
+				current.AddCheckDetail(item)
+			case *ReturnDetail:
+				current.AddReturnDetail(item)
+			}
+			itemCount++
+			totalAmount = totalAmount + amount
+		}
+	}
+	return bundles, nil
+}
+
+// CashLetter returns a CashLetter of the Bundles of the items, created with the CreateOptions.
+func (b *Bundler) CashLetter(opts ...CreateOption) (CashLetter, error) {
+	bundles, err := b.Bundles()
+	if err != nil {
+		return CashLetter{}, err
+	}
+	cl := NewCashLetter(b.CashLetterHeader)
+	for _, bundle := range bundles {
+		cl.AddBundle(bundle)
+	}
+	if err := cl.Create(opts...); err != nil {
+		return CashLetter{}, err
+	}
+	return cl, nil
+}
+
+// bundleHeader generates the BundleHeader of the Bundle with sequence number seq starting with item.
+func (b *Bundler) bundleHeader(item BundleItem, seq int) *BundleHeader {
+	clh := b.CashLetterHeader
+	bh := NewBundleHeader()
+	bh.CollectionTypeIndicator = clh.CollectionTypeIndicator
+	if clh.CollectionTypeIndicator == "99" {
+		// a Bundle within a mixed CashLetter has the CollectionTypeIndicator of its items
+		bh.CollectionTypeIndicator = "01"
+		if _, ok := item.(*ReturnDetail); ok {
+			bh.CollectionTypeIndicator = "03"
+		}
+	}
+	bh.DestinationRoutingNumber = clh.DestinationRoutingNumber
+	bh.ECEInstitutionRoutingNumber = clh.ECEInstitutionRoutingNumber
+	bh.BundleBusinessDate = clh.CashLetterBusinessDate
+	bh.BundleCreationDate = clh.CashLetterCreationDate
+	bh.BundleID = strconv.Itoa(seq)
+	bh.SetBundleSequenceNumber(seq)
+	bh.CycleNumber = b.CycleNumber
+	return bh
+}
+
+// bundleItemAmount returns the ItemAmount of a CheckDetail or ReturnDetail
+func bundleItemAmount(item BundleItem) int {
+	switch item := item.(type) {
+	case *CheckDetail:
+		return item.ItemAmount
+	case *ReturnDetail:
+		return item.ItemAmount
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"testing"
+)
+
+// mockBundlerCheckDetail creates a CheckDetail without addenda for a payor routing number and amount
+func mockBundlerCheckDetail(routingNumber string, amount int) *CheckDetail {
+	cd := mockCheckDetail()
+	cd.PayorBankRoutingNumber = routingNumber[:8]
+	cd.PayorBankCheckDigit = routingNumber[8:]
+	cd.ItemAmount = amount
+	cd.AddendumCount = 0
+	return cd
+}
+
+// bundlerItem is a BundleItem which isn't a CheckDetail or ReturnDetail
+type bundlerItem struct {
+	CheckDetail
+}
+
+// bundleAmounts returns the ItemAmount of the items of each Bundle
+func bundleAmounts(bundles []*Bundle) [][]int {
+	var amounts [][]int
+	for _, b := range bundles {
+		var a []int
+		for _, cd := range b.Checks {
+			a = append(a, cd.ItemAmount)
+		}
+		for _, rd := range b.Returns {
+			a = append(a, rd.ItemAmount)
+		}
+		amounts = append(amounts, a)
+	}
+	return amounts
+}
+
+func equalBundleAmounts(got, expected [][]int) bool {
+	if len(got) != len(expected) {
+		return false
+	}
+	for i := range got {
+		if len(got[i]) != len(expected[i]) {
+			return false
+		}
+		for j := range got[i] {
+			if got[i][j] != expected[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// TestBundler validates items are partitioned by MaxItems and MaxAmount
+func TestBundler(t *testing.T) {
+	tests := []struct {
+		opts     []BundlerOption
+		expected [][]int
+	}{
+		{nil, [][]int{{100, 200, 300, 400, 600}}},
+		{[]BundlerOption{BundleMaxItemsOption(2)}, [][]int{{100, 200}, {300, 400}, {600}}},
+		{[]BundlerOption{BundleMaxAmountOption(500)}, [][]int{{100, 200}, {300}, {400}, {600}}},
+		{[]BundlerOption{BundleMaxItemsOption(1), BundleMaxAmountOption(1000)}, [][]int{{100}, {200}, {300}, {400}, {600}}},
+	}
+	for i, test := range tests {
+		b := NewBundler(mockCashLetterHeader(), test.opts...)
+		for _, amount := range []int{100, 200, 300, 400, 600} {
+			b.AddCheckDetail(mockBundlerCheckDetail("031300012", amount))
+		}
+		bundles, err := b.Bundles()
+		if err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		if amounts := bundleAmounts(bundles); !equalBundleAmounts(amounts, test.expected) {
+			t.Errorf("test %d: bundled %v, expected %v", i, amounts, test.expected)
+		}
+	}
+}
+
+// TestBundler__CashLetter validates a CashLetter is created from the Bundles of the items
+func TestBundler__CashLetter(t *testing.T) {
+	clh := mockCashLetterHeader()
+	b := NewBundler(clh, BundleMaxItemsOption(2), BundleCycleNumberOption("01"))
+	for _, amount := range []int{100, 200, 300} {
+		b.AddCheckDetail(mockBundlerCheckDetail("031300012", amount))
+	}
+	cl, err := b.CashLetter()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(cl.Bundles) != 2 {
+		t.Fatalf("created %d Bundles", len(cl.Bundles))
+	}
+	for i, bundle := range cl.Bundles {
+		bh := bundle.BundleHeader
+		if bh.CollectionTypeIndicator != clh.CollectionTypeIndicator || bh.DestinationRoutingNumber != clh.DestinationRoutingNumber ||
+			bh.ECEInstitutionRoutingNumber != clh.ECEInstitutionRoutingNumber || !bh.BundleBusinessDate.Equal(clh.CashLetterBusinessDate) ||
+			!bh.BundleCreationDate.Equal(clh.CashLetterCreationDate) || bh.CycleNumber != "01" {
+			t.Errorf("BundleHeader: %s", bh.String())
+		}
+		if bh.BundleSequenceNumberField() != []string{"0001", "0002"}[i] {
+			t.Errorf("BundleSequenceNumber: %s", bh.BundleSequenceNumberField())
+		}
+	}
+	if bc := cl.Bundles[0].BundleControl; bc.BundleItemsCount != 2 || bc.BundleTotalAmount != 300 {
+		t.Errorf("BundleControl: %s", bc.String())
+	}
+	if clc := cl.CashLetterControl; clc.CashLetterBundleCount != 2 || clc.CashLetterItemsCount != 3 || clc.CashLetterTotalAmount != 600 {
+		t.Errorf("CashLetterControl: %s", clc.String())
+	}
+
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := file.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	_, err = NewBundler(clh).CashLetter(CreateRoutingNumberSummaryOption())
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestBundler__Group validates only items with the same grouping key are bundled together
+func TestBundler__Group(t *testing.T) {
+	b := NewBundler(mockCashLetterHeader(), BundleGroupOption(PayorRoutingPrefixGroup(4)), BundleMaxItemsOption(2))
+	b.AddCheckDetail(mockBundlerCheckDetail("031300012", 100))
+	b.AddCheckDetail(mockBundlerCheckDetail("231380104", 200))
+	b.AddCheckDetail(mockBundlerCheckDetail("031300999", 300))
+	b.AddCheckDetail(mockBundlerCheckDetail("031301234", 400))
+	b.AddCheckDetail(mockBundlerCheckDetail("231380104", 500))
+
+	bundles, err := b.Bundles()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	expected := [][]int{{100, 300}, {400}, {200, 500}}
+	if amounts := bundleAmounts(bundles); !equalBundleAmounts(amounts, expected) {
+		t.Errorf("bundled %v, expected %v", amounts, expected)
+	}
+}
+
+// TestBundler__Mixed validates CheckDetail and ReturnDetail are bundled separately in a mixed CashLetter
+func TestBundler__Mixed(t *testing.T) {
+	clh := mockCashLetterHeader()
+	clh.CollectionTypeIndicator = "99"
+	b := NewBundler(clh)
+	rd := mockReturnDetail()
+	rd.AddendumCount = 0
+	if err := b.AddItem(rd); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := b.AddItem(mockBundlerCheckDetail("031300012", 100)); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := b.AddItem(&bundlerItem{}); err == nil {
+		t.Error("expected error")
+	}
+
+	cl, err := b.CashLetter()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(cl.Bundles) != 2 || len(cl.Bundles[0].Returns) != 1 || len(cl.Bundles[1].Checks) != 1 {
+		t.Fatalf("unexpected Bundles: %v", bundleAmounts(cl.Bundles))
+	}
+	if v := cl.Bundles[0].BundleHeader.CollectionTypeIndicator; v != "03" {
+		t.Errorf("CollectionTypeIndicator: %s", v)
+	}
+	if v := cl.Bundles[1].BundleHeader.CollectionTypeIndicator; v != "01" {
+		t.Errorf("CollectionTypeIndicator: %s", v)
+	}
+}
+
+// TestBundler__Errors validates the options of a Bundler
+func TestBundler__Errors(t *testing.T) {
+	tests := map[string]*Bundler{
+		"CashLetterHeader": NewBundler(nil),
+		"MaxItems":         NewBundler(mockCashLetterHeader(), BundleMaxItemsOption(-1)),
+		"MaxAmount":        NewBundler(mockCashLetterHeader(), BundleMaxAmountOption(-1)),
+		"entries":          NewBundler(mockCashLetterHeader()),
+	}
+	for fieldName, b := range tests {
+		if fieldName != "entries" {
+			b.AddCheckDetail(mockBundlerCheckDetail("031300012", 100))
+		}
+		_, err := b.Bundles()
+		if e, ok := err.(*CashLetterError); !ok || e.FieldName != fieldName {
+			t.Errorf("%s: %T: %s", fieldName, err, err)
+		}
+	}
+}
@@ -21,8 +21,11 @@ func (e *CashLetterError) Error() string {
 
 // Errors specific to parsing a CashLetter
 var (
-	msgCashLetterBundleEntries = "%v cannot have bundle entries"
-	msgCashLetterRoutingNumber = "%v cannot have a Routing Number Summary"
+	msgCashLetterBundleEntries        = "%v cannot have bundle entries"
+	msgCashLetterRoutingNumber        = "%v cannot have a Routing Number Summary"
+	msgCashLetterBoxSummary           = "BoxSummary %v %v does not match CashLetter %v %v"
+	msgCashLetterRoutingNumberSummary = "RoutingNumberSummary %v %v does not match CheckDetail %v %v"
+	msgCashLetterRoutingNumberUnique  = "RoutingNumberSummary %v is not unique"
 )
 
 // CashLetter contains CashLetterHeader, CashLetterControl and Bundle records.
@@ -35,6 +38,17 @@ type CashLetter struct {
 	Bundles []*Bundle `json:"bundles,omitempty"`
 	// CreditItems is an array of CreditItem
 	CreditItems []*CreditItem `json:"creditItem,omitempty"`
+	// AccountTotalsDetail is an array of AccountTotalsDetail
+	AccountTotalsDetail []*AccountTotalsDetail `json:"accountTotalsDetail,omitempty"`
+	// NonHitTotalsDetail is an array of NonHitTotalsDetail
+	NonHitTotalsDetail []*NonHitTotalsDetail `json:"nonHitTotalsDetail,omitempty"`
+	// UserRecords is an array of the User Records which are not within a Bundle
+	UserRecords UserRecords `json:"userRecords,omitempty"`
+	// UserRecordPositions are the number of Bundles each of the UserRecords follows. UserRecords without a
+	// position precede the first Bundle.
+	UserRecordPositions []int `json:"userRecordPositions,omitempty"`
+	// BoxSummary is an array of BoxSummary
+	BoxSummary []*BoxSummary `json:"boxSummary,omitempty"`
 	// RoutingNumberSummary is an array of RoutingNumberSummary
 	RoutingNumberSummary []*RoutingNumberSummary `json:"routingNumberSummary,omitempty"`
 	// currentBundle is the currentBundle being parsed
@@ -65,6 +79,15 @@ func (cl *CashLetter) setRecordType() {
 	for i := range cl.CreditItems {
 		cl.CreditItems[i].setRecordType()
 	}
+	for i := range cl.AccountTotalsDetail {
+		cl.AccountTotalsDetail[i].setRecordType()
+	}
+	for i := range cl.NonHitTotalsDetail {
+		cl.NonHitTotalsDetail[i].setRecordType()
+	}
+	for i := range cl.BoxSummary {
+		cl.BoxSummary[i].setRecordType()
+	}
 	for i := range cl.RoutingNumberSummary {
 		cl.RoutingNumberSummary[i].setRecordType()
 	}
@@ -94,9 +117,84 @@ func (cl *CashLetter) Validate() error {
 	return nil
 }
 
+// validateRoutingNumberSummary ensures each RoutingNumberSummary is for a unique routing number and agrees with
+// the total amount and item count of the CheckDetail with its routing number, see
+// ValidateRoutingNumberSummaryOption.
+func (cl *CashLetter) validateRoutingNumberSummary() error {
+	if len(cl.RoutingNumberSummary) == 0 {
+		return nil
+	}
+	totals := make(map[string]*RoutingNumberSummary)
+	for _, rns := range cl.routingNumberTotals() {
+		totals[rns.CashLetterRoutingNumber] = rns
+	}
+	seen := make(map[string]bool)
+	for _, rns := range cl.RoutingNumberSummary {
+		routingNumber := rns.CashLetterRoutingNumberField()
+		if seen[routingNumber] {
+			msg := fmt.Sprintf(msgCashLetterRoutingNumberUnique, routingNumber)
+			return &CashLetterError{CashLetterID: cl.CashLetterHeader.CashLetterID, FieldName: "CashLetterRoutingNumber", Msg: msg}
+		}
+		seen[routingNumber] = true
+
+		total := totals[routingNumber]
+		if total == nil {
+			total = &RoutingNumberSummary{}
+		}
+		if rns.RoutingNumberTotalAmount != total.RoutingNumberTotalAmount {
+			msg := fmt.Sprintf(msgCashLetterRoutingNumberSummary, routingNumber, rns.RoutingNumberTotalAmount, "ItemAmount", total.RoutingNumberTotalAmount)
+			return &CashLetterError{CashLetterID: cl.CashLetterHeader.CashLetterID, FieldName: "RoutingNumberTotalAmount", Msg: msg}
+		}
+		if rns.RoutingNumberItemCount != total.RoutingNumberItemCount {
+			msg := fmt.Sprintf(msgCashLetterRoutingNumberSummary, routingNumber, rns.RoutingNumberItemCount, "count", total.RoutingNumberItemCount)
+			return &CashLetterError{CashLetterID: cl.CashLetterHeader.CashLetterID, FieldName: "RoutingNumberItemCount", Msg: msg}
+		}
+	}
+	return nil
+}
+
+// routingNumberTotals returns a RoutingNumberSummary with the total amount and item count of the CheckDetail for
+// each payor routing number of the CashLetter, in the order the routing numbers are first found.
+func (cl *CashLetter) routingNumberTotals() []*RoutingNumberSummary {
+	var summaries []*RoutingNumberSummary
+	byRoutingNumber := make(map[string]*RoutingNumberSummary)
+	for _, b := range cl.Bundles {
+		if b == nil {
+			continue
+		}
+		for _, cd := range b.Checks {
+			routingNumber := cd.PayorBankRoutingNumberField() + cd.PayorBankCheckDigitField()
+			rns, ok := byRoutingNumber[routingNumber]
+			if !ok {
+				rns = NewRoutingNumberSummary()
+				rns.CashLetterRoutingNumber = routingNumber
+				byRoutingNumber[routingNumber] = rns
+				summaries = append(summaries, rns)
+			}
+			rns.RoutingNumberTotalAmount = rns.RoutingNumberTotalAmount + cd.ItemAmount
+			rns.RoutingNumberItemCount = rns.RoutingNumberItemCount + 1
+		}
+	}
+	return summaries
+}
+
+// buildRoutingNumberSummary replaces the RoutingNumberSummary records of the CashLetter with one for each payor
+// routing number of its CheckDetail. The UserField of a replaced RoutingNumberSummary is kept.
+func (cl *CashLetter) buildRoutingNumberSummary() {
+	userFields := make(map[string]string)
+	for _, rns := range cl.RoutingNumberSummary {
+		userFields[rns.CashLetterRoutingNumberField()] = rns.UserField
+	}
+	summaries := cl.routingNumberTotals()
+	for _, rns := range summaries {
+		rns.UserField = userFields[rns.CashLetterRoutingNumber]
+	}
+	cl.RoutingNumberSummary = summaries
+}
+
 // build a valid CashLetter by building a CashLetterControl. An error is returned if
 // the CashLetter being built has invalid records.
-func (cl *CashLetter) build() error {
+func (cl *CashLetter) build(opts *createOptions) error {
 
 	// Requires a valid CashLetterHeader
 	if err := cl.CashLetterHeader.Validate(); err != nil {
@@ -118,12 +216,24 @@ func (cl *CashLetter) build() error {
 		cashLetterItemsCount = cashLetterItemsCount + len(cl.GetCreditItems())
 		creditIndicator = 1
 	}
+	for _, ur := range cl.UserRecords {
+		if err := ur.Validate(); err != nil {
+			return err
+		}
+	}
 	// Bundles
 	for _, b := range cl.Bundles {
 
 		// Set Bundle Sequence Numbers
 		b.BundleHeader.SetBundleSequenceNumber(bundleSequenceNumber)
 
+		// Credits
+		for _, cr := range b.Credits {
+			cashLetterItemsCount = cashLetterItemsCount + 1
+			cashLetterTotalAmount = cashLetterTotalAmount + cr.ItemAmount
+			creditIndicator = 1
+		}
+
 		// Sequence  Number
 		cdSequenceNumber := 1
 
@@ -153,7 +263,7 @@ func (cl *CashLetter) build() error {
 			}
 			for x := range cd.CheckDetailAddendumC {
 				cd.CheckDetailAddendumC[x].SetEndorsingBankItemSequenceNumber(cdSequenceNumber)
-				cd.CheckDetailAddendumC[x].RecordNumber = cdAddendumARecordNumber
+				cd.CheckDetailAddendumC[x].RecordNumber = cdAddendumCRecordNumber
 				cdAddendumCRecordNumber++
 				if cdAddendumCRecordNumber > 99 {
 					cdAddendumCRecordNumber = 1
@@ -190,7 +300,7 @@ func (cl *CashLetter) build() error {
 
 			for x := range rd.ReturnDetailAddendumD {
 				rd.ReturnDetailAddendumD[x].SetEndorsingBankItemSequenceNumber(rdSequenceNumber)
-				rd.ReturnDetailAddendumA[x].RecordNumber = rdAddendumDRecordNumber
+				rd.ReturnDetailAddendumD[x].RecordNumber = rdAddendumDRecordNumber
 				rdAddendumDRecordNumber++
 				if rdAddendumDRecordNumber > 99 {
 					rdAddendumDRecordNumber = 1
@@ -214,6 +324,14 @@ func (cl *CashLetter) build() error {
 		bundleSequenceNumber++
 	}
 
+	// BoxSummary totals must balance with the Bundles of the CashLetter
+	if err := cl.buildBoxSummary(cashLetterBundleCount, cashLetterTotalAmount); err != nil {
+		return err
+	}
+	if opts.routingNumberSummary {
+		cl.buildRoutingNumberSummary()
+	}
+
 	// build a CashLetterControl record
 	clc := NewCashLetterControl()
 	clc.CashLetterBundleCount = cashLetterBundleCount
@@ -230,9 +348,64 @@ func (cl *CashLetter) build() error {
 	return nil
 }
 
+// buildBoxSummary sets BoxSummary sequence numbers and ensures the BoxSummary totals match the
+// CashLetter bundle count and total amount.
+func (cl *CashLetter) buildBoxSummary(bundleCount, totalAmount int) error {
+	if len(cl.BoxSummary) == 0 {
+		return nil
+	}
+	boxBundleCount := 0
+	boxTotalAmount := 0
+	for i, bs := range cl.BoxSummary {
+		bs.SetBoxSequenceNumber(i + 1)
+		if err := bs.Validate(); err != nil {
+			return err
+		}
+		boxBundleCount = boxBundleCount + bs.BoxBundleCount
+		boxTotalAmount = boxTotalAmount + bs.BoxTotalAmount
+	}
+	if boxBundleCount != bundleCount {
+		msg := fmt.Sprintf(msgCashLetterBoxSummary, "BoxBundleCount", boxBundleCount, "CashLetterBundleCount", bundleCount)
+		return &CashLetterError{CashLetterID: cl.CashLetterHeader.CashLetterID, FieldName: "BoxBundleCount", Msg: msg}
+	}
+	if boxTotalAmount != totalAmount {
+		msg := fmt.Sprintf(msgCashLetterBoxSummary, "BoxTotalAmount", boxTotalAmount, "CashLetterTotalAmount", totalAmount)
+		return &CashLetterError{CashLetterID: cl.CashLetterHeader.CashLetterID, FieldName: "BoxTotalAmount", Msg: msg}
+	}
+	return nil
+}
+
+// CreateOption is an option for CashLetter.Create
+type CreateOption func(*createOptions)
+
+type createOptions struct {
+	routingNumberSummary bool
+}
+
+// CreateRoutingNumberSummaryOption builds a RoutingNumberSummary for each payor routing number of the CheckDetail
+// in the CashLetter with their total amount and item count. Any RoutingNumberSummary records are replaced, keeping
+// the UserField of a record for the same routing number.
+func CreateRoutingNumberSummaryOption() CreateOption {
+	return func(o *createOptions) {
+		o.routingNumberSummary = true
+	}
+}
+
+// ValidateRoutingNumberSummaryOption validates the RoutingNumberSummary records of each CashLetter are for unique
+// routing numbers and agree with the total amount and item count of the CheckDetail drawn on their routing number.
+func ValidateRoutingNumberSummaryOption() ValidateOption {
+	return func(o *validateOptions) {
+		o.routingNumberSummary = true
+	}
+}
+
 // Create creates a CashLetter of Bundles containing CheckDetail or ReturnDetail
-func (cl *CashLetter) Create() error {
-	if err := cl.build(); err != nil {
+func (cl *CashLetter) Create(opts ...CreateOption) error {
+	o := &createOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := cl.build(o); err != nil {
 		return err
 	}
 	return cl.Validate()
@@ -299,3 +472,59 @@ func (cl *CashLetter) GetCreditItems() []*CreditItem {
 	}
 	return cl.CreditItems
 }
+
+// AddAccountTotalsDetail appends an AccountTotalsDetail to the CashLetter
+func (cl *CashLetter) AddAccountTotalsDetail(atd *AccountTotalsDetail) []*AccountTotalsDetail {
+	cl.AccountTotalsDetail = append(cl.AccountTotalsDetail, atd)
+	return cl.AccountTotalsDetail
+}
+
+// GetAccountTotalsDetail returns a slice of AccountTotalsDetail for the CashLetter
+func (cl *CashLetter) GetAccountTotalsDetail() []*AccountTotalsDetail {
+	if cl == nil {
+		return nil
+	}
+	return cl.AccountTotalsDetail
+}
+
+// AddNonHitTotalsDetail appends a NonHitTotalsDetail to the CashLetter
+func (cl *CashLetter) AddNonHitTotalsDetail(nhtd *NonHitTotalsDetail) []*NonHitTotalsDetail {
+	cl.NonHitTotalsDetail = append(cl.NonHitTotalsDetail, nhtd)
+	return cl.NonHitTotalsDetail
+}
+
+// GetNonHitTotalsDetail returns a slice of NonHitTotalsDetail for the CashLetter
+func (cl *CashLetter) GetNonHitTotalsDetail() []*NonHitTotalsDetail {
+	if cl == nil {
+		return nil
+	}
+	return cl.NonHitTotalsDetail
+}
+
+// AddUserRecord appends a UserRecord to the CashLetter
+func (cl *CashLetter) AddUserRecord(ur UserRecord) UserRecords {
+	cl.UserRecords = append(cl.UserRecords, ur)
+	return cl.UserRecords
+}
+
+// GetUserRecords returns a slice of UserRecord for the CashLetter
+func (cl *CashLetter) GetUserRecords() UserRecords {
+	if cl == nil {
+		return nil
+	}
+	return cl.UserRecords
+}
+
+// AddBoxSummary appends a BoxSummary to the CashLetter
+func (cl *CashLetter) AddBoxSummary(bs *BoxSummary) []*BoxSummary {
+	cl.BoxSummary = append(cl.BoxSummary, bs)
+	return cl.BoxSummary
+}
+
+// GetBoxSummary returns a slice of BoxSummary for the CashLetter
+func (cl *CashLetter) GetBoxSummary() []*BoxSummary {
+	if cl == nil {
+		return nil
+	}
+	return cl.BoxSummary
+}
@@ -49,6 +49,8 @@ type CashLetterControl struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCashLetterControl returns a new CashLetterControl with default values for non exported fields
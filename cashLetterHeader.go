@@ -144,6 +144,8 @@ type CashLetterHeader struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCashLetterHeader returns a new CashLetterHeader with default values for non exported fields
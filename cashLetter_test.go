@@ -1,6 +1,8 @@
 package imagecashletter
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +18,15 @@ func TestCashLetterPanics(t *testing.T) {
 	if v := cl.GetCreditItems(); v != nil {
 		t.Errorf("unexpected GetCreditItems: %v", v)
 	}
+	if v := cl.GetAccountTotalsDetail(); v != nil {
+		t.Errorf("unexpected GetAccountTotalsDetail: %v", v)
+	}
+	if v := cl.GetNonHitTotalsDetail(); v != nil {
+		t.Errorf("unexpected GetNonHitTotalsDetail: %v", v)
+	}
+	if v := cl.GetBoxSummary(); v != nil {
+		t.Errorf("unexpected GetBoxSummary: %v", v)
+	}
 }
 
 // TestCashLetterNoBundle validates no Bundle when CashLetterHeader.RecordTypeIndicator = "N"
@@ -72,3 +83,194 @@ func TestCashLetterRoutingNumberSummary(t *testing.T) {
 		}
 	}
 }
+
+// TestCashLetterBoxSummary validates BoxSummary totals are balanced with the CashLetter Bundles
+func TestCashLetterBoxSummary(t *testing.T) {
+	cd := mockCheckDetail()
+	cd.AddCheckDetailAddendumA(mockCheckDetailAddendumA())
+	cd.AddCheckDetailAddendumB(mockCheckDetailAddendumB())
+	cd.AddCheckDetailAddendumC(mockCheckDetailAddendumC())
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCheckDetail(cd)
+	bundleTwo := NewBundle(mockBundleHeader())
+	bundleTwo.AddCheckDetail(cd)
+
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddBundle(bundle)
+	cl.AddBundle(bundleTwo)
+	bs := mockBoxSummary()
+	bs.BoxSequenceNumber = ""
+	cl.AddBoxSummary(bs)
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if bs.BoxSequenceNumber != "001" {
+		t.Errorf("BoxSequenceNumber: %s", bs.BoxSequenceNumber)
+	}
+
+	bs.BoxTotalAmount = 100000
+	if err := cl.Create(); err != nil {
+		if e, ok := err.(*CashLetterError); !ok || e.FieldName != "BoxTotalAmount" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+
+	bs.BoxTotalAmount = 200000
+	bs.BoxBundleCount = 1
+	if err := cl.Create(); err != nil {
+		if e, ok := err.(*CashLetterError); !ok || e.FieldName != "BoxBundleCount" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// mockRoutingNumberCashLetter creates a CashLetter with checks for two payor routing numbers in two Bundles
+func mockRoutingNumberCashLetter() CashLetter {
+	cd := mockCheckDetail()
+	cd.AddendumCount = 0
+	cdTwo := mockCheckDetail()
+	cdTwo.AddendumCount = 0
+	cdTwo.PayorBankRoutingNumber = "23138010"
+	cdTwo.PayorBankCheckDigit = "4"
+	cdTwo.ItemAmount = 25000
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCheckDetail(cd)
+	bundle.AddCheckDetail(cdTwo)
+	bundleTwo := NewBundle(mockBundleHeader())
+	bundleTwo.AddCheckDetail(cd)
+
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddBundle(bundle)
+	cl.AddBundle(bundleTwo)
+	return cl
+}
+
+// TestCashLetterCreateRoutingNumberSummary validates a RoutingNumberSummary is built for each payor routing number
+func TestCashLetterCreateRoutingNumberSummary(t *testing.T) {
+	cl := mockRoutingNumberCashLetter()
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(cl.RoutingNumberSummary) != 0 {
+		t.Errorf("RoutingNumberSummary built without CreateRoutingNumberSummaryOption")
+	}
+
+	rns := mockRoutingNumberSummary()
+	rns.UserField = "Kept"
+	cl.AddRoutingNumberSummary(rns)
+	if err := cl.Create(CreateRoutingNumberSummaryOption()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	summaries := cl.GetRoutingNumberSummary()
+	if len(summaries) != 2 {
+		t.Fatalf("built %d RoutingNumberSummary", len(summaries))
+	}
+	if s := summaries[0]; s.CashLetterRoutingNumber != "031300012" || s.RoutingNumberTotalAmount != 200000 ||
+		s.RoutingNumberItemCount != 2 || s.UserField != "" {
+		t.Errorf("RoutingNumberSummary: %s", s.String())
+	}
+	if s := summaries[1]; s.CashLetterRoutingNumber != "231380104" || s.RoutingNumberTotalAmount != 25000 ||
+		s.RoutingNumberItemCount != 1 || s.UserField != "Kept" {
+		t.Errorf("RoutingNumberSummary: %s", s.String())
+	}
+
+	// the built RoutingNumberSummary records are read back and validated
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	read, err := NewReader(strings.NewReader(b.String())).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(read.CashLetters[0].RoutingNumberSummary) != 2 {
+		t.Errorf("read %d RoutingNumberSummary", len(read.CashLetters[0].RoutingNumberSummary))
+	}
+
+	if err := read.Validate(ValidateRoutingNumberSummaryOption()); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	// a RoutingNumberSummary read which doesn't agree with the items is only an error with
+	// ValidateRoutingNumberSummaryOption
+	data := strings.Replace(b.String(), "8523138010400000000025000", "8523138010400000000025001", 1)
+	read, err = NewReader(strings.NewReader(data)).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := read.Validate(ValidateRoutingNumberSummaryOption()); err != nil {
+		if !strings.Contains(err.Error(), "RoutingNumberTotalAmount") {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestCashLetterRoutingNumberSummaryTotals validates RoutingNumberSummary records agree with the CheckDetail
+func TestCashLetterRoutingNumberSummaryTotals(t *testing.T) {
+	tests := map[string]func(cl *CashLetter){
+		"RoutingNumberTotalAmount": func(cl *CashLetter) {
+			cl.RoutingNumberSummary[0].RoutingNumberTotalAmount = 1
+		},
+		"RoutingNumberItemCount": func(cl *CashLetter) {
+			cl.RoutingNumberSummary[1].RoutingNumberItemCount = 2
+		},
+		"CashLetterRoutingNumber": func(cl *CashLetter) {
+			cl.AddRoutingNumberSummary(cl.RoutingNumberSummary[0])
+		},
+	}
+	for fieldName, fn := range tests {
+		cl := mockRoutingNumberCashLetter()
+		if err := cl.Create(CreateRoutingNumberSummaryOption()); err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		fn(&cl)
+		if err := cl.Validate(); err != nil {
+			t.Errorf("%s: %T: %s", fieldName, err, err)
+		}
+		if err := cl.validateRoutingNumberSummary(); err != nil {
+			if e, ok := err.(*CashLetterError); !ok || e.FieldName != fieldName {
+				t.Errorf("%T: %s", err, err)
+			}
+		} else {
+			t.Errorf("%s: expected error", fieldName)
+		}
+	}
+
+	// a RoutingNumberSummary for a routing number without items
+	cl := mockRoutingNumberCashLetter()
+	cl.AddRoutingNumberSummary(mockRoutingNumberSummary())
+	cl.RoutingNumberSummary[0].CashLetterRoutingNumber = "121042882"
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := file.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if err := file.Validate(ValidateRoutingNumberSummaryOption()); err != nil {
+		if !strings.Contains(err.Error(), "RoutingNumberTotalAmount") {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	report := file.ValidationReport(ValidateRoutingNumberSummaryOption())
+	if v := report.Violations[0]; v.Path != "/cashLetters/0/routingNumberSummary" || v.Severity != SeverityError {
+		t.Errorf("violations: %v", report.Violations)
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"errors"
+	"image"
+	"image/color"
+)
+
+// ErrCCITTData is returned when CCITT Group 4 image data is corrupt or ends before the last row of the image
+var ErrCCITTData = errors.New("corrupt or truncated CCITT Group 4 image data")
+
+// ccittWhiteCodes are the T.4 run length codes of white runs, terminating (0-63), makeup (64-1728) and extended
+// makeup (1792-2560) codes.
+var ccittWhiteCodes = map[int]string{
+	0: "00110101", 1: "000111", 2: "0111", 3: "1000", 4: "1011", 5: "1100", 6: "1110", 7: "1111",
+	8: "10011", 9: "10100", 10: "00111", 11: "01000", 12: "001000", 13: "000011", 14: "110100", 15: "110101",
+	16: "101010", 17: "101011", 18: "0100111", 19: "0001100", 20: "0001000", 21: "0010111", 22: "0000011",
+	23: "0000100", 24: "0101000", 25: "0101011", 26: "0010011", 27: "0100100", 28: "0011000", 29: "00000010",
+	30: "00000011", 31: "00011010", 32: "00011011", 33: "00010010", 34: "00010011", 35: "00010100",
+	36: "00010101", 37: "00010110", 38: "00010111", 39: "00101000", 40: "00101001", 41: "00101010",
+	42: "00101011", 43: "00101100", 44: "00101101", 45: "00000100", 46: "00000101", 47: "00001010",
+	48: "00001011", 49: "01010010", 50: "01010011", 51: "01010100", 52: "01010101", 53: "00100100",
+	54: "00100101", 55: "01011000", 56: "01011001", 57: "01011010", 58: "01011011", 59: "01001010",
+	60: "01001011", 61: "00110010", 62: "00110011", 63: "00110100",
+	64: "11011", 128: "10010", 192: "010111", 256: "0110111", 320: "00110110", 384: "00110111",
+	448: "01100100", 512: "01100101", 576: "01101000", 640: "01100111", 704: "011001100", 768: "011001101",
+	832: "011010010", 896: "011010011", 960: "011010100", 1024: "011010101", 1088: "011010110",
+	1152: "011010111", 1216: "011011000", 1280: "011011001", 1344: "011011010", 1408: "011011011",
+	1472: "010011000", 1536: "010011001", 1600: "010011010", 1664: "011000", 1728: "010011011",
+}
+
+// ccittBlackCodes are the T.4 run length codes of black runs, terminating (0-63), makeup (64-1728) and extended
+// makeup (1792-2560) codes.
+var ccittBlackCodes = map[int]string{
+	0: "0000110111", 1: "010", 2: "11", 3: "10", 4: "011", 5: "0011", 6: "0010", 7: "00011", 8: "000101",
+	9: "000100", 10: "0000100", 11: "0000101", 12: "0000111", 13: "00000100", 14: "00000111", 15: "000011000",
+	16: "0000010111", 17: "0000011000", 18: "0000001000", 19: "00001100111", 20: "00001101000",
+	21: "00001101100", 22: "00000110111", 23: "00000101000", 24: "00000010111", 25: "00000011000",
+	26: "000011001010", 27: "000011001011", 28: "000011001100", 29: "000011001101", 30: "000001101000",
+	31: "000001101001", 32: "000001101010", 33: "000001101011", 34: "000011010010", 35: "000011010011",
+	36: "000011010100", 37: "000011010101", 38: "000011010110", 39: "000011010111", 40: "000001101100",
+	41: "000001101101", 42: "000011011010", 43: "000011011011", 44: "000001010100", 45: "000001010101",
+	46: "000001010110", 47: "000001010111", 48: "000001100100", 49: "000001100101", 50: "000001010010",
+	51: "000001010011", 52: "000000100100", 53: "000000110111", 54: "000000111000", 55: "000000100111",
+	56: "000000101000", 57: "000001011000", 58: "000001011001", 59: "000000101011", 60: "000000101100",
+	61: "000001011010", 62: "000001100110", 63: "000001100111",
+	64: "0000001111", 128: "000011001000", 192: "000011001001", 256: "000001011011", 320: "000000110011",
+	384: "000000110100", 448: "000000110101", 512: "0000001101100", 576: "0000001101101",
+	640: "0000001001010", 704: "0000001001011", 768: "0000001001100", 832: "0000001001101",
+	896: "0000001110010", 960: "0000001110011", 1024: "0000001110100", 1088: "0000001110101",
+	1152: "0000001110110", 1216: "0000001110111", 1280: "0000001010010", 1344: "0000001010011",
+	1408: "0000001010100", 1472: "0000001010101", 1536: "0000001011010", 1600: "0000001011011",
+	1664: "0000001100100", 1728: "0000001100101",
+}
+
+// ccittExtendedCodes are the T.4 extended makeup codes shared by white and black runs
+var ccittExtendedCodes = map[int]string{
+	1792: "00000001000", 1856: "00000001100", 1920: "00000001101", 1984: "000000010010", 2048: "000000010011",
+	2112: "000000010100", 2176: "000000010101", 2240: "000000010110", 2304: "000000010111",
+	2368: "000000011100", 2432: "000000011101", 2496: "000000011110", 2560: "000000011111",
+}
+
+// T.6 coding modes
+const (
+	ccittPass = iota
+	ccittHorizontal
+	ccittV0
+	ccittVR1
+	ccittVR2
+	ccittVR3
+	ccittVL1
+	ccittVL2
+	ccittVL3
+	ccittEOL
+)
+
+// ccittModeCodes are the T.6 codes of each coding mode
+var ccittModeCodes = map[int]string{
+	ccittPass: "0001", ccittHorizontal: "001", ccittV0: "1", ccittVR1: "011", ccittVR2: "000011",
+	ccittVR3: "0000011", ccittVL1: "010", ccittVL2: "000010", ccittVL3: "0000010", ccittEOL: "000000000001",
+}
+
+// ccittVerticalOffsets are the offsets of a1 from b1 of each vertical mode
+var ccittVerticalOffsets = map[int]int{
+	ccittV0: 0, ccittVR1: 1, ccittVR2: 2, ccittVR3: 3, ccittVL1: -1, ccittVL2: -2, ccittVL3: -3,
+}
+
+// ccittTable decodes the codes of a table, keyed by the code length and bits
+type ccittTable map[uint32]int
+
+func newCCITTTable(tables ...map[int]string) ccittTable {
+	t := make(ccittTable)
+	for _, codes := range tables {
+		for value, code := range codes {
+			t[ccittKey(len(code), ccittBits(code))] = value
+		}
+	}
+	return t
+}
+
+func ccittKey(length int, bits uint32) uint32 {
+	return uint32(length)<<24 | bits
+}
+
+func ccittBits(code string) uint32 {
+	var bits uint32
+	for _, c := range code {
+		bits = bits<<1 | uint32(c-'0')
+	}
+	return bits
+}
+
+var (
+	ccittWhiteTable = newCCITTTable(ccittWhiteCodes, ccittExtendedCodes)
+	ccittBlackTable = newCCITTTable(ccittBlackCodes, ccittExtendedCodes)
+	ccittModeTable  = newCCITTTable(ccittModeCodes)
+)
+
+// ccittReader reads the bits of CCITT image data, most significant bit first (FillOrder 1)
+type ccittReader struct {
+	data []byte
+	pos  int
+}
+
+// read returns the value of the next code of table
+func (r *ccittReader) read(table ccittTable) (int, error) {
+	var bits uint32
+	for length := 1; length <= 13; length++ {
+		if r.pos >= len(r.data)*8 {
+			return 0, ErrCCITTData
+		}
+		bit := r.data[r.pos/8] >> (7 - uint(r.pos%8)) & 1
+		r.pos++
+		bits = bits<<1 | uint32(bit)
+		if v, ok := table[ccittKey(length, bits)]; ok {
+			return v, nil
+		}
+	}
+	return 0, ErrCCITTData
+}
+
+// run reads the makeup and terminating codes of a run of color
+func (r *ccittReader) run(black bool) (int, error) {
+	table := ccittWhiteTable
+	if black {
+		table = ccittBlackTable
+	}
+	total := 0
+	for {
+		v, err := r.read(table)
+		if err != nil {
+			return 0, err
+		}
+		total += v
+		if v < 64 {
+			return total, nil
+		}
+	}
+}
+
+// ccittChanges finds b1, the first changing element of the reference line after a0 which changes to the color
+// opposite to the color of a0, and b2, the changing element after b1. Changing elements at even indexes change
+// to black. Both are width when the reference line has no such changing element.
+func ccittChanges(ref []int, a0 int, black bool, width int) (int, int) {
+	for i, c := range ref {
+		if c <= a0 || (i%2 == 1) != black {
+			continue
+		}
+		if i+1 < len(ref) {
+			return c, ref[i+1]
+		}
+		return c, width
+	}
+	return width, width
+}
+
+// DecodeCCITTG4 decodes CCITT Group 4 (T.6) compressed image data of width by height pixels into a gray image
+// with black (0) and white (255) pixels. blackIsZero inverts the coded colors, for a TIFF PhotometricInterpretation
+// of 1. When the data is corrupt or ends early the rows decoded so far are returned with ErrCCITTData.
+func DecodeCCITTG4(data []byte, width, height int, blackIsZero bool) (*image.Gray, error) {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	rows, err := decodeCCITTG4Rows(data, width, height, blackIsZero, img, 0)
+	if err != nil {
+		return img.SubImage(image.Rect(0, 0, width, rows)).(*image.Gray), err
+	}
+	return img, nil
+}
+
+// decodeCCITTG4Rows decodes height rows of data into img starting at row y, and returns the number of rows
+// decoded.
+func decodeCCITTG4Rows(data []byte, width, height int, blackIsZero bool, img *image.Gray, y int) (int, error) {
+	if width <= 0 {
+		return 0, ErrCCITTData
+	}
+	black, white := color.Gray{Y: 0}, color.Gray{Y: 255}
+	if blackIsZero {
+		black, white = white, black
+	}
+	r := &ccittReader{data: data}
+	var ref []int
+	for row := 0; row < height; row++ {
+		line, err := r.line(ref, width)
+		if err != nil {
+			return row, err
+		}
+		// paint the row, pixels change color at each changing element
+		x, isBlack := 0, false
+		for _, c := range append(line, width) {
+			if c > width {
+				c = width
+			}
+			for ; x < c; x++ {
+				if isBlack {
+					img.SetGray(x, y+row, black)
+				} else {
+					img.SetGray(x, y+row, white)
+				}
+			}
+			isBlack = !isBlack
+		}
+		ref = line
+	}
+	return height, nil
+}
+
+// line decodes the changing elements of a coding line with the changing elements of the reference line ref
+func (r *ccittReader) line(ref []int, width int) ([]int, error) {
+	var line []int
+	a0, black := -1, false
+	for a0 < width {
+		mode, err := r.read(ccittModeTable)
+		if err != nil {
+			return nil, err
+		}
+		b1, b2 := ccittChanges(ref, a0, black, width)
+		switch mode {
+		case ccittPass:
+			a0 = b2
+		case ccittHorizontal:
+			r1, err := r.run(black)
+			if err != nil {
+				return nil, err
+			}
+			r2, err := r.run(!black)
+			if err != nil {
+				return nil, err
+			}
+			if a0 < 0 {
+				a0 = 0
+			}
+			a1 := a0 + r1
+			a2 := a1 + r2
+			if a2 > width {
+				return nil, ErrCCITTData
+			}
+			line = append(line, a1, a2)
+			a0 = a2
+		case ccittEOL:
+			// end of facsimile block before the last row
+			return nil, ErrCCITTData
+		default:
+			a1 := b1 + ccittVerticalOffsets[mode]
+			if a1 < 0 || a1 > width || a1 < a0 {
+				return nil, ErrCCITTData
+			}
+			line = append(line, a1)
+			a0 = a1
+			black = !black
+		}
+	}
+	// changing elements at the end of the line don't change any pixel
+	for len(line) > 0 && line[len(line)-1] >= width {
+		line = line[:len(line)-1]
+	}
+	return line, nil
+}
+
+// ccittWriter writes the bits of CCITT image data, most significant bit first (FillOrder 1)
+type ccittWriter struct {
+	data []byte
+	bits uint
+}
+
+// write appends the bits of a code
+func (w *ccittWriter) write(code string) {
+	for _, c := range code {
+		if w.bits%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if c == '1' {
+			w.data[len(w.data)-1] |= 0x80 >> (w.bits % 8)
+		}
+		w.bits++
+	}
+}
+
+// run writes the makeup and terminating codes of a run of color
+func (w *ccittWriter) run(length int, black bool) {
+	codes := ccittWhiteCodes
+	if black {
+		codes = ccittBlackCodes
+	}
+	for length >= 2560 {
+		w.write(ccittExtendedCodes[2560])
+		length -= 2560
+	}
+	if makeup := length - length%64; makeup >= 1792 {
+		w.write(ccittExtendedCodes[makeup])
+	} else if makeup > 0 {
+		w.write(codes[makeup])
+	}
+	w.write(codes[length%64])
+}
+
+// EncodeCCITTG4 encodes a gray image as CCITT Group 4 (T.6) compressed image data, for a TIFF
+// PhotometricInterpretation of 0 (WhiteIsZero) and FillOrder of 1. Pixels darker than 128 are coded black.
+func EncodeCCITTG4(img *image.Gray) []byte {
+	b := img.Bounds()
+	width := b.Dx()
+	w := &ccittWriter{}
+	var ref []int
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		// changing elements of the coding line, at even indexes changing to black
+		var line []int
+		isBlack := false
+		for x := 0; x < width; x++ {
+			if (img.GrayAt(b.Min.X+x, y).Y < 128) != isBlack {
+				line = append(line, x)
+				isBlack = !isBlack
+			}
+		}
+		next := func(a0 int) int {
+			for _, c := range line {
+				if c > a0 {
+					return c
+				}
+			}
+			return width
+		}
+
+		a0, black := -1, false
+		for a0 < width {
+			a1 := next(a0)
+			b1, b2 := ccittChanges(ref, a0, black, width)
+			switch {
+			case b2 < a1:
+				w.write(ccittModeCodes[ccittPass])
+				a0 = b2
+			case a1-b1 >= -3 && a1-b1 <= 3:
+				for mode, offset := range ccittVerticalOffsets {
+					if offset == a1-b1 {
+						w.write(ccittModeCodes[mode])
+					}
+				}
+				a0 = a1
+				black = !black
+			default:
+				a2 := next(a1)
+				start := a0
+				if start < 0 {
+					start = 0
+				}
+				w.write(ccittModeCodes[ccittHorizontal])
+				w.run(a1-start, black)
+				w.run(a2-a1, !black)
+				a0 = a2
+			}
+		}
+		ref = line
+	}
+	// end of facsimile block
+	w.write(ccittModeCodes[ccittEOL])
+	w.write(ccittModeCodes[ccittEOL])
+	return w.data
+}
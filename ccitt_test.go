@@ -0,0 +1,116 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mockG4ImageView reads the front image of a check from valid-ascii.x937, a 1200 x 550 Group 4 TIFF image at 200 DPI
+func mockG4ImageView(t *testing.T) (ImageViewDetail, ImageViewData) {
+	t.Helper()
+	fd, err := os.Open(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	defer fd.Close()
+	file, err := NewReader(fd, ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	cd := file.CashLetters[0].Bundles[0].Checks[0]
+	return cd.ImageViewDetail[0], cd.ImageViewData[0]
+}
+
+// TestDecodeCCITTG4 validates Group 4 image data is decoded
+func TestDecodeCCITTG4(t *testing.T) {
+	ivDetail, ivData := mockG4ImageView(t)
+	info, err := InspectImageView(&ivDetail, &ivData)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	strip := ivData.ImageData[info.StripOffsets[0] : info.StripOffsets[0]+info.StripByteCounts[0]]
+
+	img, err := DecodeCCITTG4(strip, info.Width, info.Height, false)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if b := img.Bounds(); b.Dx() != 1200 || b.Dy() != 550 {
+		t.Errorf("unexpected bounds: %v", b)
+	}
+	black := 0
+	for _, p := range img.Pix {
+		switch p {
+		case 0:
+			black++
+		case 255:
+		default:
+			t.Fatalf("unexpected pixel: %d", p)
+		}
+	}
+	if black == 0 || black > len(img.Pix)/4 {
+		t.Errorf("unexpected black pixels: %d", black)
+	}
+
+	// blackIsZero inverts the image
+	inverted, err := DecodeCCITTG4(strip, info.Width, info.Height, true)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	for i := range img.Pix {
+		if img.Pix[i] != 255-inverted.Pix[i] {
+			t.Fatalf("pixel %d is not inverted", i)
+		}
+	}
+
+	// truncated data returns the rows decoded
+	partial, err := DecodeCCITTG4(strip[:len(strip)/2], info.Width, info.Height, false)
+	if err != ErrCCITTData {
+		t.Errorf("%T: %s", err, err)
+	}
+	if rows := partial.Bounds().Dy(); rows == 0 || rows >= info.Height {
+		t.Errorf("unexpected rows: %d", rows)
+	}
+	if _, err := DecodeCCITTG4([]byte{0, 0, 0, 0}, info.Width, info.Height, false); err != ErrCCITTData {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestEncodeCCITTG4 validates images are encoded as Group 4 image data which decodes to the same image
+func TestEncodeCCITTG4(t *testing.T) {
+	ivDetail, ivData := mockG4ImageView(t)
+	info, err := InspectImageView(&ivDetail, &ivData)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	strip := ivData.ImageData[info.StripOffsets[0] : info.StripOffsets[0]+info.StripByteCounts[0]]
+	img, err := DecodeCCITTG4(strip, info.Width, info.Height, false)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	// a wide image has runs coded with extended makeup codes
+	wide := image.NewGray(image.Rect(0, 0, 3000, 4))
+	for i := range wide.Pix {
+		if x := i % 3000; x > 2600 || (i/3000 == 2 && x < 2000) {
+			wide.Pix[i] = 255
+		}
+	}
+
+	for _, img := range []*image.Gray{img, wide} {
+		b := img.Bounds()
+		decoded, err := DecodeCCITTG4(EncodeCCITTG4(img), b.Dx(), b.Dy(), false)
+		if err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		if !bytes.Equal(img.Pix, decoded.Pix) {
+			t.Errorf("%v image does not decode to the same image", b)
+		}
+	}
+}
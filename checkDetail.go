@@ -147,10 +147,17 @@ type CheckDetail struct {
 	ImageViewData []ImageViewData `json:"imageViewData"`
 	// ImageViewAnalysis
 	ImageViewAnalysis []ImageViewAnalysis `json:"imageViewAnalysis"`
+	// UserRecords are the User Records which follow the CheckDetail
+	UserRecords UserRecords `json:"userRecords,omitempty"`
+	// UserRecordPositions are the number of addendum and image view records each of the UserRecords follows.
+	// UserRecords without a position follow the image view records.
+	UserRecordPositions []int `json:"userRecordPositions,omitempty"`
 	// validator is composed for imagecashletter data validation
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCheckDetail returns a new CheckDetail with default values for non exported fields
@@ -289,6 +296,10 @@ func (cd *CheckDetail) Validate() error {
 			return &FieldError{FieldName: "ArchiveTypeIndicator", Value: cd.ArchiveTypeIndicator, Msg: err.Error()}
 		}
 	}
+	// AuxiliaryOnUs and OnUs must be MICR fields
+	if err := validateMICR(cd.AuxiliaryOnUs, cd.OnUs); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -464,6 +475,23 @@ func (cd *CheckDetail) GetImageViewAnalysis() []ImageViewAnalysis {
 	return cd.ImageViewAnalysis
 }
 
+// AddUserRecord appends a UserRecord to the CheckDetail
+func (cd *CheckDetail) AddUserRecord(ur UserRecord) UserRecords {
+	cd.UserRecords = append(cd.UserRecords, ur)
+	return cd.UserRecords
+}
+
+// GetUserRecords returns a slice of UserRecord for the CheckDetail
+func (cd *CheckDetail) GetUserRecords() UserRecords {
+	return cd.UserRecords
+}
+
+// itemRecordCount returns the number of addendum and image view records of the CheckDetail
+func (cd *CheckDetail) itemRecordCount() int {
+	return len(cd.CheckDetailAddendumA) + len(cd.CheckDetailAddendumB) + len(cd.CheckDetailAddendumC) +
+		len(cd.ImageViewDetail) + len(cd.ImageViewData) + len(cd.ImageViewAnalysis)
+}
+
 // SetEceInstitutionItemSequenceNumber sets EceInstitutionItemSequenceNumber
 func (cd *CheckDetail) SetEceInstitutionItemSequenceNumber(seq int) string {
 	cd.EceInstitutionItemSequenceNumber = cd.numericField(seq, 15)
@@ -83,6 +83,8 @@ type CheckDetailAddendumA struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCheckDetailAddendumA returns a new CheckDetailAddendumA with default values for non exported fields
@@ -50,6 +50,8 @@ type CheckDetailAddendumB struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCheckDetailAddendumB returns a new CheckDetailAddendumB with default values for non exported fields
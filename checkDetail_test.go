@@ -43,8 +43,8 @@ func TestMockCheckDetail(t *testing.T) {
 	if err := cd.Validate(); err != nil {
 		t.Error("mockCheckDetail does not validate and will break other tests: ", err)
 	}
-	if cd.recordType != "25" {
-		t.Error("recordType does not validate")
+	if cd.RecordType != "25" {
+		t.Error("RecordType does not validate")
 	}
 	if cd.AuxiliaryOnUs != "123456789" {
 		t.Error("AuxiliaryOnUs does not validate")
@@ -107,8 +107,8 @@ func TestParseCheckDetail(t *testing.T) {
 	}
 	record := r.currentCashLetter.currentBundle.GetChecks()[0]
 
-	if record.recordType != "25" {
-		t.Errorf("RecordType Expected '25' got: %v", record.recordType)
+	if record.RecordType != "25" {
+		t.Errorf("RecordType Expected '25' got: %v", record.RecordType)
 	}
 	if record.AuxiliaryOnUsField() != "      123456789" {
 		t.Errorf("AuxiliaryOnUs Expected '      123456789' got: %v", record.AuxiliaryOnUsField())
@@ -193,10 +193,10 @@ func BenchmarkCDString(b *testing.B) {
 // TestCDRecordType validation
 func TestCDRecordType(t *testing.T) {
 	cd := mockCheckDetail()
-	cd.recordType = "00"
+	cd.RecordType = "00"
 	if err := cd.Validate(); err != nil {
 		if e, ok := err.(*FieldError); ok {
-			if e.FieldName != "recordType" {
+			if e.FieldName != "RecordType" {
 				t.Errorf("%T: %s", err, err)
 			}
 		}
@@ -299,10 +299,10 @@ func TestCDArchiveTypeIndicator(t *testing.T) {
 // TestCDFIRecordType validation
 func TestCDFIRecordType(t *testing.T) {
 	cd := mockCheckDetail()
-	cd.recordType = ""
+	cd.RecordType = ""
 	if err := cd.Validate(); err != nil {
 		if e, ok := err.(*FieldError); ok {
-			if e.FieldName != "recordType" {
+			if e.FieldName != "RecordType" {
 				t.Errorf("%T: %s", err, err)
 			}
 		}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/moov-io/imagecashletter"
+)
+
+var (
+	flagJson = flag.Bool("json", false, "Output the differences in JSON to stdout")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-json] original.icl other.icl\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// exit like diff: 0 when the files are the same, 1 when they differ and 2 on trouble
+	equal, err := diff(flag.Arg(0), flag.Arg(1), *flagJson, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(2)
+	}
+	if !equal {
+		os.Exit(1)
+	}
+}
+
+// diff writes the differences of the ICL files at two paths to w, and returns true when they're the same
+func diff(fromPath, toPath string, jsonOutput bool, w io.Writer) (bool, error) {
+	from, err := readFile(fromPath)
+	if err != nil {
+		return false, err
+	}
+	to, err := readFile(toPath)
+	if err != nil {
+		return false, err
+	}
+
+	d := imagecashletter.DiffFiles(from, to)
+	if jsonOutput {
+		if err := json.NewEncoder(w).Encode(d); err != nil {
+			return false, fmt.Errorf("problem writing differences: %v", err)
+		}
+	} else if err := d.WriteText(w); err != nil {
+		return false, fmt.Errorf("problem writing differences: %v", err)
+	}
+	return d.Equal(), nil
+}
+
+// readFile reads the ICL file at path, detecting whether it's ASCII or EBCDIC and how its records are framed
+func readFile(path string) (*imagecashletter.File, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	file, err := imagecashletter.NewReader(fd, imagecashletter.ReadDetectFormatOption()).Read()
+	if err != nil {
+		return nil, fmt.Errorf("problem reading %s: %v", path, err)
+	}
+	return &file, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	original := filepath.Join("..", "..", "test", "testdata", "BNK20180905121042882-A.icl")
+	other := filepath.Join("..", "..", "test", "testdata", "BNK20181010121042882-A.icl")
+
+	var buf bytes.Buffer
+	equal, err := diff(original, original, false, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal || buf.Len() != 0 {
+		t.Errorf("unexpected differences: %s", buf.String())
+	}
+
+	buf.Reset()
+	equal, err = diff(original, other, false, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if equal || !strings.Contains(buf.String(), "changed FileHeader /fileHeader\n") {
+		t.Errorf("unexpected differences: %s", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := diff(original, other, true, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Records []struct {
+			Kind string `json:"kind"`
+			Path string `json:"path"`
+		} `json:"records"`
+	}
+	if err := json.NewDecoder(&buf).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Records) == 0 || resp.Records[0].Path != "/fileHeader" {
+		t.Errorf("unexpected differences: %v", resp.Records)
+	}
+
+	if _, err := diff(original, "missing.icl", false, &buf); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"github.com/moov-io/imagecashletter"
+	"io/ioutil"
 	"log"
 	"os"
 	"runtime/pprof"
@@ -15,6 +16,9 @@ var (
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 
 	flagJson = flag.Bool("json", false, "Output ICL File in JSON to stdout")
+
+	// image digital signatures
+	verifyKey = flag.String("verifyKey", "", "Verify image digital signatures with the PEM encoded public key file")
 )
 
 func main() {
@@ -38,7 +42,8 @@ func main() {
 		log.Panicf("Can not open file: %s: \n", err)
 	}
 
-	r := imagecashletter.NewReader(f)
+	// detect whether the file is ASCII or EBCDIC, and how its records are framed
+	r := imagecashletter.NewReader(f, imagecashletter.ReadDetectFormatOption())
 	ICLFile, err := r.Read()
 	if err != nil {
 		fmt.Printf("Issue reading file: %+v \n", err)
@@ -54,6 +59,25 @@ func main() {
 		fmt.Printf("Could not build file with read properties: %v", err)
 	}
 
+	// Verify the digital signatures of signed images
+	if *verifyKey != "" {
+		data, err := ioutil.ReadFile(*verifyKey)
+		if err != nil {
+			log.Fatalf("Can not read public key: %v", err)
+		}
+		key, err := imagecashletter.ParsePublicKeyPEM(data)
+		if err != nil {
+			log.Fatalf("Can not parse public key: %v", err)
+		}
+		failures := ICLFile.VerifyImageSignatures(imagecashletter.StaticImageKey(key))
+		for _, failure := range failures {
+			fmt.Printf("Image signature failed: %v\n", failure)
+		}
+		if len(failures) > 0 {
+			os.Exit(1)
+		}
+	}
+
 	// Output file contents
 	if *flagJson {
 		if err := json.NewEncoder(os.Stdout).Encode(ICLFile); err != nil {
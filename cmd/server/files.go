@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/moov-io/base"
@@ -24,8 +25,15 @@ import (
 var (
 	errNoFileId       = errors.New("no File ID found")
 	errNoCashLetterId = errors.New("no CashLetter ID found")
+	errNoProfile      = errors.New("validation profile not found")
+	errNoDirectory    = errors.New("no routing directory loaded")
+	errImageSignature = errors.New("image digital signatures failed verification")
 )
 
+// routingDirectory is the RoutingDirectory of ROUTING_DIRECTORY_FILES, used to validate files with
+// ?routingDirectory=true
+var routingDirectory *imagecashletter.RoutingDirectory
+
 func addFileRoutes(logger log.Logger, r *mux.Router, repo ICLFileRepository) {
 	r.Methods("GET").Path("/files").HandlerFunc(getFiles(logger, repo))
 	r.Methods("POST").Path("/files/create").HandlerFunc(createFile(logger, repo))
@@ -35,6 +43,7 @@ func addFileRoutes(logger log.Logger, r *mux.Router, repo ICLFileRepository) {
 
 	r.Methods("GET").Path("/files/{fileId}/contents").HandlerFunc(getFileContents(logger, repo))
 	r.Methods("GET").Path("/files/{fileId}/validate").HandlerFunc(validateFile(logger, repo))
+	r.Methods("POST").Path("/files/{fileId}/signatures/verify").HandlerFunc(verifyFileSignatures(logger, repo))
 
 	r.Methods("POST").Path("/files/{fileId}/cashLetters").HandlerFunc(addCashLetterToFile(logger, repo))
 	r.Methods("DELETE").Path("/files/{fileId}/cashLetters/{cashLetterId}").HandlerFunc(removeCashLetterFromFile(logger, repo))
@@ -336,20 +345,143 @@ func validateFile(logger log.Logger, repo ICLFileRepository) http.HandlerFunc {
 			return
 		}
 
-		if err := file.Create(); err != nil { // Create calls Validate
-			err = logger.LogErrorf("file=%s was invalid: %v", fileId, err).Err()
+		var opts []imagecashletter.ValidateOption
+		if name := r.URL.Query().Get("profile"); name != "" {
+			profile := imagecashletter.LookupValidationProfile(name)
+			if profile == nil {
+				logger.LogErrorf("validation profile %q was not found", name)
+				moovhttp.Problem(w, errNoProfile)
+				return
+			}
+			opts = append(opts, imagecashletter.ValidateProfileOption(profile))
+		}
+		if images, _ := strconv.ParseBool(r.URL.Query().Get("images")); images {
+			opts = append(opts, imagecashletter.ValidateImagesOption())
+		}
+		switch routingNumbers := r.URL.Query().Get("routingNumbers"); routingNumbers {
+		case "all":
+			opts = append(opts, imagecashletter.ValidateAllRoutingNumbersOption())
+		default:
+			if ok, _ := strconv.ParseBool(routingNumbers); ok {
+				opts = append(opts, imagecashletter.ValidateRoutingNumbersOption())
+			}
+		}
+		if directory, _ := strconv.ParseBool(r.URL.Query().Get("routingDirectory")); directory {
+			if routingDirectory == nil {
+				logger.LogError(errNoDirectory)
+				moovhttp.Problem(w, errNoDirectory)
+				return
+			}
+			opts = append(opts, imagecashletter.ValidateRoutingDirectoryOption(routingDirectory))
+		}
+
+		report := file.ValidationReport(opts...)
+		resp := validateFileResponse{ValidationReport: report}
+		status := http.StatusOK
+		err = file.Create() // Create calls Validate
+		if err == nil {
+			err = report.Err()
+		}
+		if strict, _ := strconv.ParseBool(r.URL.Query().Get("strict")); strict && err == nil {
+			// warnings, such as out-of-balance control totals, are errors too
+			var errs base.ErrorList
+			for i := range report.Violations {
+				errs.Add(&report.Violations[i])
+			}
+			if !errs.Empty() {
+				err = errs
+			}
+		}
+		if err != nil {
+			logger.LogErrorf("file=%s was invalid: %v", fileId, err)
+			msg := err.Error()
+			resp.Error = &msg
+			status = http.StatusBadRequest
+		} else {
+			logger.Log("validated file")
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// validateFileResponse is the ValidationReport of a file with the error of every violation which makes it invalid
+type validateFileResponse struct {
+	Error *string `json:"error"`
+	*imagecashletter.ValidationReport
+}
+
+// verifyFileSignatures verifies the digital signature of every signed image of a file with the PEM encoded public
+// key in the request body.
+func verifyFileSignatures(logger log.Logger, repo ICLFileRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if requestID := moovhttp.GetRequestID(r); requestID != "" {
+			logger = logger.Set("requestID", log.String(requestID))
+		}
+
+		w = wrapResponseWriter(logger, w, r)
+
+		fileId := getFileId(w, r)
+		if fileId == "" {
+			logger.LogError(errNoFileId)
+			return
+		}
+		logger = logger.Set("fileID", log.String(fileId))
+
+		bs, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			err = logger.LogErrorf("error reading public key: %v", err).Err()
+			moovhttp.Problem(w, err)
+			return
+		}
+		key, err := imagecashletter.ParsePublicKeyPEM(bs)
+		if err != nil {
+			err = logger.LogErrorf("error parsing public key: %v", err).Err()
+			moovhttp.Problem(w, err)
+			return
+		}
+
+		file, err := repo.getFile(fileId)
+		if err != nil {
+			err = logger.LogErrorf("error retrieving file: %v", err).Err()
 			moovhttp.Problem(w, err)
 			return
 		}
 
-		logger.Log("validated file")
+		if file == nil {
+			logger.Logf("file %q was not found", fileId)
+			http.NotFound(w, r)
+			return
+		}
+
+		resp := verifyFileSignaturesResponse{
+			Failures: file.VerifyImageSignatures(imagecashletter.StaticImageKey(key)),
+		}
+		status := http.StatusOK
+		if len(resp.Failures) > 0 {
+			logger.LogErrorf("file=%s has %d images which failed signature verification", fileId, len(resp.Failures))
+			msg := errImageSignature.Error()
+			resp.Error = &msg
+			status = http.StatusBadRequest
+		} else {
+			resp.Failures = []*imagecashletter.ImageSignatureError{}
+			logger.Log("verified image signatures")
+		}
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(`{"error": null}`)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
 	}
 }
 
+// verifyFileSignaturesResponse is every image of a file whose digital signature failed verification
+type verifyFileSignaturesResponse struct {
+	Error    *string                                `json:"error"`
+	Failures []*imagecashletter.ImageSignatureError `json:"failures"`
+}
+
 func addCashLetterToFile(logger log.Logger, repo ICLFileRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if requestID := moovhttp.GetRequestID(r); requestID != "" {
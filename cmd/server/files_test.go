@@ -6,7 +6,12 @@ package main
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/http"
@@ -297,7 +302,144 @@ func TestFiles_validateFile(t *testing.T) {
 		w.Flush()
 
 		require.Equal(t, http.StatusOK, w.Code, w.Body)
-		assert.Contains(t, w.Body.String(), `"{\"error\": null}"`)
+
+		var resp struct {
+			Error      *string                     `json:"error"`
+			Violations []imagecashletter.Violation `json:"violations"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Nil(t, resp.Error)
+		for _, v := range resp.Violations {
+			assert.Equal(t, imagecashletter.SeverityWarning, v.Severity, v)
+		}
+	})
+
+	t.Run("control mismatch", func(t *testing.T) {
+		file := readFile(t, "BNK20180905121042882-A.icl")
+		file.CashLetters[0].Bundles[0].BundleControl.BundleTotalAmount = 1
+		repo.file = file
+		defer func() { repo.file = f }()
+
+		// out-of-balance control totals are warnings
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body)
+
+		// and errors with ?strict=true
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?strict=true", nil))
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+
+		var resp struct {
+			Error      *string                     `json:"error"`
+			Violations []imagecashletter.Violation `json:"violations"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotNil(t, resp.Error)
+		assert.Contains(t, *resp.Error, "BundleTotalAmount")
+	})
+
+	t.Run("invalid addendum count", func(t *testing.T) {
+		file := readFile(t, "BNK20180905121042882-A.icl")
+		file.CashLetters[0].Bundles[0].Checks[0].AddendumCount = 9
+		repo.file = file
+		defer func() { repo.file = f }()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+
+		var resp struct {
+			Error *string `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotNil(t, resp.Error)
+		assert.Contains(t, *resp.Error, "AddendumCount")
+	})
+
+	t.Run("validation profile", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?profile=ca015", nil))
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+
+		var resp struct {
+			Profile    string                      `json:"profile"`
+			Violations []imagecashletter.Violation `json:"violations"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "ca015", resp.Profile)
+		require.NotEmpty(t, resp.Violations)
+		assert.Equal(t, "CountryCode", resp.Violations[0].FieldName)
+	})
+
+	t.Run("inspect images", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?images=true", nil))
+		w.Flush()
+
+		// the images of the test file are placeholders, not TIFF
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+
+		var resp struct {
+			Violations []imagecashletter.Violation `json:"violations"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotEmpty(t, resp.Violations)
+		assert.Equal(t, "/cashLetters/0/bundles/0/checks/0/imageViewData/0", resp.Violations[0].Path)
+		assert.Equal(t, "ImageData", resp.Violations[0].FieldName)
+	})
+
+	t.Run("routing numbers", func(t *testing.T) {
+		for _, routingNumbers := range []string{"true", "all"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?routingNumbers="+routingNumbers, nil))
+			w.Flush()
+
+			// the routing numbers of the test file have valid check digits
+			require.Equal(t, http.StatusOK, w.Code, w.Body)
+		}
+	})
+
+	t.Run("routing directory", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?routingDirectory=true", nil))
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+		assert.Contains(t, w.Body.String(), errNoDirectory.Error())
+
+		// the routing numbers of the test file are not in an empty directory
+		routingDirectory = imagecashletter.NewRoutingDirectory()
+		defer func() { routingDirectory = nil }()
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?routingDirectory=true", nil))
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+		var resp struct {
+			Violations []imagecashletter.Violation `json:"violations"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotEmpty(t, resp.Violations)
+		assert.Equal(t, "/cashLetters/0/cashLetterHeader", resp.Violations[0].Path)
+		assert.Equal(t, "DestinationRoutingNumber", resp.Violations[0].FieldName)
+	})
+
+	t.Run("unknown validation profile", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/files/foo/validate?profile=unknown", nil))
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+		assert.Contains(t, w.Body.String(), errNoProfile.Error())
 	})
 
 	t.Run("invalid file", func(t *testing.T) {
@@ -308,6 +450,17 @@ func TestFiles_validateFile(t *testing.T) {
 		w.Flush()
 
 		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+
+		var resp struct {
+			Error      *string                     `json:"error"`
+			Violations []imagecashletter.Violation `json:"violations"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotNil(t, resp.Error)
+		require.NotEmpty(t, resp.Violations)
+		assert.Equal(t, "/fileHeader", resp.Violations[0].Path)
+		assert.Equal(t, "FileHeader", resp.Violations[0].RecordName)
+		assert.Equal(t, imagecashletter.SeverityError, resp.Violations[0].Severity)
 	})
 
 	t.Run("repo error", func(t *testing.T) {
@@ -320,6 +473,80 @@ func TestFiles_validateFile(t *testing.T) {
 	})
 }
 
+func TestFiles_verifyFileSignatures(t *testing.T) {
+	repo := &testICLFileRepository{}
+	router := mux.NewRouter()
+	addFileRoutes(log.NewNopLogger(), router, repo)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	verify := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/files/foo/signatures/verify", bytes.NewReader(publicKey)))
+		w.Flush()
+		return w
+	}
+
+	t.Run("file not found", func(t *testing.T) {
+		w := verify()
+		require.Equal(t, http.StatusNotFound, w.Code, w.Body)
+	})
+
+	f := readFile(t, "BNK20180905121042882-A.icl")
+	cd := f.CashLetters[0].Bundles[0].Checks[0]
+	cd.ImageViewDetail[0].DigitalSignatureMethod = ""
+	require.NoError(t, imagecashletter.SignImageView(&cd.ImageViewDetail[0], &cd.ImageViewData[0], key))
+	repo.file = f
+
+	t.Run("verified", func(t *testing.T) {
+		w := verify()
+		require.Equal(t, http.StatusOK, w.Code, w.Body)
+
+		var resp verifyFileSignaturesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Nil(t, resp.Error)
+		assert.Empty(t, resp.Failures)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		cd.ImageViewData[0].ImageData[0]++
+		w := verify()
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+
+		var resp verifyFileSignaturesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotNil(t, resp.Error)
+		require.Len(t, resp.Failures, 1)
+		assert.Equal(t, "/cashLetters/0/bundles/0/checks/0/imageViewData/0", resp.Failures[0].Path)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/files/foo/signatures/verify", strings.NewReader("key")))
+		w.Flush()
+
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body)
+	})
+}
+
+func TestRegisterValidationProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	data := `[{"name": "server-test", "fields": {"FileHeader.UserField": {"required": true}}}]`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	require.NoError(t, registerValidationProfiles(path))
+	profile := imagecashletter.LookupValidationProfile("server-test")
+	require.NotNil(t, profile)
+	assert.True(t, profile.Fields["FileHeader.UserField"].Required)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": ""}]`), 0600))
+	require.Error(t, registerValidationProfiles(path))
+	require.Error(t, registerValidationProfiles(filepath.Join(t.TempDir(), "missing.json")))
+}
+
 func TestFiles_addCashLetterToFile(t *testing.T) {
 	repo := &testICLFileRepository{}
 	router := mux.NewRouter()
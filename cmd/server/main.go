@@ -45,6 +45,23 @@ func main() {
 
 	logger.Logf("Starting moov-io/imagecashletter server version %s", imagecashletter.Version)
 
+	if path := os.Getenv("VALIDATION_PROFILES_FILE"); path != "" {
+		if err := registerValidationProfiles(path); err != nil {
+			logger.Fatal().LogErrorf("problem registering validation profiles: %v", err)
+			os.Exit(1)
+		}
+		logger.Logf("registered validation profiles from %s", path)
+	}
+	if paths := os.Getenv("ROUTING_DIRECTORY_FILES"); paths != "" {
+		directory, err := imagecashletter.LoadRoutingDirectory(strings.Split(paths, ",")...)
+		if err != nil {
+			logger.Fatal().LogErrorf("problem loading routing directory: %v", err)
+			os.Exit(1)
+		}
+		routingDirectory = directory
+		logger.Logf("loaded %d routing numbers from %s", directory.Len(), paths)
+	}
+
 	// Channel for errors
 	errs := make(chan error)
 
@@ -133,6 +150,26 @@ func main() {
 	}
 }
 
+// registerValidationProfiles registers the validation profiles of a JSON file
+func registerValidationProfiles(path string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	profiles, err := imagecashletter.ReadValidationProfiles(fd)
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if err := imagecashletter.RegisterValidationProfile(profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func addPingRoute(r *mux.Router) {
 	r.Methods("GET").Path("/ping").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		moovhttp.SetAccessControlAllowHeaders(w, r.Header.Get("Origin"))
@@ -0,0 +1,294 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Current Implementation: Credit(s) Precede CheckDetail(s) and ReturnDetail(s) within a Bundle. Credit(s) are
+// included in the BundleControl, CashLetterControl and FileControl totals with a CreditTotalIndicator of 1.
+//
+// FileHeader
+// CashLetterHeader Record
+// BundleHeader Record
+// Credit
+// 1st CheckDetail
+// 2nd CheckDetail
+// N* CheckDetail
+// Last CheckDetail
+// BundleControl
+// CashLetterControl
+// FileControl
+
+// Credit Record
+type Credit struct {
+	// ID is a client defined string used as a reference to this record.
+	ID string `json:"id"`
+	// RecordType defines the type of record.
+	recordType string
+	// AuxiliaryOnUs identifies a code used on commercial checks at the discretion of the payor bank.
+	AuxiliaryOnUs string `json:"auxiliaryOnUs"`
+	// ExternalProcessingCode identifies a code used for special purposes as authorized by the Accredited
+	// Standards Committee X9. Also known as Position 44.
+	ExternalProcessingCode string `json:"externalProcessingCode"`
+	// PayorBankRoutingNumber is a routing number assigned by the posting bank to identify this credit.
+	// Format: TTTTAAAAC, where:
+	// TTTT: Federal Reserve Prefix
+	// AAAA: ABA Institution Identifier
+	// C: Payor Bank Routing Number Check Digit
+	PayorBankRoutingNumber string `json:"payorBankRoutingNumber"`
+	// CreditAccountNumberOnUs identifies data specified by the payor bank. On-Us data usually consists of the
+	// account number to be credited, a serial number or transaction code, or both.
+	CreditAccountNumberOnUs string `json:"creditAccountNumberOnUs"`
+	// ItemAmount identifies the amount of the credit.  All amounts fields have two implied decimal points.
+	// e.g., 100000 is $1,000.00
+	ItemAmount int `json:"itemAmount"`
+	// ECEInstitutionItemSequenceNumber identifies a number assigned by the institution that creates the Credit.
+	ECEInstitutionItemSequenceNumber string `json:"eceInstitutionItemSequenceNumber"`
+	// DocumentationTypeIndicator identifies a code that indicates the type of documentation that supports the
+	// credit record. See CreditItem.DocumentationTypeIndicator for values.
+	DocumentationTypeIndicator string `json:"documentationTypeIndicator"`
+	// AccountTypeCode is a code that indicates the type of account to which this Credit is associated.
+	// Values:
+	// 0: Unknown
+	// 1: DDA account
+	// 2: General Ledger account
+	// 3: Savings account
+	// 4: Money Market account
+	// 5: Other account
+	AccountTypeCode string `json:"accountTypeCode"`
+	// SourceWorkCode is a code used to identify the source of the work associated with this Credit.
+	// See CreditItem.SourceWorkCode for values.
+	SourceWorkCode string `json:"sourceWorkCode"`
+	// WorkType is a code that identifies the type of work, defined by the clearing arrangement.
+	WorkType string `json:"workType"`
+	// DebitCreditIndicator is a code that identifies whether this record represents a debit or a credit,
+	// defined by the clearing arrangement.
+	DebitCreditIndicator string `json:"debitCreditIndicator"`
+	// reserved is a field reserved for future use.  Reserved should be blank.
+	reserved string
+	// validator is composed for imagecashletter data validation
+	validator
+	// converters is composed for imagecashletter to golang Converters
+	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
+}
+
+// NewCredit returns a new Credit with default values for non exported fields
+func NewCredit() *Credit {
+	cr := &Credit{}
+	cr.setRecordType()
+	return cr
+}
+
+func (cr *Credit) setRecordType() {
+	if cr == nil {
+		return
+	}
+	cr.recordType = "61"
+	cr.reserved = "  "
+}
+
+// Parse takes the input record string and parses the Credit values
+func (cr *Credit) Parse(record string) {
+	if utf8.RuneCountInString(record) < 78 {
+		return // line too short
+	}
+	// Character position 1-2, Always "61"
+	cr.setRecordType()
+	// 03-17
+	cr.AuxiliaryOnUs = cr.parseStringField(record[2:17])
+	// 18-18
+	cr.ExternalProcessingCode = cr.parseStringField(record[17:18])
+	// 19-27
+	cr.PayorBankRoutingNumber = cr.parseStringField(record[18:27])
+	// 28-47
+	cr.CreditAccountNumberOnUs = cr.parseStringField(record[27:47])
+	// 48-57
+	cr.ItemAmount = cr.parseNumField(record[47:57])
+	// 58-72
+	cr.ECEInstitutionItemSequenceNumber = cr.parseStringField(record[57:72])
+	// 73-73
+	cr.DocumentationTypeIndicator = cr.parseStringField(record[72:73])
+	// 74-74
+	cr.AccountTypeCode = cr.parseStringField(record[73:74])
+	// 75-76
+	cr.SourceWorkCode = cr.parseStringField(record[74:76])
+	// 77-77
+	cr.WorkType = cr.parseStringField(record[76:77])
+	// 78-78
+	cr.DebitCreditIndicator = cr.parseStringField(record[77:78])
+	// 79-80
+	cr.reserved = "  "
+}
+
+func (cr *Credit) UnmarshalJSON(data []byte) error {
+	type Alias Credit
+	aux := struct {
+		*Alias
+	}{
+		(*Alias)(cr),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	cr.setRecordType()
+	return nil
+}
+
+// String writes the Credit struct to a string.
+func (cr *Credit) String() string {
+	var buf strings.Builder
+	buf.Grow(80)
+	buf.WriteString(cr.recordType)
+	buf.WriteString(cr.AuxiliaryOnUsField())
+	buf.WriteString(cr.ExternalProcessingCodeField())
+	buf.WriteString(cr.PayorBankRoutingNumberField())
+	buf.WriteString(cr.CreditAccountNumberOnUsField())
+	buf.WriteString(cr.ItemAmountField())
+	buf.WriteString(cr.ECEInstitutionItemSequenceNumberField())
+	buf.WriteString(cr.DocumentationTypeIndicatorField())
+	buf.WriteString(cr.AccountTypeCodeField())
+	buf.WriteString(cr.SourceWorkCodeField())
+	buf.WriteString(cr.WorkTypeField())
+	buf.WriteString(cr.DebitCreditIndicatorField())
+	buf.WriteString(cr.reservedField())
+	return buf.String()
+}
+
+// Validate performs imagecashletter format rule checks on the record and returns an error if not Validated
+// The first error encountered is returned and stops the parsing.
+func (cr *Credit) Validate() error {
+	if err := cr.fieldInclusion(); err != nil {
+		return err
+	}
+	if cr.recordType != "61" {
+		msg := fmt.Sprintf(msgRecordType, 61)
+		return &FieldError{FieldName: "recordType", Value: cr.recordType, Msg: msg}
+	}
+	if cr.DocumentationTypeIndicator != "" {
+		// Z is valid for CashLetter DocumentationTypeIndicator only
+		if cr.DocumentationTypeIndicator == "Z" {
+			msg := fmt.Sprint(msgDocumentationTypeIndicator)
+			return &FieldError{FieldName: "DocumentationTypeIndicator", Value: cr.DocumentationTypeIndicator, Msg: msg}
+		}
+		if err := cr.isDocumentationTypeIndicator(cr.DocumentationTypeIndicator); err != nil {
+			return &FieldError{FieldName: "DocumentationTypeIndicator", Value: cr.DocumentationTypeIndicator, Msg: err.Error()}
+		}
+	}
+	if cr.AccountTypeCode != "" {
+		if err := cr.isAccountTypeCode(cr.AccountTypeCode); err != nil {
+			return &FieldError{FieldName: "AccountTypeCode", Value: cr.AccountTypeCode, Msg: err.Error()}
+		}
+	}
+	if cr.SourceWorkCode != "" {
+		if err := cr.isSourceWorkCode(cr.SourceWorkCode); err != nil {
+			return &FieldError{FieldName: "SourceWorkCode", Value: cr.SourceWorkCode, Msg: err.Error()}
+		}
+	}
+	if err := cr.isAlphanumeric(cr.WorkType); err != nil {
+		return &FieldError{FieldName: "WorkType", Value: cr.WorkType, Msg: err.Error()}
+	}
+	if err := cr.isAlphanumeric(cr.DebitCreditIndicator); err != nil {
+		return &FieldError{FieldName: "DebitCreditIndicator", Value: cr.DebitCreditIndicator, Msg: err.Error()}
+	}
+	return nil
+}
+
+// fieldInclusion validate mandatory fields are not default values. If fields are
+// invalid the Electronic Exchange will be returned.
+func (cr *Credit) fieldInclusion() error {
+	if cr.recordType == "" {
+		return &FieldError{FieldName: "recordType",
+			Value: cr.recordType,
+			Msg:   msgFieldInclusion + ", did you use Credit()?"}
+	}
+	if cr.PayorBankRoutingNumber == "" {
+		return &FieldError{FieldName: "PayorBankRoutingNumber",
+			Value: cr.PayorBankRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use Credit()?"}
+	}
+	if cr.PayorBankRoutingNumberField() == "000000000" {
+		return &FieldError{FieldName: "PayorBankRoutingNumber",
+			Value: cr.PayorBankRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use Credit()?"}
+	}
+	if cr.CreditAccountNumberOnUs == "" {
+		return &FieldError{FieldName: "CreditAccountNumberOnUs",
+			Value: cr.CreditAccountNumberOnUs,
+			Msg:   msgFieldInclusion + ", did you use Credit()?"}
+	}
+	if cr.ECEInstitutionItemSequenceNumberField() == "               " {
+		return &FieldError{FieldName: "ECEInstitutionItemSequenceNumber",
+			Value: cr.ECEInstitutionItemSequenceNumber,
+			Msg:   msgFieldInclusion + ", did you use Credit()?"}
+	}
+	return nil
+}
+
+// AuxiliaryOnUsField gets the AuxiliaryOnUs field
+func (cr *Credit) AuxiliaryOnUsField() string {
+	return cr.nbsmField(cr.AuxiliaryOnUs, 15)
+}
+
+// ExternalProcessingCodeField gets the ExternalProcessingCode field
+func (cr *Credit) ExternalProcessingCodeField() string {
+	return cr.alphaField(cr.ExternalProcessingCode, 1)
+}
+
+// PayorBankRoutingNumberField gets the PayorBankRoutingNumber field
+func (cr *Credit) PayorBankRoutingNumberField() string {
+	return cr.stringField(cr.PayorBankRoutingNumber, 9)
+}
+
+// CreditAccountNumberOnUsField gets the CreditAccountNumberOnUs field
+func (cr *Credit) CreditAccountNumberOnUsField() string {
+	return cr.nbsmField(cr.CreditAccountNumberOnUs, 20)
+}
+
+// ItemAmountField gets the ItemAmount field
+func (cr *Credit) ItemAmountField() string {
+	return cr.numericField(cr.ItemAmount, 10)
+}
+
+// ECEInstitutionItemSequenceNumberField gets the ECEInstitutionItemSequenceNumber field
+func (cr *Credit) ECEInstitutionItemSequenceNumberField() string {
+	return cr.alphaField(cr.ECEInstitutionItemSequenceNumber, 15)
+}
+
+// DocumentationTypeIndicatorField gets the DocumentationTypeIndicator field
+func (cr *Credit) DocumentationTypeIndicatorField() string {
+	return cr.alphaField(cr.DocumentationTypeIndicator, 1)
+}
+
+// AccountTypeCodeField gets the AccountTypeCode field
+func (cr *Credit) AccountTypeCodeField() string {
+	return cr.alphaField(cr.AccountTypeCode, 1)
+}
+
+// SourceWorkCodeField gets the SourceWorkCode field
+func (cr *Credit) SourceWorkCodeField() string {
+	return cr.alphaField(cr.SourceWorkCode, 2)
+}
+
+// WorkTypeField gets the WorkType field
+func (cr *Credit) WorkTypeField() string {
+	return cr.alphaField(cr.WorkType, 1)
+}
+
+// DebitCreditIndicatorField gets the DebitCreditIndicator field
+func (cr *Credit) DebitCreditIndicatorField() string {
+	return cr.alphaField(cr.DebitCreditIndicator, 1)
+}
+
+// reservedField gets reserved - blank space
+func (cr *Credit) reservedField() string {
+	return cr.alphaField(cr.reserved, 2)
+}
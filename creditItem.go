@@ -114,6 +114,8 @@ type CreditItem struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewCreditItem returns a new CreditItem with default values for non exported fields
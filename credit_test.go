@@ -0,0 +1,232 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"strings"
+	"testing"
+)
+
+// mockCredit creates a Credit
+func mockCredit() *Credit {
+	cr := NewCredit()
+	cr.AuxiliaryOnUs = "123456789"
+	cr.ExternalProcessingCode = ""
+	cr.PayorBankRoutingNumber = "031300012"
+	cr.CreditAccountNumberOnUs = "5558881"
+	cr.ItemAmount = 100000 // 1000.00
+	cr.ECEInstitutionItemSequenceNumber = "1"
+	cr.DocumentationTypeIndicator = "G"
+	cr.AccountTypeCode = "1"
+	cr.SourceWorkCode = "01"
+	cr.WorkType = "A"
+	cr.DebitCreditIndicator = "2"
+	return cr
+}
+
+// TestMockCredit creates a Credit
+func TestMockCredit(t *testing.T) {
+	v := mockCredit()
+	if err := v.Validate(); err != nil {
+		t.Error("mockCredit does not validate and will break other tests: ", err)
+	}
+}
+
+func TestCreditParseErr(t *testing.T) {
+	var v Credit
+	v.Parse("asdlahsakjajf")
+	if v.PayorBankRoutingNumber != "" {
+		t.Errorf("v.PayorBankRoutingNumber=%s", v.PayorBankRoutingNumber)
+	}
+}
+
+// TestParseCredit validates parsing a Credit
+func TestParseCredit(t *testing.T) {
+	var line = "61      123456789 031300012             555888100001000001              G101A2  "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	clh := mockCashLetterHeader()
+	r.addCurrentCashLetter(NewCashLetter(clh))
+	r.addCurrentBundle(NewBundle(mockBundleHeader()))
+	if err := r.parseCredit(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	record := r.currentCashLetter.currentBundle.GetCredits()[0]
+
+	if record.recordType != "61" {
+		t.Errorf("RecordType Expected '61' got: %v", record.recordType)
+	}
+	if record.AuxiliaryOnUs != "123456789" {
+		t.Errorf("AuxiliaryOnUs Expected '123456789' got: %v", record.AuxiliaryOnUs)
+	}
+	if record.PayorBankRoutingNumber != "031300012" {
+		t.Errorf("PayorBankRoutingNumber Expected '031300012' got: %v", record.PayorBankRoutingNumber)
+	}
+	if record.CreditAccountNumberOnUs != "5558881" {
+		t.Errorf("CreditAccountNumberOnUs Expected '5558881' got: %v", record.CreditAccountNumberOnUs)
+	}
+	if record.ItemAmount != 100000 {
+		t.Errorf("ItemAmount Expected '100000' got: %v", record.ItemAmount)
+	}
+	if record.ECEInstitutionItemSequenceNumber != "1" {
+		t.Errorf("ECEInstitutionItemSequenceNumber Expected '1' got: %v", record.ECEInstitutionItemSequenceNumber)
+	}
+	if record.DocumentationTypeIndicator != "G" {
+		t.Errorf("DocumentationTypeIndicator Expected 'G' got: %v", record.DocumentationTypeIndicator)
+	}
+	if record.AccountTypeCode != "1" {
+		t.Errorf("AccountTypeCode Expected '1' got: %v", record.AccountTypeCode)
+	}
+	if record.SourceWorkCode != "01" {
+		t.Errorf("SourceWorkCode Expected '01' got: %v", record.SourceWorkCode)
+	}
+	if record.WorkType != "A" {
+		t.Errorf("WorkType Expected 'A' got: %v", record.WorkType)
+	}
+	if record.DebitCreditIndicator != "2" {
+		t.Errorf("DebitCreditIndicator Expected '2' got: %v", record.DebitCreditIndicator)
+	}
+
+	if record.String() != line {
+		t.Errorf("Strings do not match")
+	}
+}
+
+// TestCreditParseOutsideBundle validation
+func TestCreditParseOutsideBundle(t *testing.T) {
+	var line = "61      123456789 031300012             555888100001000001              G101A2  "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	r.addCurrentCashLetter(NewCashLetter(mockCashLetterHeader()))
+	if err := r.parseCredit(); err != nil {
+		if !strings.Contains(err.Error(), msgFileBundleOutside) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestCRRecordType validation
+func TestCRRecordType(t *testing.T) {
+	v := mockCredit()
+	v.recordType = "00"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRDocumentationTypeIndicatorZ validation
+func TestCRDocumentationTypeIndicatorZ(t *testing.T) {
+	v := mockCredit()
+	v.DocumentationTypeIndicator = "Z"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DocumentationTypeIndicator" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRDocumentationTypeIndicator validation
+func TestCRDocumentationTypeIndicator(t *testing.T) {
+	v := mockCredit()
+	v.DocumentationTypeIndicator = "P"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DocumentationTypeIndicator" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRAccountTypeCode validation
+func TestCRAccountTypeCode(t *testing.T) {
+	v := mockCredit()
+	v.AccountTypeCode = "Z"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "AccountTypeCode" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRSourceWorkCode validation
+func TestCRSourceWorkCode(t *testing.T) {
+	v := mockCredit()
+	v.SourceWorkCode = "99"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "SourceWorkCode" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRWorkType validation
+func TestCRWorkType(t *testing.T) {
+	v := mockCredit()
+	v.WorkType = "*"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "WorkType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRDebitCreditIndicator validation
+func TestCRDebitCreditIndicator(t *testing.T) {
+	v := mockCredit()
+	v.DebitCreditIndicator = "*"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DebitCreditIndicator" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// Field Inclusion
+
+// TestCRFIRecordType validation
+func TestCRFIRecordType(t *testing.T) {
+	v := mockCredit()
+	v.recordType = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRFIPayorBankRoutingNumber validation
+func TestCRFIPayorBankRoutingNumber(t *testing.T) {
+	v := mockCredit()
+	v.PayorBankRoutingNumber = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "PayorBankRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRFIPayorBankRoutingNumberZero validation
+func TestCRFIPayorBankRoutingNumberZero(t *testing.T) {
+	v := mockCredit()
+	v.PayorBankRoutingNumber = "000000000"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "PayorBankRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRFICreditAccountNumberOnUs validation
+func TestCRFICreditAccountNumberOnUs(t *testing.T) {
+	v := mockCredit()
+	v.CreditAccountNumberOnUs = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "CreditAccountNumberOnUs" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestCRFIECEInstitutionItemSequenceNumber validation
+func TestCRFIECEInstitutionItemSequenceNumber(t *testing.T) {
+	v := mockCredit()
+	v.ECEInstitutionItemSequenceNumber = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "ECEInstitutionItemSequenceNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
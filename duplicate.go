@@ -0,0 +1,405 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ItemFingerprint identifies a check for duplicate detection, from its MICR line, amount and optionally its images
+type ItemFingerprint struct {
+	// RoutingNumber is the PayorBankRoutingNumber and PayorBankCheckDigit of the check
+	RoutingNumber string `json:"routingNumber"`
+	// AccountNumber is the account number of the On-Us field, without dashes
+	AccountNumber string `json:"accountNumber"`
+	// SerialNumber is the check number of the MICR line, without leading zeros
+	SerialNumber string `json:"serialNumber"`
+	// Amount is the ItemAmount of the check
+	Amount int `json:"amount"`
+	// ImageHash is the hex SHA-256 hash of the image data of the check's ImageViewData, with DuplicateImageHashOption
+	ImageHash string `json:"imageHash,omitempty"`
+}
+
+// NewItemFingerprint returns the ItemFingerprint of a CheckDetail, with the hash of its image data when imageHash
+// is true. The account and serial number are from the MICR fields of the check, see ParseMICR, or its raw OnUs and
+// AuxiliaryOnUs when they can't be parsed.
+func NewItemFingerprint(cd *CheckDetail, imageHash bool) ItemFingerprint {
+	fp := ItemFingerprint{
+		RoutingNumber: payorBankRoutingNumber(cd.PayorBankRoutingNumber, cd.PayorBankCheckDigit),
+		Amount:        cd.ItemAmount,
+	}
+	if m, err := cd.MICR(); err == nil {
+		fp.AccountNumber, fp.SerialNumber = m.AccountNumber, m.CheckNumber()
+	} else {
+		fp.AccountNumber, fp.SerialNumber = micrValue(cd.OnUs), micrValue(cd.AuxiliaryOnUs)
+	}
+	fp.AccountNumber = strings.Replace(fp.AccountNumber, "-", "", -1)
+	fp.SerialNumber = strings.TrimLeft(fp.SerialNumber, "0")
+	if imageHash && len(cd.ImageViewData) > 0 {
+		h := sha256.New()
+		for i := range cd.ImageViewData {
+			h.Write(imageViewBytes(&cd.ImageViewData[i]))
+		}
+		fp.ImageHash = hex.EncodeToString(h.Sum(nil))
+	}
+	return fp
+}
+
+// String returns the ItemFingerprint as a key, e.g. for a DuplicateStore
+func (fp ItemFingerprint) String() string {
+	return fmt.Sprintf("%s|%s|%s|%d|%s", fp.RoutingNumber, fp.AccountNumber, fp.SerialNumber, fp.Amount, fp.ImageHash)
+}
+
+// ItemPresentment is a check presented in a File
+type ItemPresentment struct {
+	// Fingerprint identifies the check
+	Fingerprint ItemFingerprint `json:"fingerprint"`
+	// FileID is the ID of the File
+	FileID string `json:"fileID,omitempty"`
+	// CashLetterID is the CashLetterID of the check's cash letter
+	CashLetterID string `json:"cashLetterID"`
+	// BundleSequenceNumber is the BundleSequenceNumber of the check's bundle
+	BundleSequenceNumber string `json:"bundleSequenceNumber"`
+	// ItemSequenceNumber is the EceInstitutionItemSequenceNumber of the check
+	ItemSequenceNumber string `json:"itemSequenceNumber"`
+	// Path is the path of the check in the File, e.g. /cashLetters/0/bundles/0/checks/0
+	Path string `json:"path"`
+	// Presented is the CashLetterBusinessDate of the check's cash letter, or the FileCreationDate when it's not set
+	Presented time.Time `json:"presented"`
+}
+
+// Duplicate is a check presented more than once
+type Duplicate struct {
+	// Item is the duplicate check
+	Item ItemPresentment `json:"item"`
+	// Original is the earliest presentment of the check
+	Original ItemPresentment `json:"original"`
+	// InFile is true when the Original is an earlier check of the same File, and false when it's from the
+	// DuplicateStore
+	InFile bool `json:"inFile"`
+}
+
+func (d *Duplicate) Error() string {
+	if d.InFile {
+		return fmt.Sprintf("%s is a duplicate of %s", d.Item.Path, d.Original.Path)
+	}
+	return fmt.Sprintf("%s is a duplicate of item %s of cash letter %s presented %s", d.Item.Path,
+		d.Original.ItemSequenceNumber, d.Original.CashLetterID, d.Original.Presented.Format("2006-01-02"))
+}
+
+// DuplicateStore records the presentments of checks across Files, see MemoryDuplicateStore and
+// FileDuplicateStore
+type DuplicateStore interface {
+	// Presentments returns the recorded presentments of a check presented on or after since, earliest first
+	Presentments(fingerprint ItemFingerprint, since time.Time) ([]ItemPresentment, error)
+	// Record records the presentments of checks
+	Record(presentments []ItemPresentment) error
+}
+
+// DuplicateDetectorOption is an option for a DuplicateDetector
+type DuplicateDetectorOption func(*DuplicateDetector)
+
+// DuplicateStoreOption sets the DuplicateStore which checks are compared with across Files.
+func DuplicateStoreOption(store DuplicateStore) DuplicateDetectorOption {
+	return func(d *DuplicateDetector) {
+		d.Store = store
+	}
+}
+
+// DuplicateLookBackOption sets how long before the presentment of a check the DuplicateStore is searched.
+func DuplicateLookBackOption(window time.Duration) DuplicateDetectorOption {
+	return func(d *DuplicateDetector) {
+		d.LookBack = window
+	}
+}
+
+// DuplicateImageHashOption adds the hash of a check's image data to its ItemFingerprint, so only checks with the
+// same images are duplicates.
+func DuplicateImageHashOption() DuplicateDetectorOption {
+	return func(d *DuplicateDetector) {
+		d.ImageHash = true
+	}
+}
+
+// DuplicateDetector finds checks presented more than once: within a File, and against the presentments of a
+// DuplicateStore. Checks are compared by ItemFingerprint, and checks without an account number are not compared.
+type DuplicateDetector struct {
+	// Store records presentments across Files, and is nil to only find duplicates within a File.
+	Store DuplicateStore
+	// LookBack is how long before the presentment of a check the Store is searched, and 0 to search all of it.
+	LookBack time.Duration
+	// ImageHash adds the hash of a check's image data to its ItemFingerprint.
+	ImageHash bool
+}
+
+// NewDuplicateDetector returns a DuplicateDetector with the options
+func NewDuplicateDetector(opts ...DuplicateDetectorOption) *DuplicateDetector {
+	d := &DuplicateDetector{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Presentments returns the ItemPresentment of every check of the File which has an account number
+func (d *DuplicateDetector) Presentments(f *File) []ItemPresentment {
+	var presentments []ItemPresentment
+	for i, cl := range f.CashLetters {
+		presented := f.Header.FileCreationDate
+		var cashLetterID string
+		if cl.CashLetterHeader != nil {
+			cashLetterID = cl.CashLetterHeader.CashLetterID
+			if !cl.CashLetterHeader.CashLetterBusinessDate.IsZero() {
+				presented = cl.CashLetterHeader.CashLetterBusinessDate
+			}
+		}
+		for j, b := range cl.Bundles {
+			if b == nil {
+				continue
+			}
+			var bundleSequenceNumber string
+			if b.BundleHeader != nil {
+				bundleSequenceNumber = b.BundleHeader.BundleSequenceNumber
+			}
+			for k, cd := range b.Checks {
+				if cd == nil {
+					continue
+				}
+				fp := NewItemFingerprint(cd, d.ImageHash)
+				if fp.AccountNumber == "" {
+					continue
+				}
+				presentments = append(presentments, ItemPresentment{
+					Fingerprint:          fp,
+					FileID:               f.ID,
+					CashLetterID:         cashLetterID,
+					BundleSequenceNumber: bundleSequenceNumber,
+					ItemSequenceNumber:   cd.EceInstitutionItemSequenceNumber,
+					Path:                 fmt.Sprintf("/cashLetters/%d/bundles/%d/checks/%d", i, j, k),
+					Presented:            presented,
+				})
+			}
+		}
+	}
+	return presentments
+}
+
+// Detect returns a Duplicate for each check of the File which is a duplicate of an earlier check of the File, or
+// of a presentment of the Store within LookBack. Detect doesn't record the checks of the File, see Record.
+func (d *DuplicateDetector) Detect(f *File) ([]Duplicate, error) {
+	if f == nil {
+		return nil, ErrNilFile
+	}
+	var duplicates []Duplicate
+	seen := make(map[string]ItemPresentment)
+	for _, p := range d.Presentments(f) {
+		key := p.Fingerprint.String()
+		if original, ok := seen[key]; ok {
+			duplicates = append(duplicates, Duplicate{Item: p, Original: original, InFile: true})
+			continue
+		}
+		seen[key] = p
+		if d.Store == nil {
+			continue
+		}
+		var since time.Time
+		if d.LookBack > 0 {
+			since = p.Presented.Add(-d.LookBack)
+		}
+		originals, err := d.Store.Presentments(p.Fingerprint, since)
+		if err != nil {
+			return nil, err
+		}
+		if len(originals) > 0 {
+			duplicates = append(duplicates, Duplicate{Item: p, Original: originals[0]})
+		}
+	}
+	return duplicates, nil
+}
+
+// Record records the checks of the File in the Store, so later Files are compared with them.
+func (d *DuplicateDetector) Record(f *File) error {
+	if f == nil {
+		return ErrNilFile
+	}
+	if d.Store == nil {
+		return nil
+	}
+	return d.Store.Record(d.Presentments(f))
+}
+
+// MemoryDuplicateStore is a DuplicateStore in memory
+type MemoryDuplicateStore struct {
+	mu           sync.RWMutex
+	presentments map[string][]ItemPresentment
+}
+
+// NewMemoryDuplicateStore returns an empty MemoryDuplicateStore
+func NewMemoryDuplicateStore() *MemoryDuplicateStore {
+	return &MemoryDuplicateStore{presentments: make(map[string][]ItemPresentment)}
+}
+
+// Presentments returns the recorded presentments of a check presented on or after since, earliest first
+func (s *MemoryDuplicateStore) Presentments(fingerprint ItemFingerprint, since time.Time) ([]ItemPresentment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var presentments []ItemPresentment
+	for _, p := range s.presentments[fingerprint.String()] {
+		if !p.Presented.Before(since) {
+			presentments = append(presentments, p)
+		}
+	}
+	return presentments, nil
+}
+
+// Record records the presentments of checks
+func (s *MemoryDuplicateStore) Record(presentments []ItemPresentment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recorded := make(map[string]bool)
+	for _, p := range presentments {
+		key := p.Fingerprint.String()
+		s.presentments[key] = append(s.presentments[key], p)
+		recorded[key] = true
+	}
+	for key := range recorded {
+		presentments := s.presentments[key]
+		sort.SliceStable(presentments, func(i, j int) bool {
+			return presentments[i].Presented.Before(presentments[j].Presented)
+		})
+	}
+	return nil
+}
+
+// Prune removes the presentments of checks presented before a time, e.g. outside of the look-back window
+func (s *MemoryDuplicateStore) Prune(before time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, presentments := range s.presentments {
+		kept := presentments[:0]
+		for _, p := range presentments {
+			if !p.Presented.Before(before) {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.presentments, key)
+		} else {
+			s.presentments[key] = kept
+		}
+	}
+}
+
+// all returns every presentment of the MemoryDuplicateStore
+func (s *MemoryDuplicateStore) all() []ItemPresentment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var presentments []ItemPresentment
+	for _, p := range s.presentments {
+		presentments = append(presentments, p...)
+	}
+	return presentments
+}
+
+// FileDuplicateStore is a DuplicateStore in a file of JSON lines, one ItemPresentment per line, which is read into
+// memory when it's opened and appended to by Record.
+type FileDuplicateStore struct {
+	path   string
+	mu     sync.Mutex
+	memory *MemoryDuplicateStore
+}
+
+// OpenFileDuplicateStore opens the FileDuplicateStore at path, creating it when it doesn't exist.
+func OpenFileDuplicateStore(path string) (*FileDuplicateStore, error) {
+	s := &FileDuplicateStore{path: path, memory: NewMemoryDuplicateStore()}
+	fd, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	var presentments []ItemPresentment
+	scanner := bufio.NewScanner(fd)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var p ItemPresentment
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			return nil, fmt.Errorf("problem reading duplicate store %s line %d: %v", path, line, err)
+		}
+		presentments = append(presentments, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.memory.Record(presentments); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Presentments returns the recorded presentments of a check presented on or after since, earliest first
+func (s *FileDuplicateStore) Presentments(fingerprint ItemFingerprint, since time.Time) ([]ItemPresentment, error) {
+	return s.memory.Presentments(fingerprint, since)
+}
+
+// Record appends the presentments of checks to the file
+func (s *FileDuplicateStore) Record(presentments []ItemPresentment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fd, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if err := writePresentments(fd, presentments); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	return s.memory.Record(presentments)
+}
+
+// Prune removes the presentments of checks presented before a time, rewriting the file
+func (s *FileDuplicateStore) Prune(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory.Prune(before)
+	tmp := s.path + ".tmp"
+	fd, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := writePresentments(fd, s.memory.all()); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// writePresentments writes presentments as JSON lines
+func writePresentments(fd *os.File, presentments []ItemPresentment) error {
+	w := bufio.NewWriter(fd)
+	enc := json.NewEncoder(w)
+	for i := range presentments {
+		if err := enc.Encode(&presentments[i]); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
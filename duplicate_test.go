@@ -0,0 +1,166 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// mockDuplicateFile returns a File of one cash letter with a business date and a bundle of the checks
+func mockDuplicateFile(businessDate time.Time, checks ...*CheckDetail) *File {
+	clh := mockCashLetterHeader()
+	clh.CashLetterBusinessDate = businessDate
+	bundle := NewBundle(mockBundleHeader())
+	for _, cd := range checks {
+		bundle.AddCheckDetail(cd)
+	}
+	file := NewFile().SetHeader(mockFileHeader())
+	file.CashLetters = []CashLetter{{CashLetterHeader: clh, Bundles: []*Bundle{bundle}}}
+	return file
+}
+
+// mockDuplicateCheck returns a CheckDetail with MICR fields and an amount
+func mockDuplicateCheck(auxiliaryOnUs, onUs string, amount int) *CheckDetail {
+	cd := mockCheckDetail()
+	cd.AuxiliaryOnUs, cd.OnUs, cd.ItemAmount = auxiliaryOnUs, onUs, amount
+	return cd
+}
+
+// TestNewItemFingerprint validates checks are fingerprinted from their MICR fields and amount
+func TestNewItemFingerprint(t *testing.T) {
+	fp := NewItemFingerprint(mockDuplicateCheck("", "12-3456 789/001001", 2500), false)
+	expected := ItemFingerprint{RoutingNumber: "031300012", AccountNumber: "123456789", SerialNumber: "1001", Amount: 2500}
+	if fp != expected {
+		t.Errorf("unexpected ItemFingerprint: %#v", fp)
+	}
+	// the same check with its serial number in the Auxiliary On-Us field
+	if other := NewItemFingerprint(mockDuplicateCheck("1001", "123456789/", 2500), false); other != fp {
+		t.Errorf("unexpected ItemFingerprint: %#v", other)
+	}
+
+	cd := mockDuplicateCheck("", "123456789/1001", 2500)
+	cd.ImageViewData = []ImageViewData{{ImageData: []byte("*FRONT*")}, {ImageData: []byte("*BACK*")}}
+	withImage := NewItemFingerprint(cd, true)
+	if withImage.ImageHash == "" || withImage.String() == fp.String() {
+		t.Errorf("unexpected ItemFingerprint: %#v", withImage)
+	}
+	cd.ImageViewData[1].ImageData = []byte("*OTHER BACK*")
+	if other := NewItemFingerprint(cd, true); other.ImageHash == withImage.ImageHash {
+		t.Errorf("unexpected ItemFingerprint: %#v", other)
+	}
+}
+
+// TestDuplicateDetector__File validates duplicate checks within a File are detected
+func TestDuplicateDetector__File(t *testing.T) {
+	businessDate := time.Date(2020, time.March, 2, 0, 0, 0, 0, time.UTC)
+	file := mockDuplicateFile(businessDate,
+		mockDuplicateCheck("", "123456789/1001", 2500),
+		mockDuplicateCheck("", "123456789/1002", 2500),
+		mockDuplicateCheck("1001", "123456789/", 2500),
+		mockDuplicateCheck("", "123456789/1001", 2501),
+		mockDuplicateCheck("", "", 2500),
+		mockDuplicateCheck("", "", 2500),
+	)
+	duplicates, err := NewDuplicateDetector().Detect(file)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("unexpected Duplicates: %v", duplicates)
+	}
+	d := duplicates[0]
+	if d.Item.Path != "/cashLetters/0/bundles/0/checks/2" || d.Original.Path != "/cashLetters/0/bundles/0/checks/0" ||
+		!d.InFile || !d.Item.Presented.Equal(businessDate) {
+		t.Errorf("unexpected Duplicate: %#v", d)
+	}
+	if d.Error() != "/cashLetters/0/bundles/0/checks/2 is a duplicate of /cashLetters/0/bundles/0/checks/0" {
+		t.Error(d.Error())
+	}
+	if _, err := NewDuplicateDetector().Detect(nil); err != ErrNilFile {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestDuplicateDetector__Store validates duplicate checks across Files are detected within the look-back window
+func TestDuplicateDetector__Store(t *testing.T) {
+	for name, open := range map[string]func(t *testing.T) DuplicateStore{
+		"memory": func(t *testing.T) DuplicateStore { return NewMemoryDuplicateStore() },
+		"file": func(t *testing.T) DuplicateStore {
+			store, err := OpenFileDuplicateStore(filepath.Join(t.TempDir(), "presentments.jsonl"))
+			if err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			return store
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			detector := NewDuplicateDetector(DuplicateStoreOption(store), DuplicateLookBackOption(30*24*time.Hour))
+			monday := time.Date(2020, time.March, 2, 0, 0, 0, 0, time.UTC)
+			first := mockDuplicateFile(monday, mockDuplicateCheck("", "123456789/1001", 2500))
+			first.ID = "first"
+			if duplicates, err := detector.Detect(first); err != nil || len(duplicates) != 0 {
+				t.Fatalf("unexpected Duplicates: %v %v", duplicates, err)
+			}
+			if err := detector.Record(first); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+
+			// the check is presented again the next day, and a month and a half later
+			second := mockDuplicateFile(monday.AddDate(0, 0, 1), mockDuplicateCheck("", "123456789/1001", 2500),
+				mockDuplicateCheck("", "123456789/1002", 2500))
+			duplicates, err := detector.Detect(second)
+			if err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			if len(duplicates) != 1 || duplicates[0].InFile || duplicates[0].Original.FileID != "first" ||
+				duplicates[0].Item.Path != "/cashLetters/0/bundles/0/checks/0" || !duplicates[0].Original.Presented.Equal(monday) {
+				t.Fatalf("unexpected Duplicates: %v", duplicates)
+			}
+			later := mockDuplicateFile(monday.AddDate(0, 0, 45), mockDuplicateCheck("", "123456789/1001", 2500))
+			if duplicates, err := detector.Detect(later); err != nil || len(duplicates) != 0 {
+				t.Errorf("unexpected Duplicates: %v %v", duplicates, err)
+			}
+			if duplicates, err := NewDuplicateDetector(DuplicateStoreOption(store)).Detect(later); err != nil || len(duplicates) != 1 {
+				t.Errorf("unexpected Duplicates: %v %v", duplicates, err)
+			}
+
+			// file stores are read again when opened
+			if s, ok := store.(*FileDuplicateStore); ok {
+				reopened, err := OpenFileDuplicateStore(s.path)
+				if err != nil {
+					t.Fatalf("%T: %s", err, err)
+				}
+				if duplicates, err := NewDuplicateDetector(DuplicateStoreOption(reopened)).Detect(second); err != nil || len(duplicates) != 1 {
+					t.Errorf("unexpected Duplicates: %v %v", duplicates, err)
+				}
+				if err := reopened.Prune(monday.AddDate(0, 0, 1)); err != nil {
+					t.Fatalf("%T: %s", err, err)
+				}
+				reopened, err = OpenFileDuplicateStore(s.path)
+				if err != nil {
+					t.Fatalf("%T: %s", err, err)
+				}
+				if duplicates, err := NewDuplicateDetector(DuplicateStoreOption(reopened)).Detect(second); err != nil || len(duplicates) != 0 {
+					t.Errorf("unexpected Duplicates: %v %v", duplicates, err)
+				}
+			}
+		})
+	}
+}
+
+// TestOpenFileDuplicateStore__Invalid validates a FileDuplicateStore which isn't JSON lines can't be opened
+func TestOpenFileDuplicateStore__Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presentments.jsonl")
+	if err := os.WriteFile(path, []byte("{}\nnot json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileDuplicateStore(path); err == nil {
+		t.Error("expected error")
+	}
+}
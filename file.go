@@ -36,15 +36,16 @@ const (
 	imageViewDetailPos      = "50"
 	imageViewDataPos        = "52"
 	imageViewAnalysisPos    = "54"
+	creditPos               = "61"
+	accountTotalsDetailPos  = "40"
+	nonHitTotalsDetailPos   = "41"
 	creditItemPos           = "62"
+	userRecordPos           = "68"
 	bundleControlPos        = "70"
+	boxSummaryPos           = "75"
 	routingNumberSummaryPos = "85"
 	cashLetterControlPos    = "90"
 	fileControlPos          = "99"
-	// no longer supported by the standard
-	// accountTotalsDetailPos  = "40"
-	// nonHitTotalsDetailPos   = "41"
-	// boxSummaryPos           = "75"
 )
 
 // Record Types in EBCDIC
@@ -64,8 +65,13 @@ const (
 	imageViewDetailEbcPos      = "\xF5\xF0"
 	imageViewDataEbcPos        = "\xF5\xF2"
 	imageViewAnalysisEbcPos    = "\xF5\xF4"
+	creditEbcPos               = "\xF6\xF1"
+	accountTotalsDetailEbcPos  = "\xF4\xF0"
+	nonHitTotalsDetailEbcPos   = "\xF4\xF1"
 	creditItemEbcPos           = "\xF6\xF2"
+	userRecordEbcPos           = "\xF6\xF8"
 	bundleControlEbcPos        = "\xF7\xF0"
+	boxSummaryEbcPos           = "\xF7\xF5"
 	routingNumberSummaryEbcPos = "\xF8\xF5"
 	cashLetterControlEbcPos    = "\xF9\xF0"
 	fileControlEbcPos          = "\xF9\xF9"
@@ -76,8 +82,11 @@ var (
 	//msgFileCalculatedControlEquality = "calculated %v is out-of-balance with control %v"
 	// specific messages
 	msgRecordLength             = "Must be at least 80 characters and found %d"
+	msgRecordMaxLength          = "Must be at most %d bytes"
 	msgFileCashLetterInside     = "Inside of current cash letter"
 	msgFileCashLetterControl    = "Cash letter control without a current cash letter"
+	msgFileCashLetterNoControl  = "Cash letter without a cash letter control"
+	msgFileCashLetterOutside    = "Outside of current cash letter"
 	msgFileRoutingNumberSummary = "Routing Number Summary without a current cash letter"
 	msgFileBundleOutside        = "Outside of current bundle"
 	msgFileBundleInside         = "Inside of current bundle"
@@ -88,6 +97,10 @@ var (
 	msgFileCashLetterID         = "%s is not unique"
 	msgRecordType               = "received expecting %d"
 	msgFileCreditItem           = "Credit item outside of cash letter"
+	msgFileAccountTotalsDetail  = "Account totals detail outside of cash letter"
+	msgFileNonHitTotalsDetail   = "Non-hit totals detail outside of cash letter"
+	msgFileBoxSummary           = "Box summary outside of cash letter"
+	msgFileUserRecord           = "User record outside of cash letter"
 )
 
 // FileError is an error describing issues validating a file
@@ -221,6 +234,9 @@ func (f *File) Create() error {
 			fileTotalRecordCount = fileTotalRecordCount + len(cl.GetCreditItems())
 			creditIndicator = 1
 		}
+		fileTotalRecordCount = fileTotalRecordCount + len(cl.GetAccountTotalsDetail()) + len(cl.GetNonHitTotalsDetail())
+		fileTotalRecordCount = fileTotalRecordCount + len(cl.GetBoxSummary()) + len(cl.GetRoutingNumberSummary())
+		fileTotalRecordCount = fileTotalRecordCount + len(cl.GetUserRecords())
 
 		// Bundles
 		for _, b := range cl.Bundles {
@@ -231,6 +247,15 @@ func (f *File) Create() error {
 
 			// add 2 for each bundle header/control
 			fileTotalRecordCount = fileTotalRecordCount + 2
+			fileTotalRecordCount = fileTotalRecordCount + len(b.UserRecords)
+
+			// Credits
+			for _, cr := range b.Credits {
+				fileTotalItemCount = fileTotalItemCount + 1
+				fileTotalRecordCount = fileTotalRecordCount + 1
+				fileTotalAmount = fileTotalAmount + cr.ItemAmount
+				creditIndicator = 1
+			}
 
 			// Check Items
 			for _, cd := range b.Checks {
@@ -239,6 +264,7 @@ func (f *File) Create() error {
 				fileTotalRecordCount = fileTotalRecordCount + 1
 				fileTotalRecordCount = fileTotalRecordCount + len(cd.CheckDetailAddendumA) + len(cd.CheckDetailAddendumB) + len(cd.CheckDetailAddendumC)
 				fileTotalRecordCount = fileTotalRecordCount + len(cd.ImageViewDetail) + len(cd.ImageViewData) + len(cd.ImageViewAnalysis)
+				fileTotalRecordCount = fileTotalRecordCount + len(cd.UserRecords)
 
 				fileTotalAmount = fileTotalAmount + cd.ItemAmount
 			}
@@ -249,6 +275,7 @@ func (f *File) Create() error {
 				fileTotalRecordCount = fileTotalRecordCount + 1
 				fileTotalRecordCount = fileTotalRecordCount + len(rd.ReturnDetailAddendumA) + len(rd.ReturnDetailAddendumB) + len(rd.ReturnDetailAddendumC) + len(rd.ReturnDetailAddendumD)
 				fileTotalRecordCount = fileTotalRecordCount + len(rd.ImageViewDetail) + len(rd.ImageViewData) + len(rd.ImageViewAnalysis)
+				fileTotalRecordCount = fileTotalRecordCount + len(rd.UserRecords)
 
 				fileTotalAmount = fileTotalAmount + rd.ItemAmount
 			}
@@ -268,14 +295,22 @@ func (f *File) Create() error {
 	return nil
 }
 
-// Validate validates an ICL File
-func (f *File) Validate() error {
+// Validate validates an ICL File. With ValidateProfileOption, ValidateImagesOption, ValidateRoutingNumbersOption,
+// ValidateRoutingDirectoryOption or ValidateRoutingNumberSummaryOption every record of the File is validated, with
+// the ValidationProfile, inspecting image data, validating the check digits of routing numbers and looking them up
+// in the RoutingDirectory, and validating RoutingNumberSummary records against the CheckDetail, and every
+// Violation with SeverityError is returned in a base.ErrorList.
+func (f *File) Validate(opts ...ValidateOption) error {
 	if f == nil {
 		return ErrNilFile
 	}
 	if err := f.CashLetterIDUnique(); err != nil {
 		return err
 	}
+	if o := newValidateOptions(opts); o.profile != nil || o.images || o.routingNumbers || o.routingDirectory != nil ||
+		o.routingNumberSummary {
+		return f.ValidationReport(opts...).Err()
+	}
 	return nil
 }
 
@@ -43,6 +43,8 @@ type FileControl struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewFileControl returns a new FileControl with default values for non exported fields
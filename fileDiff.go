@@ -0,0 +1,347 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiffKind is how a record differs between two Files
+type DiffKind string
+
+const (
+	// DiffAdded records are only in the second File
+	DiffAdded DiffKind = "added"
+	// DiffRemoved records are only in the first File
+	DiffRemoved DiffKind = "removed"
+	// DiffChanged records are in both Files with different fields
+	DiffChanged DiffKind = "changed"
+)
+
+// FieldDiff is a field which differs between two records. Binary fields, such as ImageData, are compared by their
+// SHA-256 hash and length.
+type FieldDiff struct {
+	// FieldName is the name of the field, e.g. ItemAmount
+	FieldName string `json:"fieldName"`
+	// From is the value of the field in the first File
+	From string `json:"from"`
+	// To is the value of the field in the second File
+	To string `json:"to"`
+}
+
+// RecordDiff is a record added, removed or changed between two Files. Added and removed records include the records
+// they contain, e.g. the addenda and image views of a check.
+type RecordDiff struct {
+	// Kind is how the record differs
+	Kind DiffKind `json:"kind"`
+	// Path is the location of the record by key, e.g. /cashLetters/{CashLetterID}/bundles/{BundleSequenceNumber}/
+	// checks/{EceInstitutionItemSequenceNumber}/imageViewData/0. Records without a key are located by index.
+	Path string `json:"path"`
+	// RecordName is the name of the record, e.g. CheckDetail
+	RecordName string `json:"recordName"`
+	// Fields are the fields of a changed record which differ
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// FileDiff is every record which differs between two Files
+type FileDiff struct {
+	// Records are the records which differ, in the order of the Files
+	Records []RecordDiff `json:"records"`
+}
+
+// Equal returns true when no record differs between the Files
+func (d *FileDiff) Equal() bool {
+	return len(d.Records) == 0
+}
+
+// WriteText writes the FileDiff as text, a line for each record and an indented line for each changed field
+func (d *FileDiff) WriteText(w io.Writer) error {
+	for _, rec := range d.Records {
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", rec.Kind, rec.RecordName, rec.Path); err != nil {
+			return err
+		}
+		for _, field := range rec.Fields {
+			if _, err := fmt.Fprintf(w, "    %s: %q -> %q\n", field.FieldName, field.From, field.To); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DiffFiles compares two Files record by record and field by field. Cash letters are aligned by CashLetterID,
+// bundles by BundleSequenceNumber and checks and returns by EceInstitutionItemSequenceNumber, and the other records
+// of each by their position. The client defined ID of records is not compared.
+func DiffFiles(from, to *File) *FileDiff {
+	d := &FileDiff{Records: []RecordDiff{}}
+	if from == nil {
+		from = &File{}
+	}
+	if to == nil {
+		to = &File{}
+	}
+	d.record("/fileHeader", "FileHeader", &from.Header, &to.Header)
+	fromCashLetters, toCashLetters := make([]interface{}, len(from.CashLetters)), make([]interface{}, len(to.CashLetters))
+	for i := range from.CashLetters {
+		fromCashLetters[i] = &from.CashLetters[i]
+	}
+	for i := range to.CashLetters {
+		toCashLetters[i] = &to.CashLetters[i]
+	}
+	d.keyed("/cashLetters", "CashLetter", fromCashLetters, toCashLetters, func(v interface{}) string {
+		if h := v.(*CashLetter).CashLetterHeader; h != nil {
+			return h.CashLetterID
+		}
+		return ""
+	}, func(path string, a, b interface{}) {
+		d.cashLetter(path, a.(*CashLetter), b.(*CashLetter))
+	})
+	d.record("/fileControl", "FileControl", &from.Control, &to.Control)
+	return d
+}
+
+// cashLetter adds the differences of two CashLetters with the same CashLetterID
+func (d *FileDiff) cashLetter(path string, from, to *CashLetter) {
+	d.record(path+"/cashLetterHeader", "CashLetterHeader", from.CashLetterHeader, to.CashLetterHeader)
+	d.records(path+"/creditItem", "CreditItem", from.CreditItems, to.CreditItems)
+	d.records(path+"/accountTotalsDetail", "AccountTotalsDetail", from.AccountTotalsDetail, to.AccountTotalsDetail)
+	d.records(path+"/nonHitTotalsDetail", "NonHitTotalsDetail", from.NonHitTotalsDetail, to.NonHitTotalsDetail)
+	d.userRecords(path, from.UserRecords, to.UserRecords)
+	d.keyed(path+"/bundles", "Bundle", interfaces(from.Bundles), interfaces(to.Bundles), func(v interface{}) string {
+		if h := v.(*Bundle).BundleHeader; h != nil {
+			return h.BundleSequenceNumber
+		}
+		return ""
+	}, func(path string, a, b interface{}) {
+		d.bundle(path, a.(*Bundle), b.(*Bundle))
+	})
+	d.records(path+"/boxSummary", "BoxSummary", from.BoxSummary, to.BoxSummary)
+	d.records(path+"/routingNumberSummary", "RoutingNumberSummary", from.RoutingNumberSummary, to.RoutingNumberSummary)
+	d.record(path+"/cashLetterControl", "CashLetterControl", from.CashLetterControl, to.CashLetterControl)
+}
+
+// bundle adds the differences of two Bundles with the same BundleSequenceNumber
+func (d *FileDiff) bundle(path string, from, to *Bundle) {
+	d.record(path+"/bundleHeader", "BundleHeader", from.BundleHeader, to.BundleHeader)
+	d.records(path+"/credits", "Credit", from.Credits, to.Credits)
+	d.userRecords(path, from.UserRecords, to.UserRecords)
+	d.keyed(path+"/checks", "CheckDetail", interfaces(from.Checks), interfaces(to.Checks), func(v interface{}) string {
+		return v.(*CheckDetail).EceInstitutionItemSequenceNumber
+	}, func(path string, a, b interface{}) {
+		from, to := a.(*CheckDetail), b.(*CheckDetail)
+		d.record(path, "CheckDetail", from, to)
+		d.records(path+"/checkDetailAddendumA", "CheckDetailAddendumA", from.CheckDetailAddendumA, to.CheckDetailAddendumA)
+		d.records(path+"/checkDetailAddendumB", "CheckDetailAddendumB", from.CheckDetailAddendumB, to.CheckDetailAddendumB)
+		d.records(path+"/checkDetailAddendumC", "CheckDetailAddendumC", from.CheckDetailAddendumC, to.CheckDetailAddendumC)
+		d.imageViews(path, from.ImageViewDetail, from.ImageViewData, from.ImageViewAnalysis,
+			to.ImageViewDetail, to.ImageViewData, to.ImageViewAnalysis)
+		d.userRecords(path, from.UserRecords, to.UserRecords)
+	})
+	d.keyed(path+"/returns", "ReturnDetail", interfaces(from.Returns), interfaces(to.Returns), func(v interface{}) string {
+		return v.(*ReturnDetail).EceInstitutionItemSequenceNumber
+	}, func(path string, a, b interface{}) {
+		from, to := a.(*ReturnDetail), b.(*ReturnDetail)
+		d.record(path, "ReturnDetail", from, to)
+		d.records(path+"/returnDetailAddendumA", "ReturnDetailAddendumA", from.ReturnDetailAddendumA, to.ReturnDetailAddendumA)
+		d.records(path+"/returnDetailAddendumB", "ReturnDetailAddendumB", from.ReturnDetailAddendumB, to.ReturnDetailAddendumB)
+		d.records(path+"/returnDetailAddendumC", "ReturnDetailAddendumC", from.ReturnDetailAddendumC, to.ReturnDetailAddendumC)
+		d.records(path+"/returnDetailAddendumD", "ReturnDetailAddendumD", from.ReturnDetailAddendumD, to.ReturnDetailAddendumD)
+		d.imageViews(path, from.ImageViewDetail, from.ImageViewData, from.ImageViewAnalysis,
+			to.ImageViewDetail, to.ImageViewData, to.ImageViewAnalysis)
+		d.userRecords(path, from.UserRecords, to.UserRecords)
+	})
+	d.record(path+"/bundleControl", "BundleControl", from.BundleControl, to.BundleControl)
+}
+
+// imageViews adds the differences of the image view records of two items
+func (d *FileDiff) imageViews(path string, fromDetail []ImageViewDetail, fromData []ImageViewData, fromAnalysis []ImageViewAnalysis,
+	toDetail []ImageViewDetail, toData []ImageViewData, toAnalysis []ImageViewAnalysis) {
+	d.records(path+"/imageViewDetail", "ImageViewDetail", fromDetail, toDetail)
+	d.records(path+"/imageViewData", "ImageViewData", fromData, toData)
+	d.records(path+"/imageViewAnalysis", "ImageViewAnalysis", fromAnalysis, toAnalysis)
+}
+
+// userRecords adds the differences of the User Records at path
+func (d *FileDiff) userRecords(path string, from, to UserRecords) {
+	d.records(path+"/userRecords", "UserRecord", []UserRecord(from), []UserRecord(to))
+}
+
+// keyed aligns the records of two slices by key and calls diff for the records with the same key. Records with the
+// same key are aligned in order, and the second and later are located by key#n.
+func (d *FileDiff) keyed(path, recordName string, from, to []interface{}, key func(interface{}) string, diff func(path string, a, b interface{})) {
+	keys := func(values []interface{}) []string {
+		seen := make(map[string]int)
+		keys := make([]string, len(values))
+		for i, v := range values {
+			k := "-"
+			if !isNilRecord(v) {
+				if trimmed := strings.TrimSpace(key(v)); trimmed != "" {
+					k = trimmed
+				}
+			}
+			seen[k]++
+			if seen[k] > 1 {
+				k = fmt.Sprintf("%s#%d", k, seen[k])
+			}
+			keys[i] = k
+		}
+		return keys
+	}
+	fromKeys, toKeys := keys(from), keys(to)
+	toIndex := make(map[string]int)
+	for i, k := range toKeys {
+		toIndex[k] = i
+	}
+	matched := make(map[string]bool)
+	for i, k := range fromKeys {
+		keyPath := path + "/" + k
+		j, ok := toIndex[k]
+		if !ok {
+			d.Records = append(d.Records, RecordDiff{Kind: DiffRemoved, Path: keyPath, RecordName: recordName})
+			continue
+		}
+		matched[k] = true
+		switch a, b := from[i], to[j]; {
+		case isNilRecord(a) && isNilRecord(b):
+		case isNilRecord(a):
+			d.Records = append(d.Records, RecordDiff{Kind: DiffAdded, Path: keyPath, RecordName: recordName})
+		case isNilRecord(b):
+			d.Records = append(d.Records, RecordDiff{Kind: DiffRemoved, Path: keyPath, RecordName: recordName})
+		default:
+			diff(keyPath, a, b)
+		}
+	}
+	for _, k := range toKeys {
+		if !matched[k] {
+			d.Records = append(d.Records, RecordDiff{Kind: DiffAdded, Path: path + "/" + k, RecordName: recordName})
+		}
+	}
+}
+
+// records compares two slices of records by their position
+func (d *FileDiff) records(path, recordName string, from, to interface{}) {
+	a, b := reflect.ValueOf(from), reflect.ValueOf(to)
+	for i := 0; i < a.Len() || i < b.Len(); i++ {
+		var x, y interface{}
+		if i < a.Len() {
+			x = recordValue(a.Index(i))
+		}
+		if i < b.Len() {
+			y = recordValue(b.Index(i))
+		}
+		d.record(fmt.Sprintf("%s/%d", path, i), recordName, x, y)
+	}
+}
+
+// recordValue returns a record of a slice, as a pointer to records which are values
+func recordValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
+
+// record compares two records, either of which may be nil
+func (d *FileDiff) record(path, recordName string, from, to interface{}) {
+	switch {
+	case isNilRecord(from) && isNilRecord(to):
+		return
+	case isNilRecord(from):
+		d.Records = append(d.Records, RecordDiff{Kind: DiffAdded, Path: path, RecordName: recordName})
+		return
+	case isNilRecord(to):
+		d.Records = append(d.Records, RecordDiff{Kind: DiffRemoved, Path: path, RecordName: recordName})
+		return
+	}
+	a, b := reflect.Indirect(reflect.ValueOf(from)), reflect.Indirect(reflect.ValueOf(to))
+	if a.Type() != b.Type() {
+		// User Records of different types
+		d.Records = append(d.Records, RecordDiff{Kind: DiffRemoved, Path: path, RecordName: recordName},
+			RecordDiff{Kind: DiffAdded, Path: path, RecordName: recordName})
+		return
+	}
+	var fields []FieldDiff
+	if a.Kind() == reflect.Struct {
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			if field.PkgPath != "" || field.Name == "ID" {
+				continue
+			}
+			// records contained in the record are compared on their own
+			if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8 {
+				continue
+			}
+			if x, y, ok := diffField(a.Field(i), b.Field(i)); !ok {
+				fields = append(fields, FieldDiff{FieldName: field.Name, From: x, To: y})
+			}
+		}
+	} else if x, y, ok := diffField(a, b); !ok {
+		fields = append(fields, FieldDiff{FieldName: recordName, From: x, To: y})
+	}
+	if len(fields) > 0 {
+		d.Records = append(d.Records, RecordDiff{Kind: DiffChanged, Path: path, RecordName: recordName, Fields: fields})
+	}
+}
+
+// diffField returns the values of two fields, and true when they're equal
+func diffField(a, b reflect.Value) (string, string, bool) {
+	if a.Kind() == reflect.Slice && a.Type().Elem().Kind() == reflect.Uint8 {
+		x, y := a.Bytes(), b.Bytes()
+		return diffBytes(x), diffBytes(y), bytes.Equal(x, y)
+	}
+	x, y := diffValue(a), diffValue(b)
+	return x, y, x == y
+}
+
+// diffBytes describes binary data by its SHA-256 hash and length
+func diffBytes(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("sha256:%s (%d bytes)", hex.EncodeToString(sum[:]), len(data))
+}
+
+// diffValue formats the value of a field
+func diffValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		switch {
+		case t.Year() == 0:
+			// a time of day, e.g. FileCreationTime
+			return t.Format("15:04:05")
+		case t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// interfaces returns the elements of a slice of records as interfaces
+func interfaces(slice interface{}) []interface{} {
+	v := reflect.ValueOf(slice)
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// mockDiffFile reads valid-ascii.x937
+func mockDiffFile(t *testing.T) *File {
+	fd, err := os.Open(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	defer fd.Close()
+	file, err := NewReader(fd, ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	return &file
+}
+
+// TestDiffFiles validates records added, removed and changed between Files are found at field level
+func TestDiffFiles(t *testing.T) {
+	from, to := mockDiffFile(t), mockDiffFile(t)
+	if diff := DiffFiles(from, to); !diff.Equal() {
+		t.Fatalf("unexpected RecordDiffs: %v", diff.Records)
+	}
+
+	bundle := to.CashLetters[0].Bundles[0]
+	cd := bundle.Checks[0]
+	cd.ItemAmount++
+	cd.ID = "ignored"
+	cd.ImageViewData[0].ImageData = append([]byte{}, cd.ImageViewData[0].ImageData...)
+	cd.ImageViewData[0].ImageData[len(cd.ImageViewData[0].ImageData)-1] ^= 0xff
+	cd.ImageViewAnalysis = append(cd.ImageViewAnalysis, NewImageViewAnalysis())
+	added := mockCheckDetail()
+	added.EceInstitutionItemSequenceNumber = "999"
+	bundle.Checks = append(bundle.Checks, added)
+
+	diff := DiffFiles(from, to)
+	clID := strings.TrimSpace(from.CashLetters[0].CashLetterHeader.CashLetterID)
+	bundleSeq := strings.TrimSpace(bundle.BundleHeader.BundleSequenceNumber)
+	checkPath := "/cashLetters/" + clID + "/bundles/" + bundleSeq + "/checks/" + strings.TrimSpace(cd.EceInstitutionItemSequenceNumber)
+	byPath := make(map[string]RecordDiff)
+	for _, rec := range diff.Records {
+		byPath[string(rec.Kind)+" "+rec.Path] = rec
+	}
+	if rec, ok := byPath["changed "+checkPath]; !ok || rec.RecordName != "CheckDetail" || len(rec.Fields) != 1 ||
+		rec.Fields[0].FieldName != "ItemAmount" {
+		t.Errorf("unexpected RecordDiffs: %v", diff.Records)
+	}
+	if rec, ok := byPath["changed "+checkPath+"/imageViewData/0"]; !ok || len(rec.Fields) != 1 ||
+		rec.Fields[0].FieldName != "ImageData" || !strings.HasPrefix(rec.Fields[0].From, "sha256:") ||
+		rec.Fields[0].From == rec.Fields[0].To {
+		t.Errorf("unexpected RecordDiffs: %v", diff.Records)
+	}
+	analysisPath := checkPath + "/imageViewAnalysis/" + strconv.Itoa(len(cd.ImageViewAnalysis)-1)
+	if rec, ok := byPath["added "+analysisPath]; !ok || rec.RecordName != "ImageViewAnalysis" {
+		t.Errorf("unexpected RecordDiffs: %v", diff.Records)
+	}
+	if rec, ok := byPath["added /cashLetters/"+clID+"/bundles/"+bundleSeq+"/checks/999"]; !ok || rec.RecordName != "CheckDetail" {
+		t.Errorf("unexpected RecordDiffs: %v", diff.Records)
+	}
+	if len(diff.Records) != 4 {
+		t.Errorf("unexpected RecordDiffs: %v", diff.Records)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.WriteText(&buf); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if !strings.Contains(buf.String(), "changed CheckDetail "+checkPath+"\n    ItemAmount: ") {
+		t.Errorf("unexpected text: %s", buf.String())
+	}
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if !bytes.Contains(data, []byte(`"kind":"added"`)) || !bytes.Contains(data, []byte(`"fieldName":"ItemAmount"`)) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+// TestDiffFiles__CashLetters validates cash letters are aligned by CashLetterID
+func TestDiffFiles__CashLetters(t *testing.T) {
+	from, to := mockDiffFile(t), mockDiffFile(t)
+	id := strings.TrimSpace(from.CashLetters[0].CashLetterHeader.CashLetterID)
+	to.CashLetters[0].CashLetterHeader.CashLetterID = "RESEND"
+	to.Header.ResendIndicator = "Y"
+
+	diff := DiffFiles(from, to)
+	if len(diff.Records) != 3 {
+		t.Fatalf("unexpected RecordDiffs: %v", diff.Records)
+	}
+	for i, expected := range []RecordDiff{
+		{Kind: DiffChanged, Path: "/fileHeader", RecordName: "FileHeader",
+			Fields: []FieldDiff{{FieldName: "ResendIndicator", From: from.Header.ResendIndicator, To: "Y"}}},
+		{Kind: DiffRemoved, Path: "/cashLetters/" + id, RecordName: "CashLetter"},
+		{Kind: DiffAdded, Path: "/cashLetters/RESEND", RecordName: "CashLetter"},
+	} {
+		rec := diff.Records[i]
+		if rec.Kind != expected.Kind || rec.Path != expected.Path || rec.RecordName != expected.RecordName ||
+			len(rec.Fields) != len(expected.Fields) || (len(rec.Fields) > 0 && rec.Fields[0] != expected.Fields[0]) {
+			t.Errorf("unexpected RecordDiff: %#v", rec)
+		}
+	}
+	if diff := DiffFiles(nil, to); len(diff.Records) != 3 {
+		t.Errorf("unexpected RecordDiffs: %v", diff.Records)
+	}
+}
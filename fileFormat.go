@@ -0,0 +1,193 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Errors specific to detecting the format of a file
+var (
+	msgFileFormat = "is not an ASCII or EBCDIC FileHeader with length prefix, RDW or newline framing"
+)
+
+// errRecordSegment is returned when a record is segmented across RDWs, which isn't supported
+var errRecordSegment = errors.New("segmented RDW records are not supported")
+
+// Framing is how the records of a file are separated
+type Framing string
+
+const (
+	// FramingLengthPrefix records are prefixed with their length as a 4 byte big-endian integer, see
+	// ReadVariableLineLengthOption
+	FramingLengthPrefix Framing = "length-prefix"
+	// FramingRDW records are prefixed with an IBM Record Descriptor Word: a 2 byte big-endian length, including
+	// the RDW, and 2 zero bytes
+	FramingRDW Framing = "rdw"
+	// FramingBlockedRDW records are FramingRDW records in blocks prefixed with an IBM Block Descriptor Word of
+	// the same format, as written by variable blocked (VB) datasets
+	FramingBlockedRDW Framing = "blocked-rdw"
+	// FramingLF records end with a newline
+	FramingLF Framing = "lf"
+	// FramingCRLF records end with a carriage return and newline
+	FramingCRLF Framing = "crlf"
+)
+
+// FileFormat is the encoding and framing of a file
+type FileFormat struct {
+	// EBCDIC is true when records are EBCDIC encoded, and false when they're ASCII
+	EBCDIC bool `json:"ebcdic"`
+	// Framing is how records are separated
+	Framing Framing `json:"framing"`
+}
+
+// String returns a description of the FileFormat, e.g. "EBCDIC length-prefix"
+func (f FileFormat) String() string {
+	if f.EBCDIC {
+		return "EBCDIC " + string(f.Framing)
+	}
+	return "ASCII " + string(f.Framing)
+}
+
+// DetectFileFormat detects the FileFormat of a file from its first bytes, which hold its FileHeader. A FileError
+// is returned when the first record isn't a FileHeader in a format which can be read.
+func DetectFileFormat(data []byte) (FileFormat, error) {
+	header := func(b []byte) (bool, bool) {
+		switch {
+		case bytes.HasPrefix(b, []byte(fileHeaderPos)):
+			return true, false
+		case bytes.HasPrefix(b, []byte(fileHeaderEbcPos)):
+			return true, true
+		}
+		return false, false
+	}
+	if len(data) >= 10 {
+		// a FileHeader record is 80 bytes
+		length := int(binary.BigEndian.Uint32(data[0:4]))
+		// an RDW read as a length prefix is at least 0x00540000
+		if ok, ebcdic := header(data[4:]); ok && length >= 80 && length <= 0xffff {
+			return FileFormat{EBCDIC: ebcdic, Framing: FramingLengthPrefix}, nil
+		}
+		rdw := int(binary.BigEndian.Uint16(data[0:2]))
+		if data[2] == 0 && data[3] == 0 && rdw >= 84 {
+			if ok, ebcdic := header(data[4:]); ok {
+				return FileFormat{EBCDIC: ebcdic, Framing: FramingRDW}, nil
+			}
+			if data[6] == 0 && data[7] == 0 && int(binary.BigEndian.Uint16(data[4:6])) >= 84 {
+				if ok, ebcdic := header(data[8:]); ok {
+					return FileFormat{EBCDIC: ebcdic, Framing: FramingBlockedRDW}, nil
+				}
+			}
+		}
+	}
+	if ok, ebcdic := header(data); ok {
+		format := FileFormat{EBCDIC: ebcdic, Framing: FramingLF}
+		if i := bytes.IndexByte(data, '\n'); i > 0 && data[i-1] == '\r' {
+			format.Framing = FramingCRLF
+		}
+		return format, nil
+	}
+	return FileFormat{}, &FileError{FieldName: "FileHeader", Msg: msgFileFormat}
+}
+
+// ReadDetectFormatOption allows Reader to detect the encoding and framing of a file from its first bytes, in place
+// of ReadEbcdicEncodingOption and ReadVariableLineLengthOption. Files with length prefix, IBM RDW and newline
+// (LF or CRLF) framing are read, ASCII or EBCDIC encoded. Reader.Format returns the format detected.
+func ReadDetectFormatOption() ReaderOption {
+	return func(r *Reader) {
+		r.detectFormat = true
+	}
+}
+
+// Format detects and returns the FileFormat of a Reader created with ReadDetectFormatOption. Read and NextRecord
+// detect the format when Format hasn't been called. Without ReadDetectFormatOption the FileFormat of the options
+// the Reader was created with is returned.
+func (r *Reader) Format() (FileFormat, error) {
+	if r.format != nil {
+		return *r.format, nil
+	}
+	if !r.detectFormat {
+		return FileFormat{EBCDIC: r.ebcdic, Framing: r.framing}, nil
+	}
+	br := bufio.NewReaderSize(r.input, 4096)
+	data, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return FileFormat{}, r.error(&FileError{FieldName: "LineNumber", Value: "0", Msg: err.Error()})
+	}
+	format, err := DetectFileFormat(data)
+	if err != nil {
+		r.recordName = "FileHeader"
+		return FileFormat{}, r.error(err)
+	}
+
+	// read from the buffered input, which holds the peeked bytes
+	r.scanner = bufio.NewScanner(br)
+	r.setScannerBuffer()
+	r.decodeLine, r.ebcdic, r.framing = Passthrough, false, FramingLF
+	if format.EBCDIC {
+		ReadEbcdicEncodingOption()(r)
+	}
+	switch format.Framing {
+	case FramingLengthPrefix:
+		ReadVariableLineLengthOption()(r)
+	case FramingRDW:
+		r.scanner.Split(r.scanRDW(false))
+	case FramingBlockedRDW:
+		r.scanner.Split(r.scanRDW(true))
+	}
+	r.format = &format
+	return format, nil
+}
+
+// scanRDW returns a bufio.SplitFunc which splits records prefixed with an RDW, in blocks prefixed with a BDW when
+// blocked.
+func (r *Reader) scanRDW(blocked bool) bufio.SplitFunc {
+	// blockLeft is the number of bytes left in the current block
+	blockLeft := 0
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if len(data) == 0 && atEOF {
+			// all records have been read
+			return 0, nil, nil
+		}
+		skip := 0
+		if blocked && blockLeft <= 0 {
+			// the block starts with a BDW, then its first RDW
+			skip = 4
+		}
+		if len(data) < skip+4 {
+			if atEOF {
+				return 0, nil, io.ErrUnexpectedEOF
+			}
+			return 0, nil, nil
+		}
+		if skip > 0 {
+			blockLeft = int(binary.BigEndian.Uint16(data[0:2])) - 4
+		}
+		rdw := data[skip : skip+4]
+		if rdw[2] != 0 || rdw[3] != 0 {
+			return 0, nil, errRecordSegment
+		}
+		length := int(binary.BigEndian.Uint16(rdw[0:2])) - 4
+		if length < 0 || length > r.maxRecordLength {
+			return 0, nil, errRecordTooLong
+		}
+		if len(data) < skip+4+length {
+			if skip > 0 {
+				// read the BDW again with the record
+				blockLeft = 0
+			}
+			if atEOF {
+				return 0, nil, io.ErrUnexpectedEOF
+			}
+			return 0, nil, nil
+		}
+		blockLeft -= 4 + length
+		return skip + 4 + length, data[skip+4 : skip+4+length], nil
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// mockFramedFile reframes a file with length prefixes as RDW records, in blocks of up to blockSize bytes when
+// blockSize isn't 0.
+func mockFramedFile(t *testing.T, data []byte, blockSize int) []byte {
+	t.Helper()
+	var records [][]byte
+	for len(data) > 0 {
+		length := int(binary.BigEndian.Uint32(data[0:4]))
+		rdw := make([]byte, 4, 4+length)
+		binary.BigEndian.PutUint16(rdw[0:2], uint16(4+length))
+		records = append(records, append(rdw, data[4:4+length]...))
+		data = data[4+length:]
+	}
+	var buf bytes.Buffer
+	for len(records) > 0 {
+		n, size := 0, 4
+		for n < len(records) && (blockSize == 0 || n == 0 || size+len(records[n]) <= blockSize) {
+			size += len(records[n])
+			n++
+		}
+		if blockSize > 0 {
+			bdw := make([]byte, 4)
+			binary.BigEndian.PutUint16(bdw[0:2], uint16(size))
+			buf.Write(bdw)
+		}
+		for _, rec := range records[:n] {
+			buf.Write(rec)
+		}
+		records = records[n:]
+	}
+	return buf.Bytes()
+}
+
+// TestReader__DetectFormat validates the encoding and framing of files are detected
+func TestReader__DetectFormat(t *testing.T) {
+	ascii, err := ioutil.ReadFile(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	ebcdic, err := ioutil.ReadFile(filepath.Join("test", "testdata", "valid-ebcdic.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	file, err := NewReader(bytes.NewReader(ascii), ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	expected, _ := json.Marshal(file)
+
+	// newline framing needs image data without line endings
+	lines := file
+	lines.CashLetters = []CashLetter{file.CashLetters[0]}
+	for _, cd := range lines.CashLetters[0].Bundles[0].Checks {
+		for i := range cd.ImageViewData {
+			cd.ImageViewData[i].ImageData = []byte("*IMAGE DATA*")
+			cd.ImageViewData[i].LengthImageData = "0000012"
+		}
+	}
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(&lines); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	lf := buf.Bytes()
+	crlf := bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+	expectedLines, _ := json.Marshal(lines)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		format   FileFormat
+		expected []byte
+	}{
+		{"ascii length prefix", ascii, FileFormat{Framing: FramingLengthPrefix}, expected},
+		{"ebcdic length prefix", ebcdic, FileFormat{EBCDIC: true, Framing: FramingLengthPrefix}, nil},
+		{"ascii rdw", mockFramedFile(t, ascii, 0), FileFormat{Framing: FramingRDW}, expected},
+		{"ebcdic rdw", mockFramedFile(t, ebcdic, 0), FileFormat{EBCDIC: true, Framing: FramingRDW}, nil},
+		{"ascii blocked rdw", mockFramedFile(t, ascii, 32760), FileFormat{Framing: FramingBlockedRDW}, expected},
+		{"ebcdic blocked rdw", mockFramedFile(t, ebcdic, 32760), FileFormat{EBCDIC: true, Framing: FramingBlockedRDW}, nil},
+		{"lf", lf, FileFormat{Framing: FramingLF}, expectedLines},
+		{"crlf", crlf, FileFormat{Framing: FramingCRLF}, expectedLines},
+	}
+	for _, test := range tests {
+		r := NewReader(bytes.NewReader(test.data), ReadDetectFormatOption())
+		format, err := r.Format()
+		if err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		if format != test.format {
+			t.Errorf("%s: detected %s", test.name, format)
+		}
+		read, err := r.Read()
+		if err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		if len(read.CashLetters) == 0 || len(read.CashLetters[0].Bundles[0].Checks) == 0 {
+			t.Errorf("%s: no checks read", test.name)
+		}
+		if got, _ := json.Marshal(read); test.expected != nil && !bytes.Equal(got, test.expected) {
+			t.Errorf("%s: file read does not match", test.name)
+		}
+	}
+
+	// the format is detected by NextRecord
+	r := NewReader(bytes.NewReader(mockFramedFile(t, ebcdic, 0)), ReadDetectFormatOption())
+	if rec, err := r.NextRecord(); err != nil || rec.Name != "FileHeader" {
+		t.Errorf("%v %T: %s", rec, err, err)
+	}
+
+	// files which aren't recognized
+	for _, data := range [][]byte{nil, []byte("not an image cash letter file"), ascii[8:100]} {
+		_, err := NewReader(bytes.NewReader(data), ReadDetectFormatOption()).Read()
+		if e, ok := err.(*ParseError); !ok {
+			t.Errorf("%T: %s", err, err)
+		} else if fe, ok := e.Err.(*FileError); !ok || fe.FieldName != "FileHeader" {
+			t.Errorf("%T: %s", e.Err, e.Err)
+		}
+	}
+
+	// without ReadDetectFormatOption the format of the options is returned
+	format, _ := NewReader(bytes.NewReader(ebcdic), ReadVariableLineLengthOption(), ReadEbcdicEncodingOption()).Format()
+	if format != (FileFormat{EBCDIC: true, Framing: FramingLengthPrefix}) {
+		t.Errorf("unexpected format: %s", format)
+	}
+}
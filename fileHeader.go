@@ -92,6 +92,8 @@ type FileHeader struct {
 	validator
 	// converters is composed for ImageCashLetter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewFileHeader returns a new FileHeader with default values for non exported fields
@@ -0,0 +1,441 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Errors specific to analyzing images
+var (
+	msgAnalysisImage    = "is not a bitonal CCITT Group 4 TIFF image which can be analyzed"
+	msgAnalysisMismatch = "is reported as %v, the image analysis found %v"
+	msgImageDimensions  = "is %v x %v pixels, more than its image data or resolution allows"
+)
+
+// maxImagePixels is the most pixels of an image which is decoded, more than a 9 x 4.25 inch image at 1200 DPI
+const maxImagePixels = 64 << 20
+
+// ImageAnalyzerOption is an option for an ImageAnalyzer
+type ImageAnalyzerOption func(*ImageAnalyzer)
+
+// ImageSizeOption sets the minimum and maximum width and height of an image, in inches.
+func ImageSizeOption(minWidth, minHeight, maxWidth, maxHeight float64) ImageAnalyzerOption {
+	return func(a *ImageAnalyzer) {
+		a.MinWidth, a.MinHeight = minWidth, minHeight
+		a.MaxWidth, a.MaxHeight = maxWidth, maxHeight
+	}
+}
+
+// ImageDarknessOption sets the minimum and maximum fraction of black pixels of an image.
+func ImageDarknessOption(min, max float64) ImageAnalyzerOption {
+	return func(a *ImageAnalyzer) {
+		a.MinDarkness, a.MaxDarkness = min, max
+	}
+}
+
+// ImageSkewOption sets the maximum skew of an image, in degrees.
+func ImageSkewOption(degrees float64) ImageAnalyzerOption {
+	return func(a *ImageAnalyzer) {
+		a.MaxSkew = degrees
+	}
+}
+
+// ImageStreakOption sets the fraction of black pixels of a row or column which makes it a streak or band.
+func ImageStreakOption(darkness float64) ImageAnalyzerOption {
+	return func(a *ImageAnalyzer) {
+		a.StreakDarkness = darkness
+	}
+}
+
+// ImagePartialOption sets the fraction of the image's rows which, when black or missing at the top or bottom of
+// the image, make it a partial image.
+func ImagePartialOption(fraction float64) ImageAnalyzerOption {
+	return func(a *ImageAnalyzer) {
+		a.PartialRows = fraction
+	}
+}
+
+// ImageAnalyzer computes the image quality tests of an ImageViewAnalysis from the bitonal CCITT Group 4 TIFF
+// image data of an ImageViewData:
+//
+// PartialImage is present when the image data ends before its last row, or too many of its first or last rows
+// are black. ExcessiveImageSkew is present when the text of the image is skewed by more than MaxSkew.
+// TooLightOrTooDark is present when the fraction of black pixels is outside MinDarkness and MaxDarkness.
+// StreaksAndOrBands is present when a row or column, away from the edges of the image, is nearly all black.
+// BelowMinimumImageSize and ExceedsMaximumImageSize compare the size of the image at its resolution.
+//
+// PiggybackImage, the image usability tests and ImageEnabledPOD need knowledge of the document which isn't in
+// its image, and are left as not tested.
+type ImageAnalyzer struct {
+	// MinWidth and MinHeight are the smallest size of an image, in inches.
+	MinWidth, MinHeight float64
+	// MaxWidth and MaxHeight are the largest size of an image, in inches.
+	MaxWidth, MaxHeight float64
+	// MinDarkness and MaxDarkness are the smallest and largest fraction of black pixels of an image.
+	MinDarkness, MaxDarkness float64
+	// MaxSkew is the largest skew of an image, in degrees.
+	MaxSkew float64
+	// StreakDarkness is the fraction of black pixels of a row or column which makes it a streak or band.
+	StreakDarkness float64
+	// PartialRows is the fraction of rows which, black or missing at the top or bottom, make a partial image.
+	PartialRows float64
+}
+
+// NewImageAnalyzer returns an ImageAnalyzer with the thresholds of the options. Thresholds which aren't set are
+// for a check image: 5.0 x 2.125 to 9.0 x 4.25 inches, 0.5% to 40% black pixels, 3 degrees of skew, 90% black
+// streaks and bands, and 10% of rows for a partial image.
+func NewImageAnalyzer(opts ...ImageAnalyzerOption) *ImageAnalyzer {
+	a := &ImageAnalyzer{
+		MinWidth:       5.0,
+		MinHeight:      2.125,
+		MaxWidth:       9.0,
+		MaxHeight:      4.25,
+		MinDarkness:    0.005,
+		MaxDarkness:    0.40,
+		MaxSkew:        3,
+		StreakDarkness: 0.90,
+		PartialRows:    0.10,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Analyze decodes the bitonal CCITT Group 4 TIFF image data of an ImageViewData and returns an ImageViewAnalysis
+// with the results of the image quality tests. A FieldError is returned for image data which isn't a TIFF image
+// with an ImageViewCompressionAlgorithm of 00, whose TIFF structure is corrupt, or which is more than twice
+// MaxWidth by MaxHeight at its resolution or larger than its image data can encode.
+func (a *ImageAnalyzer) Analyze(ivDetail *ImageViewDetail, ivData *ImageViewData) (ImageViewAnalysis, error) {
+	if ivDetail.ImageViewCompressionAlgorithm != "00" {
+		return ImageViewAnalysis{}, &FieldError{FieldName: "ImageData", Msg: msgAnalysisImage}
+	}
+	info, err := InspectImageView(ivDetail, ivData)
+	if info == nil {
+		if err == nil {
+			err = &FieldError{FieldName: "ImageData", Msg: msgAnalysisImage}
+		}
+		return ImageViewAnalysis{}, err
+	}
+	if info.Compression != 4 || info.BitsPerSample != 1 || info.Width <= 0 || info.Height <= 0 {
+		return ImageViewAnalysis{}, &FieldError{FieldName: "ImageData", Msg: msgAnalysisImage}
+	}
+	xdpi, ydpi := info.XResolution, info.YResolution
+	if info.ResolutionUnit == 3 {
+		xdpi, ydpi = xdpi*2.54, ydpi*2.54
+	}
+	if err := checkTIFFDimensions(info); err != nil {
+		return ImageViewAnalysis{}, err
+	}
+	// images more than twice MaxWidth or MaxHeight at their resolution are not decoded
+	if (xdpi > 0 && a.MaxWidth > 0 && float64(info.Width) > 2*a.MaxWidth*xdpi) ||
+		(ydpi > 0 && a.MaxHeight > 0 && float64(info.Height) > 2*a.MaxHeight*ydpi) {
+		return ImageViewAnalysis{}, &FieldError{FieldName: "ImageData", Value: fmt.Sprintf("%vx%v", info.Width, info.Height),
+			Msg: fmt.Sprintf(msgImageDimensions, info.Width, info.Height)}
+	}
+	img, complete := decodeTIFFG4(imageViewBytes(ivData), info)
+	return a.analyze(img, info.Height, xdpi, ydpi, complete), nil
+}
+
+// checkTIFFDimensions returns a FieldError when the width and height of TIFF image data are more than
+// maxImagePixels, or more than its strips of image data can encode.
+func checkTIFFDimensions(info *TIFFInfo) error {
+	width, height := int64(info.Width), int64(info.Height)
+	var stripBytes int64
+	for _, n := range info.StripByteCounts {
+		stripBytes += int64(n)
+	}
+	bits := int64(info.BitsPerSample) * int64(info.SamplesPerPixel)
+	if bits <= 0 || bits > 64 {
+		bits = 1
+	}
+	rowBytes := (width*bits + 7) / 8
+	tooLarge := width*height > maxImagePixels
+	switch info.Compression {
+	case 1:
+		tooLarge = tooLarge || rowBytes*height > stripBytes
+	case 4:
+		// each row of Group 4 image data is at least a 1 bit code
+		tooLarge = tooLarge || height > stripBytes*8
+	case 32773:
+		// each 2 bytes of PackBits image data are at most 128 bytes of pixels
+		tooLarge = tooLarge || rowBytes*height > stripBytes*64
+	}
+	if tooLarge {
+		return &FieldError{FieldName: "ImageData", Value: fmt.Sprintf("%vx%v", info.Width, info.Height),
+			Msg: fmt.Sprintf(msgImageDimensions, info.Width, info.Height)}
+	}
+	return nil
+}
+
+// decodeTIFFG4 decodes the Group 4 strips of TIFF image data, and returns the image and whether every row was
+// decoded.
+func decodeTIFFG4(data []byte, info *TIFFInfo) (*image.Gray, bool) {
+	img := image.NewGray(image.Rect(0, 0, info.Width, info.Height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	rowsPerStrip := info.RowsPerStrip
+	if rowsPerStrip <= 0 || rowsPerStrip > info.Height {
+		rowsPerStrip = info.Height
+	}
+	y := 0
+	for i, offset := range info.StripOffsets {
+		if y >= info.Height || i >= len(info.StripByteCounts) {
+			break
+		}
+		rows := rowsPerStrip
+		if y+rows > info.Height {
+			rows = info.Height - y
+		}
+		strip := data[offset : offset+info.StripByteCounts[i]]
+		n, err := decodeCCITTG4Rows(strip, info.Width, rows, info.PhotometricInterpretation == 1, img, y)
+		y += n
+		if err != nil {
+			break
+		}
+	}
+	return img.SubImage(image.Rect(0, 0, info.Width, y)).(*image.Gray), y == info.Height
+}
+
+// analyze runs the image quality tests on a decoded bitonal image of height rows
+func (a *ImageAnalyzer) analyze(img *image.Gray, height int, xdpi, ydpi float64, complete bool) ImageViewAnalysis {
+	ivAnalysis := NewImageViewAnalysis()
+	b := img.Bounds()
+	width, rows := b.Dx(), b.Dy()
+
+	rowBlack := make([]int, rows)
+	colBlack := make([]int, width)
+	black := 0
+	for y := 0; y < rows; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+width]
+		for x, p := range row {
+			if p < 128 {
+				rowBlack[y]++
+				colBlack[x]++
+			}
+		}
+		black += rowBlack[y]
+	}
+
+	// PartialImage
+	partial := !complete
+	blackTop, blackBottom := 0, 0
+	for y := 0; y < rows && rowBlack[y] == width; y++ {
+		blackTop++
+	}
+	for y := rows - 1; y >= 0 && rowBlack[y] == width; y-- {
+		blackBottom++
+	}
+	if limit := a.PartialRows * float64(height); float64(blackTop) > limit || float64(blackBottom) > limit {
+		partial = true
+	}
+	ivAnalysis.PartialImage = analysisCondition(partial)
+
+	// ExcessiveImageSkew
+	if rows > 0 && black > 0 {
+		ivAnalysis.ExcessiveImageSkew = analysisCondition(math.Abs(imageSkew(img)) > a.MaxSkew)
+	}
+
+	// TooLightOrTooDark
+	if pixels := width * rows; pixels > 0 {
+		darkness := float64(black) / float64(pixels)
+		ivAnalysis.TooLightOrTooDark = analysisCondition(darkness < a.MinDarkness || darkness > a.MaxDarkness)
+	}
+
+	// StreaksAndOrBands, away from the edges of the document
+	if rows > 0 && width > 0 {
+		streak := false
+		for x := width / 20; x < width-width/20; x++ {
+			streak = streak || float64(colBlack[x]) >= a.StreakDarkness*float64(rows)
+		}
+		for y := rows / 20; y < rows-rows/20; y++ {
+			streak = streak || float64(rowBlack[y]) >= a.StreakDarkness*float64(width)
+		}
+		ivAnalysis.StreaksAndOrBands = analysisCondition(streak)
+	}
+
+	// BelowMinimumImageSize and ExceedsMaximumImageSize
+	if xdpi > 0 && ydpi > 0 {
+		w, h := float64(width)/xdpi, float64(height)/ydpi
+		ivAnalysis.BelowMinimumImageSize = analysisCondition(w < a.MinWidth || h < a.MinHeight)
+		ivAnalysis.ExceedsMaximumImageSize = analysisCondition(w > a.MaxWidth || h > a.MaxHeight)
+	}
+
+	ivAnalysis.GlobalImageQuality = 2
+	for _, condition := range []int{ivAnalysis.PartialImage, ivAnalysis.ExcessiveImageSkew, ivAnalysis.TooLightOrTooDark,
+		ivAnalysis.StreaksAndOrBands, ivAnalysis.BelowMinimumImageSize, ivAnalysis.ExceedsMaximumImageSize} {
+		if condition == 1 {
+			ivAnalysis.GlobalImageQuality = 1
+		}
+	}
+	return ivAnalysis
+}
+
+// analysisCondition returns the ImageViewAnalysis code of a tested condition, 1 when present and 2 when not
+func analysisCondition(present bool) int {
+	if present {
+		return 1
+	}
+	return 2
+}
+
+// imageSkew estimates the skew of the text of a bitonal image in degrees, as the angle whose projection of the
+// black pixels onto the rows of the image is the most concentrated.
+func imageSkew(img *image.Gray) float64 {
+	b := img.Bounds()
+	width, rows := b.Dx(), b.Dy()
+	var xs, ys []int
+	for y := 0; y < rows; y++ {
+		for x := 0; x < width; x++ {
+			if img.Pix[y*img.Stride+x] < 128 {
+				xs = append(xs, x)
+				ys = append(ys, y)
+			}
+		}
+	}
+
+	best, bestScore := 0.0, -1.0
+	bins := make([]float64, rows+2*width+1)
+	for step := -60; step <= 60; step++ {
+		angle := float64(step) / 4
+		slope := math.Tan(angle * math.Pi / 180)
+		for i := range bins {
+			bins[i] = 0
+		}
+		for i := range xs {
+			bin := int(math.Round(float64(ys[i])-float64(xs[i])*slope)) + width
+			if bin >= 0 && bin < len(bins) {
+				bins[bin]++
+			}
+		}
+		score := 0.0
+		for _, n := range bins {
+			score += n * n
+		}
+		if score > bestScore || (score == bestScore && math.Abs(angle) < math.Abs(best)) {
+			best, bestScore = angle, score
+		}
+	}
+	return best
+}
+
+// Populate analyzes every bitonal Group 4 TIFF image of the File's CheckDetail and ReturnDetail items, and sets
+// the image quality tests of the ImageViewAnalysis at the same index as its ImageViewData. ImageViewAnalysis
+// records are added when an item has fewer than its images. Images in other formats are not analyzed.
+func (a *ImageAnalyzer) Populate(f *File) error {
+	return a.walk(f, func(path string, ivDetail *ImageViewDetail, ivData *ImageViewData, ivAnalysis *[]ImageViewAnalysis, i int) error {
+		computed, err := a.Analyze(ivDetail, ivData)
+		if err != nil {
+			return err
+		}
+		for len(*ivAnalysis) <= i {
+			*ivAnalysis = append(*ivAnalysis, NewImageViewAnalysis())
+		}
+		rec := &(*ivAnalysis)[i]
+		rec.GlobalImageQuality = computed.GlobalImageQuality
+		rec.PartialImage = computed.PartialImage
+		rec.ExcessiveImageSkew = computed.ExcessiveImageSkew
+		rec.PiggybackImage = computed.PiggybackImage
+		rec.TooLightOrTooDark = computed.TooLightOrTooDark
+		rec.StreaksAndOrBands = computed.StreaksAndOrBands
+		rec.BelowMinimumImageSize = computed.BelowMinimumImageSize
+		rec.ExceedsMaximumImageSize = computed.ExceedsMaximumImageSize
+		return nil
+	})
+}
+
+// CrossCheck analyzes every bitonal Group 4 TIFF image of the File's CheckDetail and ReturnDetail items which has
+// an ImageViewAnalysis at the same index, and returns a Violation with SeverityWarning for each image quality test
+// the ImageViewAnalysis reports differently than the analysis. Tests reported as not done are not compared.
+// Images which can't be analyzed are returned as Violations with SeverityError.
+func (a *ImageAnalyzer) CrossCheck(f *File) []Violation {
+	var violations []Violation
+	a.walk(f, func(path string, ivDetail *ImageViewDetail, ivData *ImageViewData, ivAnalysis *[]ImageViewAnalysis, i int) error {
+		if i >= len(*ivAnalysis) {
+			return nil
+		}
+		computed, err := a.Analyze(ivDetail, ivData)
+		if err != nil {
+			violations = append(violations, newViolation(fmt.Sprintf("%s/imageViewData/%d", path, i), imageViewDataPos,
+				"ImageViewData", SeverityError, err))
+			return nil
+		}
+		reported := &(*ivAnalysis)[i]
+		for _, test := range []struct {
+			fieldName          string
+			reported, computed int
+		}{
+			{"PartialImage", reported.PartialImage, computed.PartialImage},
+			{"ExcessiveImageSkew", reported.ExcessiveImageSkew, computed.ExcessiveImageSkew},
+			{"TooLightOrTooDark", reported.TooLightOrTooDark, computed.TooLightOrTooDark},
+			{"StreaksAndOrBands", reported.StreaksAndOrBands, computed.StreaksAndOrBands},
+			{"BelowMinimumImageSize", reported.BelowMinimumImageSize, computed.BelowMinimumImageSize},
+			{"ExceedsMaximumImageSize", reported.ExceedsMaximumImageSize, computed.ExceedsMaximumImageSize},
+		} {
+			if test.reported == 0 || test.computed == 0 || test.reported == test.computed {
+				continue
+			}
+			err := &FieldError{FieldName: test.fieldName, Value: fmt.Sprint(test.reported),
+				Msg: fmt.Sprintf(msgAnalysisMismatch, test.reported, test.computed)}
+			violations = append(violations, newViolation(fmt.Sprintf("%s/imageViewAnalysis/%d", path, i),
+				imageViewAnalysisPos, "ImageViewAnalysis", SeverityWarning, err))
+		}
+		return nil
+	})
+	return violations
+}
+
+// analyzedImageFunc is called for each image of a File which can be analyzed
+type analyzedImageFunc func(path string, ivDetail *ImageViewDetail, ivData *ImageViewData, ivAnalysis *[]ImageViewAnalysis, i int) error
+
+// walk calls fn for each bitonal Group 4 TIFF image of the File's CheckDetail and ReturnDetail items, paired with
+// the ImageViewDetail at the same index, and returns the first error of fn.
+func (a *ImageAnalyzer) walk(f *File, fn analyzedImageFunc) error {
+	item := func(path string, ivDetail []ImageViewDetail, ivData []ImageViewData, ivAnalysis *[]ImageViewAnalysis) error {
+		for i := range ivData {
+			if i >= len(ivDetail) {
+				break
+			}
+			d := &ivDetail[i]
+			if d.ImageIndicator == 0 || d.ImageViewFormatIndicator != "00" || d.ImageViewCompressionAlgorithm != "00" {
+				continue
+			}
+			if err := fn(path, d, &ivData[i], ivAnalysis, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i, cl := range f.CashLetters {
+		for j, b := range cl.Bundles {
+			if b == nil {
+				continue
+			}
+			for k, cd := range b.Checks {
+				if cd == nil {
+					continue
+				}
+				path := fmt.Sprintf("/cashLetters/%d/bundles/%d/checks/%d", i, j, k)
+				if err := item(path, cd.ImageViewDetail, cd.ImageViewData, &cd.ImageViewAnalysis); err != nil {
+					return err
+				}
+			}
+			for k, rd := range b.Returns {
+				if rd == nil {
+					continue
+				}
+				path := fmt.Sprintf("/cashLetters/%d/bundles/%d/returns/%d", i, j, k)
+				if err := item(path, rd.ImageViewDetail, rd.ImageViewData, &rd.ImageViewAnalysis); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
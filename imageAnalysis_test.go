@@ -0,0 +1,185 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// mockAnalyzedImage creates a white 1200 x 550 image with lines of black text, rotated by skew degrees
+func mockAnalyzedImage(skew float64) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 1200, 550))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	slope := math.Tan(skew * math.Pi / 180)
+	for line := 100; line < 450; line += 50 {
+		for x := 100; x < 1100; x++ {
+			if x%20 >= 14 {
+				continue
+			}
+			for dy := 0; dy < 3; dy++ {
+				y := line + dy + int(math.Round(float64(x)*slope))
+				if y >= 0 && y < 550 {
+					img.Pix[y*img.Stride+x] = 0
+				}
+			}
+		}
+	}
+	return img
+}
+
+// TestImageAnalyzer__analyze validates the image quality tests of an image
+func TestImageAnalyzer__analyze(t *testing.T) {
+	a := NewImageAnalyzer()
+
+	// no conditions
+	ivAnalysis := a.analyze(mockAnalyzedImage(0), 550, 200, 200, true)
+	if ivAnalysis.GlobalImageQuality != 2 || ivAnalysis.PartialImage != 2 || ivAnalysis.ExcessiveImageSkew != 2 ||
+		ivAnalysis.TooLightOrTooDark != 2 || ivAnalysis.StreaksAndOrBands != 2 || ivAnalysis.BelowMinimumImageSize != 2 ||
+		ivAnalysis.ExceedsMaximumImageSize != 2 || ivAnalysis.PiggybackImage != 0 {
+		t.Errorf("unexpected ImageViewAnalysis: %+v", ivAnalysis)
+	}
+
+	tests := []struct {
+		name      string
+		img       func() *image.Gray
+		height    int
+		dpi       float64
+		complete  bool
+		condition func(ImageViewAnalysis) int
+	}{
+		{"partial", func() *image.Gray { return mockAnalyzedImage(0) }, 550, 200, false,
+			func(v ImageViewAnalysis) int { return v.PartialImage }},
+		{"black bottom", func() *image.Gray {
+			img := mockAnalyzedImage(0)
+			for i := 480 * img.Stride; i < len(img.Pix); i++ {
+				img.Pix[i] = 0
+			}
+			return img
+		}, 550, 200, true, func(v ImageViewAnalysis) int { return v.PartialImage }},
+		{"skew", func() *image.Gray { return mockAnalyzedImage(6) }, 550, 200, true,
+			func(v ImageViewAnalysis) int { return v.ExcessiveImageSkew }},
+		{"too light", func() *image.Gray {
+			img := mockAnalyzedImage(0)
+			for i := range img.Pix {
+				img.Pix[i] = 255
+			}
+			img.Pix[600] = 0
+			return img
+		}, 550, 200, true, func(v ImageViewAnalysis) int { return v.TooLightOrTooDark }},
+		{"too dark", func() *image.Gray {
+			img := mockAnalyzedImage(0)
+			for i := range img.Pix {
+				if i%3 != 0 {
+					img.Pix[i] = 0
+				}
+			}
+			return img
+		}, 550, 200, true, func(v ImageViewAnalysis) int { return v.TooLightOrTooDark }},
+		{"streak", func() *image.Gray {
+			img := mockAnalyzedImage(0)
+			for y := 0; y < 550; y++ {
+				img.Pix[y*img.Stride+600] = 0
+			}
+			return img
+		}, 550, 200, true, func(v ImageViewAnalysis) int { return v.StreaksAndOrBands }},
+		{"below minimum", func() *image.Gray { return mockAnalyzedImage(0) }, 550, 300, true,
+			func(v ImageViewAnalysis) int { return v.BelowMinimumImageSize }},
+		{"exceeds maximum", func() *image.Gray { return mockAnalyzedImage(0) }, 550, 100, true,
+			func(v ImageViewAnalysis) int { return v.ExceedsMaximumImageSize }},
+	}
+	for _, test := range tests {
+		ivAnalysis := a.analyze(test.img(), test.height, test.dpi, test.dpi, test.complete)
+		if test.condition(ivAnalysis) != 1 || ivAnalysis.GlobalImageQuality != 1 {
+			t.Errorf("%s: unexpected ImageViewAnalysis: %+v", test.name, ivAnalysis)
+		}
+	}
+
+	// thresholds are configurable
+	a = NewImageAnalyzer(ImageSkewOption(10), ImageSizeOption(1, 1, 20, 20))
+	ivAnalysis = a.analyze(mockAnalyzedImage(6), 550, 100, 100, true)
+	if ivAnalysis.ExcessiveImageSkew != 2 || ivAnalysis.ExceedsMaximumImageSize != 2 {
+		t.Errorf("unexpected ImageViewAnalysis: %+v", ivAnalysis)
+	}
+}
+
+// TestImageAnalyzer__Analyze validates a Group 4 TIFF image is analyzed
+func TestImageAnalyzer__Analyze(t *testing.T) {
+	ivDetail, ivData := mockG4ImageView(t)
+	ivAnalysis, err := NewImageAnalyzer().Analyze(&ivDetail, &ivData)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if ivAnalysis.GlobalImageQuality != 2 || ivAnalysis.PartialImage != 2 || ivAnalysis.TooLightOrTooDark != 2 {
+		t.Errorf("unexpected ImageViewAnalysis: %+v", ivAnalysis)
+	}
+
+	// truncated image data is a partial image
+	info, _ := ParseTIFF(ivData.ImageData)
+	truncated := ivData
+	truncated.ImageData = append([]byte(nil), ivData.ImageData...)
+	for i := info.StripOffsets[0] + info.StripByteCounts[0]/2; i < info.StripOffsets[0]+info.StripByteCounts[0]; i++ {
+		truncated.ImageData[i] = 0
+	}
+	ivAnalysis, err = NewImageAnalyzer().Analyze(&ivDetail, &truncated)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if ivAnalysis.PartialImage != 1 || ivAnalysis.GlobalImageQuality != 1 {
+		t.Errorf("unexpected ImageViewAnalysis: %+v", ivAnalysis)
+	}
+
+	// images larger than their image data or resolution allow aren't decoded
+	for _, size := range [][2]int{{1 << 30, 1 << 30}, {65535, 65535}, {200, 1000}, {9 * 200 * 3, 100}} {
+		hostile := ivData
+		hostile.ImageData = encodeTIFFG4(size[0], size[1], 200, make([]byte, 16))
+		if _, err := NewImageAnalyzer().Analyze(&ivDetail, &hostile); err != nil {
+			if e, ok := err.(*FieldError); !ok || e.FieldName != "ImageData" {
+				t.Errorf("%T: %s", err, err)
+			}
+		} else {
+			t.Errorf("%v: expected error", size)
+		}
+	}
+
+	// images which aren't Group 4 TIFF can't be analyzed
+	ivDetail.ImageViewCompressionAlgorithm = "01"
+	if _, err := NewImageAnalyzer().Analyze(&ivDetail, &ivData); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestImageAnalyzer__PopulateCrossCheck validates the ImageViewAnalysis of a File are populated and cross-checked
+func TestImageAnalyzer__PopulateCrossCheck(t *testing.T) {
+	ivDetail, ivData := mockG4ImageView(t)
+	cd := mockCheckDetail()
+	cd.AddImageViewDetail(ivDetail)
+	cd.AddImageViewData(ivData)
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCheckDetail(cd)
+	file := NewFile()
+	file.CashLetters = []CashLetter{{Bundles: []*Bundle{bundle}}}
+
+	a := NewImageAnalyzer()
+	if err := a.Populate(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(cd.ImageViewAnalysis) != 1 || cd.ImageViewAnalysis[0].GlobalImageQuality != 2 {
+		t.Fatalf("unexpected ImageViewAnalysis: %+v", cd.ImageViewAnalysis)
+	}
+	if violations := a.CrossCheck(file); len(violations) != 0 {
+		t.Errorf("unexpected Violations: %v", violations)
+	}
+
+	cd.ImageViewAnalysis[0].TooLightOrTooDark = 1
+	violations := a.CrossCheck(file)
+	if len(violations) != 1 || violations[0].Path != "/cashLetters/0/bundles/0/checks/0/imageViewAnalysis/0" ||
+		violations[0].FieldName != "TooLightOrTooDark" || violations[0].Severity != SeverityWarning {
+		t.Errorf("unexpected Violations: %v", violations)
+	}
+}
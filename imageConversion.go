@@ -0,0 +1,531 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"time"
+)
+
+// Errors specific to converting images
+var (
+	msgConversionFormat      = "is not a JPEG, PNG or TIFF image"
+	msgConversionTIFF        = "has TIFF %v %v, which can't be converted"
+	msgConversionDPI         = "can't be converted to %v DPI, X9.100-181 requires 200 or 240 DPI"
+	msgConversionEmpty       = "has no pixels"
+	msgConversionImageDetail = "can't be created without an ImageCreatorRoutingNumber and ImageCreatorDate"
+)
+
+// ImageConverterOption is an option for an ImageConverter
+type ImageConverterOption func(*ImageConverter)
+
+// ImageResolutionOption sets the resolution of converted images, 200 or 240 DPI.
+func ImageResolutionOption(dpi int) ImageConverterOption {
+	return func(c *ImageConverter) {
+		c.DPI = dpi
+	}
+}
+
+// ImageSourceResolutionOption sets the resolution of the images to convert, in DPI, replacing the resolution
+// the images declare.
+func ImageSourceResolutionOption(dpi float64) ImageConverterOption {
+	return func(c *ImageConverter) {
+		c.SourceDPI = dpi
+	}
+}
+
+// ImageThresholdOption sets the gray level (1-255) below which a pixel is converted to black. When not set the
+// threshold is chosen for each image from its histogram.
+func ImageThresholdOption(level uint8) ImageConverterOption {
+	return func(c *ImageConverter) {
+		c.Threshold = level
+	}
+}
+
+// ImageCreatorOption sets the ImageCreatorRoutingNumber and ImageCreatorDate of the ImageViewDetail records, which
+// are also the EceInstitutionRoutingNumber and BundleBusinessDate of the ImageViewData records.
+func ImageCreatorOption(routingNumber string, date time.Time) ImageConverterOption {
+	return func(c *ImageConverter) {
+		c.ImageCreatorRoutingNumber = routingNumber
+		c.ImageCreatorDate = date
+	}
+}
+
+// ImageConverter converts JPEG, PNG and TIFF images from a scanner to bitonal CCITT Group 4 TIFF images which meet
+// the TIFF profile of X9.100-181. Color and grayscale images are converted to gray, resampled to DPI, and
+// thresholded to black and white.
+//
+// TIFF images must be uncompressed, PackBits or Group 4 compressed, with 1 or 8 bits per sample. Images of more
+// than 64M pixels are not converted.
+type ImageConverter struct {
+	// DPI is the resolution of converted images, 200 or 240.
+	DPI int
+	// SourceDPI is the resolution of the images to convert. When 0 the resolution the image declares is used,
+	// or DPI when it declares none.
+	SourceDPI float64
+	// Threshold is the gray level below which a pixel is black. When 0 it's chosen for each image.
+	Threshold uint8
+	// ImageCreatorRoutingNumber is the routing number of the institution which created the images.
+	ImageCreatorRoutingNumber string
+	// ImageCreatorDate is the date the images were created.
+	ImageCreatorDate time.Time
+}
+
+// NewImageConverter returns an ImageConverter with the options, converting to 200 DPI when no resolution is set.
+func NewImageConverter(opts ...ImageConverterOption) *ImageConverter {
+	c := &ImageConverter{
+		DPI: 200,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ConvertImage converts JPEG, PNG or TIFF image data to a bitonal CCITT Group 4 TIFF image at DPI.
+func (c *ImageConverter) ConvertImage(data []byte) ([]byte, error) {
+	if c.DPI != 200 && c.DPI != 240 {
+		return nil, &FieldError{FieldName: "ImageData", Value: fmt.Sprint(c.DPI), Msg: fmt.Sprintf(msgConversionDPI, c.DPI)}
+	}
+	gray, xdpi, ydpi, err := decodeGrayImage(data)
+	if err != nil {
+		return nil, err
+	}
+	if c.SourceDPI > 0 {
+		xdpi, ydpi = c.SourceDPI, c.SourceDPI
+	}
+	if xdpi <= 0 || ydpi <= 0 {
+		xdpi, ydpi = float64(c.DPI), float64(c.DPI)
+	}
+	b := gray.Bounds()
+	width := int(math.Round(float64(b.Dx()) * float64(c.DPI) / xdpi))
+	height := int(math.Round(float64(b.Dy()) * float64(c.DPI) / ydpi))
+	if width <= 0 || height <= 0 {
+		return nil, &FieldError{FieldName: "ImageData", Msg: msgConversionEmpty}
+	}
+	if int64(width)*int64(height) > maxImagePixels {
+		return nil, &FieldError{FieldName: "ImageData", Value: fmt.Sprintf("%vx%v", width, height),
+			Msg: fmt.Sprintf(msgImageDimensions, width, height)}
+	}
+	gray = resampleGray(gray, width, height)
+
+	threshold := c.Threshold
+	if threshold == 0 {
+		threshold = otsuThreshold(gray)
+	}
+	for i, p := range gray.Pix {
+		if p < threshold {
+			gray.Pix[i] = 0
+		} else {
+			gray.Pix[i] = 255
+		}
+	}
+	return encodeTIFFG4(width, height, c.DPI, EncodeCCITTG4(gray)), nil
+}
+
+// ImageViews converts the front and back images of an item, and returns an ImageViewDetail and ImageViewData for
+// each, with ViewSideIndicator 0 (front) and 1 (back). The back is left out when it's nil. The ImageViewData are
+// ready to attach once their EceInstitutionItemSequenceNumber and CycleNumber are set, see AttachImageViews.
+func (c *ImageConverter) ImageViews(front, back []byte) ([]ImageViewDetail, []ImageViewData, error) {
+	if c.ImageCreatorRoutingNumber == "" || c.ImageCreatorDate.IsZero() {
+		return nil, nil, &FieldError{FieldName: "ImageCreatorRoutingNumber", Value: c.ImageCreatorRoutingNumber,
+			Msg: msgConversionImageDetail}
+	}
+	var ivDetails []ImageViewDetail
+	var ivDatas []ImageViewData
+	for side, data := range [][]byte{front, back} {
+		if side == 1 && back == nil {
+			break
+		}
+		tiff, err := c.ConvertImage(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		ivDetail := NewImageViewDetail()
+		ivDetail.ImageIndicator = 1
+		ivDetail.ImageCreatorRoutingNumber = c.ImageCreatorRoutingNumber
+		ivDetail.ImageCreatorDate = c.ImageCreatorDate
+		ivDetail.ImageViewFormatIndicator = "00"
+		ivDetail.ImageViewCompressionAlgorithm = "00"
+		ivDetail.ImageViewDataSize = fmt.Sprintf("%07d", len(tiff))
+		ivDetail.ViewSideIndicator = side
+		ivDetail.ViewDescriptor = "00"
+		ivDetail.DigitalSignatureIndicator = 0
+		ivDetail.OverrideIndicator = "0"
+		ivDetails = append(ivDetails, ivDetail)
+
+		ivData := NewImageViewData()
+		ivData.EceInstitutionRoutingNumber = c.ImageCreatorRoutingNumber
+		ivData.BundleBusinessDate = c.ImageCreatorDate
+		ivData.LengthImageReferenceKey = "0000"
+		ivData.LengthDigitalSignature = "00000"
+		ivData.LengthImageData = fmt.Sprintf("%07d", len(tiff))
+		ivData.ImageData = tiff
+		ivDatas = append(ivDatas, ivData)
+	}
+	return ivDetails, ivDatas, nil
+}
+
+// AttachImageViews converts the front and back images of a CheckDetail with ImageViews, and adds the
+// ImageViewDetail and ImageViewData records to it with its EceInstitutionItemSequenceNumber and the cycleNumber
+// of its Bundle.
+func (c *ImageConverter) AttachImageViews(cd *CheckDetail, cycleNumber string, front, back []byte) error {
+	ivDetails, ivDatas, err := c.ImageViews(front, back)
+	if err != nil {
+		return err
+	}
+	for i := range ivDetails {
+		ivDatas[i].EceInstitutionItemSequenceNumber = cd.EceInstitutionItemSequenceNumber
+		ivDatas[i].CycleNumber = cycleNumber
+		cd.AddImageViewDetail(ivDetails[i])
+		cd.AddImageViewData(ivDatas[i])
+	}
+	return nil
+}
+
+// decodeGrayImage decodes JPEG, PNG or TIFF image data into a gray image, and returns the resolution it declares
+// in DPI, or 0 when it declares none. Images of more than maxImagePixels are not decoded.
+func decodeGrayImage(data []byte) (*image.Gray, float64, float64, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		if err := checkImageConfig(jpeg.DecodeConfig(bytes.NewReader(data))); err != nil {
+			return nil, 0, 0, err
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, 0, &FieldError{FieldName: "ImageData", Msg: err.Error()}
+		}
+		xdpi, ydpi := jfifResolution(data)
+		return toGray(img), xdpi, ydpi, nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		if err := checkImageConfig(png.DecodeConfig(bytes.NewReader(data))); err != nil {
+			return nil, 0, 0, err
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, 0, &FieldError{FieldName: "ImageData", Msg: err.Error()}
+		}
+		xdpi, ydpi := pngResolution(data)
+		return toGray(img), xdpi, ydpi, nil
+	case bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")):
+		return decodeGrayTIFF(data)
+	}
+	return nil, 0, 0, &FieldError{FieldName: "ImageData", Msg: msgConversionFormat}
+}
+
+// checkImageConfig returns a FieldError when the JPEG or PNG image of config can't be read or has more than
+// maxImagePixels
+func checkImageConfig(config image.Config, err error) error {
+	if err != nil {
+		return &FieldError{FieldName: "ImageData", Msg: err.Error()}
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return &FieldError{FieldName: "ImageData", Msg: msgConversionEmpty}
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return &FieldError{FieldName: "ImageData", Value: fmt.Sprintf("%vx%v", config.Width, config.Height),
+			Msg: fmt.Sprintf(msgImageDimensions, config.Width, config.Height)}
+	}
+	return nil
+}
+
+// toGray converts an image to gray
+func toGray(img image.Image) *image.Gray {
+	b := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	if ycc, ok := img.(*image.YCbCr); ok {
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				gray.Pix[y*gray.Stride+x] = ycc.Y[ycc.YOffset(b.Min.X+x, b.Min.Y+y)]
+			}
+		}
+		return gray
+	}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			gray.Set(x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return gray
+}
+
+// jfifResolution returns the resolution of a JPEG image's JFIF APP0 segment, in DPI
+func jfifResolution(data []byte) (float64, float64) {
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		segment := data[i+4:]
+		if length < 2 || len(segment) < length-2 {
+			break
+		}
+		segment = segment[:length-2]
+		if marker == 0xe0 && len(segment) >= 12 && bytes.HasPrefix(segment, []byte("JFIF\x00")) {
+			x, y := float64(binary.BigEndian.Uint16(segment[8:10])), float64(binary.BigEndian.Uint16(segment[10:12]))
+			switch segment[7] {
+			case 1:
+				return x, y
+			case 2:
+				return x * 2.54, y * 2.54
+			}
+			return 0, 0
+		}
+		if marker == 0xda {
+			break
+		}
+		i += 2 + length
+	}
+	return 0, 0
+}
+
+// pngResolution returns the resolution of a PNG image's pHYs chunk, in DPI
+func pngResolution(data []byte) (float64, float64) {
+	for i := 8; i+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		if length < 0 || i+12+length > len(data) {
+			break
+		}
+		chunk := data[i+8 : i+8+length]
+		switch string(data[i+4 : i+8]) {
+		case "pHYs":
+			if length == 9 && chunk[8] == 1 {
+				return float64(binary.BigEndian.Uint32(chunk[0:4])) * 0.0254, float64(binary.BigEndian.Uint32(chunk[4:8])) * 0.0254
+			}
+			return 0, 0
+		case "IDAT":
+			return 0, 0
+		}
+		i += 12 + length
+	}
+	return 0, 0
+}
+
+// decodeGrayTIFF decodes an uncompressed, PackBits or Group 4 compressed TIFF image into a gray image
+func decodeGrayTIFF(data []byte) (*image.Gray, float64, float64, error) {
+	info, err := ParseTIFF(data)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	xdpi, ydpi := info.XResolution, info.YResolution
+	if info.ResolutionUnit == 3 {
+		xdpi, ydpi = xdpi*2.54, ydpi*2.54
+	} else if info.ResolutionUnit == 1 {
+		xdpi, ydpi = 0, 0
+	}
+	if info.Width <= 0 || info.Height <= 0 || len(info.StripOffsets) == 0 || len(info.StripOffsets) != len(info.StripByteCounts) {
+		return nil, 0, 0, &FieldError{FieldName: "ImageData", Msg: msgConversionEmpty}
+	}
+	if err := checkTIFFDimensions(info); err != nil {
+		return nil, 0, 0, err
+	}
+	if info.Compression == 4 {
+		img, complete := decodeTIFFG4(data, info)
+		if !complete {
+			return nil, 0, 0, &FieldError{FieldName: "ImageData", Msg: ErrCCITTData.Error()}
+		}
+		return img, xdpi, ydpi, nil
+	}
+	if info.Compression != 1 && info.Compression != 32773 {
+		return nil, 0, 0, &FieldError{FieldName: "ImageData", Value: fmt.Sprint(info.Compression),
+			Msg: fmt.Sprintf(msgConversionTIFF, "Compression", info.Compression)}
+	}
+	if info.BitsPerSample != 1 && info.BitsPerSample != 8 {
+		return nil, 0, 0, &FieldError{FieldName: "ImageData", Value: fmt.Sprint(info.BitsPerSample),
+			Msg: fmt.Sprintf(msgConversionTIFF, "BitsPerSample", info.BitsPerSample)}
+	}
+	samples := info.SamplesPerPixel
+	rowBytes := (info.Width*samples*info.BitsPerSample + 7) / 8
+	if samples < 1 || rowBytes == 0 || (info.BitsPerSample == 1 && samples != 1) {
+		return nil, 0, 0, &FieldError{FieldName: "ImageData", Value: fmt.Sprint(samples),
+			Msg: fmt.Sprintf(msgConversionTIFF, "SamplesPerPixel", samples)}
+	}
+	photometric := info.PhotometricInterpretation
+	if photometric > 2 || (photometric == 2) != (samples >= 3) {
+		return nil, 0, 0, &FieldError{FieldName: "ImageData", Value: fmt.Sprint(photometric),
+			Msg: fmt.Sprintf(msgConversionTIFF, "PhotometricInterpretation", photometric)}
+	}
+
+	var pixels []byte
+	for i, offset := range info.StripOffsets {
+		strip := data[offset : offset+info.StripByteCounts[i]]
+		if info.Compression == 32773 {
+			strip = unpackBits(strip)
+		}
+		pixels = append(pixels, strip...)
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, info.Width, info.Height))
+	for y := 0; y < info.Height && (y+1)*rowBytes <= len(pixels); y++ {
+		row := pixels[y*rowBytes : (y+1)*rowBytes]
+		for x := 0; x < info.Width; x++ {
+			var v byte
+			switch {
+			case info.BitsPerSample == 1:
+				v = (row[x/8] >> (7 - uint(x%8)) & 1) * 255
+			case photometric == 2:
+				r, g, b := int(row[x*samples]), int(row[x*samples+1]), int(row[x*samples+2])
+				v = byte((19595*r + 38470*g + 7471*b + 1<<15) >> 16)
+			default:
+				v = row[x*samples]
+			}
+			if photometric == 0 {
+				v = 255 - v
+			}
+			gray.Pix[y*gray.Stride+x] = v
+		}
+	}
+	return gray, xdpi, ydpi, nil
+}
+
+// unpackBits decompresses PackBits compressed data
+func unpackBits(data []byte) []byte {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(int8(data[i]))
+		i++
+		switch {
+		case n >= 0:
+			end := i + n + 1
+			if end > len(data) {
+				end = len(data)
+			}
+			out = append(out, data[i:end]...)
+			i = end
+		case n != -128 && i < len(data):
+			for j := 0; j < 1-n; j++ {
+				out = append(out, data[i])
+			}
+			i++
+		}
+	}
+	return out
+}
+
+// resampleGray resamples a gray image to width by height pixels, averaging the pixels each pixel covers
+func resampleGray(src *image.Gray, width, height int) *image.Gray {
+	b := src.Bounds()
+	if b.Dx() == width && b.Dy() == height {
+		return src
+	}
+	dst := image.NewGray(image.Rect(0, 0, width, height))
+	sx, sy := float64(b.Dx())/float64(width), float64(b.Dy())/float64(height)
+	span := func(i int, scale float64, limit int) (int, int) {
+		start := int(float64(i) * scale)
+		end := int(math.Ceil(float64(i+1) * scale))
+		if end <= start {
+			end = start + 1
+		}
+		if end > limit {
+			end = limit
+		}
+		return start, end
+	}
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, sy, b.Dy())
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, sx, b.Dx())
+			sum, n := 0, 0
+			for yy := y0; yy < y1; yy++ {
+				row := src.Pix[(b.Min.Y+yy-src.Rect.Min.Y)*src.Stride:]
+				for xx := x0; xx < x1; xx++ {
+					sum += int(row[b.Min.X+xx-src.Rect.Min.X])
+					n++
+				}
+			}
+			dst.Pix[y*dst.Stride+x] = byte(sum / n)
+		}
+	}
+	return dst
+}
+
+// otsuThreshold chooses the gray level which best separates the dark and light pixels of an image
+func otsuThreshold(img *image.Gray) uint8 {
+	var histogram [256]float64
+	total := 0.0
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		for _, p := range img.Pix[y*img.Stride : y*img.Stride+b.Dx()] {
+			histogram[p]++
+			total++
+		}
+	}
+	sum := 0.0
+	for i, n := range histogram {
+		sum += float64(i) * n
+	}
+	threshold, best := 128, -1.0
+	sumDark, dark := 0.0, 0.0
+	for t := 0; t < 255; t++ {
+		dark += histogram[t]
+		sumDark += float64(t) * histogram[t]
+		light := total - dark
+		if dark == 0 || light == 0 {
+			continue
+		}
+		meanDark, meanLight := sumDark/dark, (sum-sumDark)/light
+		if between := dark * light * (meanDark - meanLight) * (meanDark - meanLight); between > best {
+			best, threshold = between, t+1
+		}
+	}
+	return uint8(threshold)
+}
+
+// encodeTIFFG4 creates little-endian TIFF image data of a single strip of Group 4 image data, with the tags of
+// the TIFF profile of X9.100-181.
+func encodeTIFFG4(width, height, dpi int, strip []byte) []byte {
+	type entry struct {
+		tag, typ uint16
+		value    uint32
+	}
+	entries := []entry{
+		{tiffNewSubfileType, 4, 0},
+		{tiffImageWidth, 4, uint32(width)},
+		{tiffImageLength, 4, uint32(height)},
+		{tiffBitsPerSample, 3, 1},
+		{tiffCompression, 3, 4},
+		{tiffPhotometricInterpretation, 3, 0},
+		{tiffFillOrder, 3, 1},
+		{tiffStripOffsets, 4, 8},
+		{tiffSamplesPerPixel, 3, 1},
+		{tiffRowsPerStrip, 4, uint32(height)},
+		{tiffStripByteCounts, 4, uint32(len(strip))},
+		{tiffXResolution, 5, 0},
+		{tiffYResolution, 5, 0},
+		// T6Options
+		{293, 4, 0},
+		{tiffResolutionUnit, 3, 2},
+	}
+	ifd := 8 + len(strip)
+	ifd += ifd % 2
+	rationals := ifd + 2 + len(entries)*12 + 4
+	data := make([]byte, rationals+16)
+	copy(data, "II*\x00")
+	binary.LittleEndian.PutUint32(data[4:8], uint32(ifd))
+	copy(data[8:], strip)
+	binary.LittleEndian.PutUint16(data[ifd:ifd+2], uint16(len(entries)))
+	for i, e := range entries {
+		b := data[ifd+2+i*12 : ifd+14+i*12]
+		binary.LittleEndian.PutUint16(b[0:2], e.tag)
+		binary.LittleEndian.PutUint16(b[2:4], e.typ)
+		binary.LittleEndian.PutUint32(b[4:8], 1)
+		switch e.typ {
+		case 3:
+			binary.LittleEndian.PutUint16(b[8:10], uint16(e.value))
+		case 4:
+			binary.LittleEndian.PutUint32(b[8:12], e.value)
+		case 5:
+			binary.LittleEndian.PutUint32(b[8:12], uint32(rationals))
+			binary.LittleEndian.PutUint32(data[rationals:rationals+4], uint32(dpi))
+			binary.LittleEndian.PutUint32(data[rationals+4:rationals+8], 1)
+			rationals += 8
+		}
+	}
+	return data
+}
@@ -0,0 +1,237 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// mockScannedImage creates a gray 1500 x 750 image of dark lines of text on a light background
+func mockScannedImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 1500, 750))
+	for y := 0; y < 750; y++ {
+		for x := 0; x < 1500; x++ {
+			c := color.Gray{Y: 220}
+			if y%75 >= 60 && y%75 < 66 && x%30 < 20 && x > 150 && x < 1350 {
+				c.Y = 40
+			}
+			img.SetGray(x, y, c)
+		}
+	}
+	return img
+}
+
+// mockPNG encodes an image as PNG with a pHYs chunk of dpi
+func mockPNG(t *testing.T, img image.Image, dpi float64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	chunk := make([]byte, 21)
+	binary.BigEndian.PutUint32(chunk[0:4], 9)
+	copy(chunk[4:8], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:12], uint32(dpi/0.0254+0.5))
+	binary.BigEndian.PutUint32(chunk[12:16], uint32(dpi/0.0254+0.5))
+	chunk[16] = 1
+	binary.BigEndian.PutUint32(chunk[17:21], crc32.ChecksumIEEE(chunk[4:17]))
+	// after the IHDR chunk
+	return append(append(append([]byte(nil), data[:33]...), chunk...), data[33:]...)
+}
+
+// mockJPEG encodes an image as JPEG with a JFIF APP0 segment of dpi
+func mockJPEG(t *testing.T, img image.Image, dpi uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	app0 := []byte("\xff\xe0\x00\x10JFIF\x00\x01\x01\x01\x00\x00\x00\x00\x00\x00")
+	binary.BigEndian.PutUint16(app0[12:14], dpi)
+	binary.BigEndian.PutUint16(app0[14:16], dpi)
+	return append(append([]byte("\xff\xd8"), app0...), data[2:]...)
+}
+
+// TestImageConverter__ConvertImage validates images are converted to X9.100-181 Group 4 TIFF images
+func TestImageConverter__ConvertImage(t *testing.T) {
+	ivDetail, ivData := mockG4ImageView(t)
+	tests := []struct {
+		name          string
+		data          []byte
+		opts          []ImageConverterOption
+		width, height int
+		dpi           float64
+	}{
+		{"png", mockPNG(t, mockScannedImage(), 300), nil, 1000, 500, 200},
+		{"jpeg", mockJPEG(t, mockScannedImage(), 300), []ImageConverterOption{ImageResolutionOption(240)}, 1200, 600, 240},
+		{"source resolution", mockPNG(t, mockScannedImage(), 100), []ImageConverterOption{ImageSourceResolutionOption(300)}, 1000, 500, 200},
+		{"no resolution", mockJPEG(t, mockScannedImage(), 0), nil, 1500, 750, 200},
+		{"tiff", ivData.ImageData, nil, 1200, 550, 200},
+	}
+	for _, test := range tests {
+		tiff, err := NewImageConverter(test.opts...).ConvertImage(test.data)
+		if err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		info, err := ParseTIFF(tiff)
+		if err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		if err := info.ValidateX9Profile(); err != nil {
+			t.Errorf("%s: %T: %s", test.name, err, err)
+		}
+		if info.Width != test.width || info.Height != test.height || info.XResolution != test.dpi {
+			t.Errorf("%s: TIFFInfo: %+v", test.name, info)
+		}
+		strip := tiff[info.StripOffsets[0] : info.StripOffsets[0]+info.StripByteCounts[0]]
+		img, err := DecodeCCITTG4(strip, info.Width, info.Height, false)
+		if err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		black := 0
+		for _, p := range img.Pix {
+			if p == 0 {
+				black++
+			}
+		}
+		if black == 0 || black > len(img.Pix)/5 {
+			t.Errorf("%s: unexpected black pixels: %d", test.name, black)
+		}
+	}
+
+	// an uncompressed grayscale TIFF image
+	gray := mockTIFF(map[uint16]int{tiffImageWidth: 4, tiffImageLength: 4, tiffBitsPerSample: 8, tiffCompression: 1,
+		tiffPhotometricInterpretation: 1, tiffRowsPerStrip: 4})
+	copy(gray[len(gray)-16:], []byte{0, 255, 255, 255, 0, 255, 255, 255, 0, 200, 200, 255, 0, 30, 255, 255})
+	tiff, err := NewImageConverter().ConvertImage(gray)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	info, _ := ParseTIFF(tiff)
+	img, err := DecodeCCITTG4(tiff[info.StripOffsets[0]:info.StripOffsets[0]+info.StripByteCounts[0]], 4, 4, false)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if !bytes.Equal(img.Pix, []byte{0, 255, 255, 255, 0, 255, 255, 255, 0, 255, 255, 255, 0, 0, 255, 255}) {
+		t.Errorf("unexpected pixels: %v", img.Pix)
+	}
+
+	// a Group 4 TIFF image is unchanged
+	tiff, _ = NewImageConverter().ConvertImage(ivData.ImageData)
+	ivDetail.ImageViewCompressionAlgorithm = "00"
+	analysis, err := NewImageAnalyzer().Analyze(&ivDetail, &ImageViewData{ImageData: tiff})
+	if err != nil || analysis.GlobalImageQuality != 2 {
+		t.Errorf("unexpected ImageViewAnalysis: %+v %v", analysis, err)
+	}
+
+	// images which can't be converted
+	for _, data := range [][]byte{nil, []byte("not an image"), []byte("\xff\xd8 truncated"), mockTIFF(map[uint16]int{tiffCompression: 5})} {
+		if _, err := NewImageConverter().ConvertImage(data); err != nil {
+			if e, ok := err.(*FieldError); !ok || e.FieldName != "ImageData" {
+				t.Errorf("%T: %s", err, err)
+			}
+		} else {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+	if _, err := NewImageConverter(ImageResolutionOption(300)).ConvertImage(ivData.ImageData); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestImageConverter__ConvertImageDimensions validates images larger than can be decoded are rejected before
+// decoding
+func TestImageConverter__ConvertImageDimensions(t *testing.T) {
+	// a PNG image which declares 100000 x 100000 pixels
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 100000)
+	ihdr[8] = 8
+	largePNG := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	largePNG = append(largePNG, ihdr...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(largePNG[12:]))
+	largePNG = append(largePNG, crc...)
+
+	for name, data := range map[string][]byte{
+		"g4":           encodeTIFFG4(1<<30, 1<<30, 200, make([]byte, 16)),
+		"g4 rows":      encodeTIFFG4(1000, 1000, 200, make([]byte, 16)),
+		"uncompressed": mockTIFF(map[uint16]int{tiffImageWidth: 65535, tiffImageLength: 65535, tiffBitsPerSample: 8, tiffCompression: 1, tiffPhotometricInterpretation: 1, tiffRowsPerStrip: 65535}),
+		"packbits":     mockTIFF(map[uint16]int{tiffImageWidth: 200, tiffImageLength: 200, tiffBitsPerSample: 8, tiffCompression: 32773, tiffPhotometricInterpretation: 1, tiffRowsPerStrip: 200}),
+		"png":          largePNG,
+		// SamplesPerPixel which don't fit the BitsPerSample
+		"samples zero": mockTIFF(map[uint16]int{tiffImageWidth: 8, tiffImageLength: 2, tiffBitsPerSample: 8, tiffCompression: 1, tiffPhotometricInterpretation: 1, tiffSamplesPerPixel: 0, tiffRowsPerStrip: 2}),
+		"samples bits": mockTIFF(map[uint16]int{tiffImageWidth: 8, tiffImageLength: 2, tiffBitsPerSample: 1, tiffCompression: 1, tiffPhotometricInterpretation: 1, tiffSamplesPerPixel: 3, tiffRowsPerStrip: 2}),
+	} {
+		if _, err := NewImageConverter().ConvertImage(data); err != nil {
+			if e, ok := err.(*FieldError); !ok || e.FieldName != "ImageData" {
+				t.Errorf("%s: %T: %s", name, err, err)
+			}
+		} else {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := NewImageConverter(ImageSourceResolutionOption(0.01)).ConvertImage(mockPNG(t, mockScannedImage(), 300)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestImageConverter__AttachImageViews validates converted images are added to a CheckDetail
+func TestImageConverter__AttachImageViews(t *testing.T) {
+	converter := NewImageConverter(ImageCreatorOption("031300012", time.Now()))
+	cd := mockCheckDetail()
+	front, back := mockPNG(t, mockScannedImage(), 300), mockJPEG(t, mockScannedImage(), 300)
+	if err := converter.AttachImageViews(cd, "01", front, back); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(cd.ImageViewDetail) != 2 || len(cd.ImageViewData) != 2 {
+		t.Fatalf("unexpected images: %d %d", len(cd.ImageViewDetail), len(cd.ImageViewData))
+	}
+	for i := range cd.ImageViewDetail {
+		ivDetail, ivData := &cd.ImageViewDetail[i], &cd.ImageViewData[i]
+		if err := ivDetail.Validate(); err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+		if err := ivData.Validate(); err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+		if _, err := InspectImageView(ivDetail, ivData); err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+		if ivDetail.ViewSideIndicator != i || ivData.EceInstitutionItemSequenceNumber != cd.EceInstitutionItemSequenceNumber ||
+			ivData.CycleNumber != "01" || ivData.parseNumField(ivData.LengthImageData) != len(ivData.ImageData) {
+			t.Errorf("unexpected image view: %+v %+v", ivDetail, ivData)
+		}
+	}
+
+	// the back is optional
+	ivDetails, ivDatas, err := converter.ImageViews(front, nil)
+	if err != nil || len(ivDetails) != 1 || len(ivDatas) != 1 {
+		t.Errorf("unexpected image views: %d %d %v", len(ivDetails), len(ivDatas), err)
+	}
+	if _, _, err := NewImageConverter().ImageViews(front, back); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestUnpackBits validates PackBits data is decompressed
+func TestUnpackBits(t *testing.T) {
+	data := []byte{0xFE, 0xAA, 0x02, 0x80, 0x00, 0x2A, 0xFD, 0xAA, 0x03, 0x80, 0x00, 0x2A, 0x22, 0xF7, 0xAA}
+	expected := []byte{0xAA, 0xAA, 0xAA, 0x80, 0x00, 0x2A, 0xAA, 0xAA, 0xAA, 0xAA, 0x80, 0x00, 0x2A, 0x22,
+		0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA}
+	if got := unpackBits(data); !bytes.Equal(got, expected) {
+		t.Errorf("unpackBits: % X", got)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Errors specific to inspecting image data
+var (
+	msgTIFFHeader          = "is not TIFF image data"
+	msgTIFFTruncated       = "is truncated, %v is beyond the %v bytes of image data"
+	msgTIFFEntry           = "has an invalid entry for tag %v"
+	msgTIFFIFDCount        = "has %v images, X9.100-181 allows a single image"
+	msgTIFFTagMissing      = "is missing TIFF tag %v (%v)"
+	msgTIFFTagValue        = "has %v %v, X9.100-181 requires %v"
+	msgTIFFResolution      = "has resolution %vx%v, X9.100-181 requires 200 or 240 DPI"
+	msgTIFFStrips          = "has %v strips, X9.100-181 requires a single strip"
+	msgTIFFCompression     = "does not match TIFF Compression %v"
+	msgImageDataMissing    = "is missing for ImageIndicator %v"
+	msgImageViewDataDetail = "has no ImageViewDetail to inspect it with"
+)
+
+// TIFF tags read by ParseTIFF
+const (
+	tiffNewSubfileType            = 254
+	tiffImageWidth                = 256
+	tiffImageLength               = 257
+	tiffBitsPerSample             = 258
+	tiffCompression               = 259
+	tiffPhotometricInterpretation = 262
+	tiffFillOrder                 = 266
+	tiffStripOffsets              = 273
+	tiffSamplesPerPixel           = 277
+	tiffRowsPerStrip              = 278
+	tiffStripByteCounts           = 279
+	tiffXResolution               = 282
+	tiffYResolution               = 283
+	tiffResolutionUnit            = 296
+)
+
+// tiffTagNames are the names of the TIFF tags read by ParseTIFF
+var tiffTagNames = map[uint16]string{
+	tiffNewSubfileType:            "NewSubfileType",
+	tiffImageWidth:                "ImageWidth",
+	tiffImageLength:               "ImageLength",
+	tiffBitsPerSample:             "BitsPerSample",
+	tiffCompression:               "Compression",
+	tiffPhotometricInterpretation: "PhotometricInterpretation",
+	tiffFillOrder:                 "FillOrder",
+	tiffStripOffsets:              "StripOffsets",
+	tiffSamplesPerPixel:           "SamplesPerPixel",
+	tiffRowsPerStrip:              "RowsPerStrip",
+	tiffStripByteCounts:           "StripByteCounts",
+	tiffXResolution:               "XResolution",
+	tiffYResolution:               "YResolution",
+	tiffResolutionUnit:            "ResolutionUnit",
+}
+
+// TIFF field types, and their size in bytes
+var tiffTypeSizes = map[uint16]int{
+	1:  1, // BYTE
+	2:  1, // ASCII
+	3:  2, // SHORT
+	4:  4, // LONG
+	5:  8, // RATIONAL
+	6:  1, // SBYTE
+	7:  1, // UNDEFINED
+	8:  2, // SSHORT
+	9:  4, // SLONG
+	10: 8, // SRATIONAL
+	11: 4, // FLOAT
+	12: 8, // DOUBLE
+}
+
+// maxTIFFIFDs limits the number of image file directories followed, so a cycle of IFD offsets ends
+const maxTIFFIFDs = 64
+
+// TIFFInfo is the structure of TIFF image data, read from the tags of its first image file directory (IFD).
+// Tags which are not present have their TIFF default value.
+type TIFFInfo struct {
+	// ByteOrder is II for little-endian or MM for big-endian
+	ByteOrder string
+	// IFDCount is the number of image file directories, one for each image
+	IFDCount int
+	// NewSubfileType is 0 for a full resolution image
+	NewSubfileType int
+	// Width is the number of pixels in each row (ImageWidth)
+	Width int
+	// Height is the number of rows (ImageLength)
+	Height int
+	// BitsPerSample is 1 for a bitonal image
+	BitsPerSample int
+	// SamplesPerPixel is 1 for a bitonal or grayscale image
+	SamplesPerPixel int
+	// Compression is the compression scheme, e.g. 4 for CCITT Group 4 or 7 for JPEG
+	Compression int
+	// PhotometricInterpretation is 0 for WhiteIsZero or 1 for BlackIsZero
+	PhotometricInterpretation int
+	// FillOrder is 1 when the most significant bit of each byte is filled first
+	FillOrder int
+	// XResolution is the number of pixels per ResolutionUnit in each row
+	XResolution float64
+	// YResolution is the number of pixels per ResolutionUnit in each column
+	YResolution float64
+	// ResolutionUnit is 2 for inches or 3 for centimeters
+	ResolutionUnit int
+	// RowsPerStrip is the number of rows in each strip
+	RowsPerStrip int
+	// StripOffsets are the offsets of the strips of image data
+	StripOffsets []int
+	// StripByteCounts are the number of bytes of each strip of image data
+	StripByteCounts []int
+
+	// tags are the tags present in the first IFD
+	tags map[uint16]bool
+}
+
+// tiffReader reads the values of TIFF image data
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ParseTIFF reads the byte order, image file directories (IFDs) and tags of TIFF image data. A FieldError for
+// ImageData is returned when data is not TIFF or its structure is corrupt.
+func ParseTIFF(data []byte) (*TIFFInfo, error) {
+	if len(data) < 8 {
+		return nil, &FieldError{FieldName: "ImageData", Msg: msgTIFFHeader}
+	}
+	r := &tiffReader{data: data}
+	info := &TIFFInfo{ByteOrder: string(data[:2])}
+	switch info.ByteOrder {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, &FieldError{FieldName: "ImageData", Msg: msgTIFFHeader}
+	}
+	if r.order.Uint16(data[2:4]) != 42 {
+		return nil, &FieldError{FieldName: "ImageData", Msg: msgTIFFHeader}
+	}
+
+	// TIFF defaults
+	info.BitsPerSample = 1
+	info.SamplesPerPixel = 1
+	info.Compression = 1
+	info.FillOrder = 1
+	info.ResolutionUnit = 2
+	info.tags = make(map[uint16]bool)
+
+	offset := int(r.order.Uint32(data[4:8]))
+	for offset != 0 {
+		if info.IFDCount == maxTIFFIFDs {
+			return nil, &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFIFDCount, info.IFDCount)}
+		}
+		if offset < 8 || offset+2 > len(data) {
+			return nil, r.truncated(offset)
+		}
+		entries := int(r.order.Uint16(data[offset : offset+2]))
+		end := offset + 2 + entries*12
+		if end+4 > len(data) {
+			return nil, r.truncated(end + 4)
+		}
+		if info.IFDCount == 0 {
+			for i := 0; i < entries; i++ {
+				if err := r.readEntry(info, data[offset+2+i*12:offset+14+i*12]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		info.IFDCount++
+		offset = int(r.order.Uint32(data[end : end+4]))
+	}
+	if info.IFDCount == 0 {
+		return nil, &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFIFDCount, 0)}
+	}
+	for i := range info.StripOffsets {
+		if i < len(info.StripByteCounts) {
+			if end := info.StripOffsets[i] + info.StripByteCounts[i]; end > len(data) {
+				return nil, r.truncated(end)
+			}
+		}
+	}
+	return info, nil
+}
+
+func (r *tiffReader) truncated(offset int) error {
+	return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFTruncated, offset, len(r.data))}
+}
+
+// readEntry reads a 12 byte IFD entry into info when it's a tag read by ParseTIFF
+func (r *tiffReader) readEntry(info *TIFFInfo, entry []byte) error {
+	tag := r.order.Uint16(entry[0:2])
+	if _, ok := tiffTagNames[tag]; !ok {
+		return nil
+	}
+	typ := r.order.Uint16(entry[2:4])
+	count := int(r.order.Uint32(entry[4:8]))
+	size, ok := tiffTypeSizes[typ]
+	if !ok || count <= 0 || count > len(r.data) {
+		return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFEntry, tag)}
+	}
+	value := entry[8:12]
+	if size*count > 4 {
+		offset := int(r.order.Uint32(entry[8:12]))
+		if offset < 0 || offset+size*count > len(r.data) {
+			return r.truncated(offset + size*count)
+		}
+		value = r.data[offset : offset+size*count]
+	}
+	info.tags[tag] = true
+
+	switch tag {
+	case tiffXResolution, tiffYResolution:
+		if typ != 5 {
+			return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFEntry, tag)}
+		}
+		num, den := r.order.Uint32(value[0:4]), r.order.Uint32(value[4:8])
+		var res float64
+		if den != 0 {
+			res = float64(num) / float64(den)
+		}
+		if tag == tiffXResolution {
+			info.XResolution = res
+		} else {
+			info.YResolution = res
+		}
+		return nil
+	}
+
+	var values []int
+	for i := 0; i < count; i++ {
+		switch typ {
+		case 1:
+			values = append(values, int(value[i]))
+		case 3:
+			values = append(values, int(r.order.Uint16(value[i*2:i*2+2])))
+		case 4:
+			values = append(values, int(r.order.Uint32(value[i*4:i*4+4])))
+		default:
+			return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFEntry, tag)}
+		}
+	}
+	switch tag {
+	case tiffNewSubfileType:
+		info.NewSubfileType = values[0]
+	case tiffImageWidth:
+		info.Width = values[0]
+	case tiffImageLength:
+		info.Height = values[0]
+	case tiffBitsPerSample:
+		info.BitsPerSample = values[0]
+	case tiffCompression:
+		info.Compression = values[0]
+	case tiffPhotometricInterpretation:
+		info.PhotometricInterpretation = values[0]
+	case tiffFillOrder:
+		info.FillOrder = values[0]
+	case tiffStripOffsets:
+		info.StripOffsets = values
+	case tiffSamplesPerPixel:
+		if values[0] == 0 {
+			return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFEntry, tag)}
+		}
+		info.SamplesPerPixel = values[0]
+	case tiffRowsPerStrip:
+		info.RowsPerStrip = values[0]
+	case tiffStripByteCounts:
+		info.StripByteCounts = values
+	case tiffResolutionUnit:
+		info.ResolutionUnit = values[0]
+	}
+	return nil
+}
+
+// ValidateX9Profile checks the TIFFInfo of a bitonal CCITT Group 4 image against the TIFF profile of
+// X9.100-181: a single image in a single strip, 1 bit per pixel, WhiteIsZero, at 200 or 240 DPI.
+func (info *TIFFInfo) ValidateX9Profile() error {
+	if info.IFDCount != 1 {
+		return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFIFDCount, info.IFDCount)}
+	}
+	for _, tag := range []uint16{tiffImageWidth, tiffImageLength, tiffCompression, tiffPhotometricInterpretation,
+		tiffStripOffsets, tiffStripByteCounts, tiffXResolution, tiffYResolution} {
+		if !info.tags[tag] {
+			return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFTagMissing, tag, tiffTagNames[tag])}
+		}
+	}
+	for _, v := range []struct {
+		tag      uint16
+		value    int
+		expected int
+	}{
+		{tiffNewSubfileType, info.NewSubfileType, 0},
+		{tiffBitsPerSample, info.BitsPerSample, 1},
+		{tiffSamplesPerPixel, info.SamplesPerPixel, 1},
+		{tiffCompression, info.Compression, 4},
+		{tiffPhotometricInterpretation, info.PhotometricInterpretation, 0},
+		{tiffFillOrder, info.FillOrder, 1},
+		{tiffResolutionUnit, info.ResolutionUnit, 2},
+	} {
+		if v.value != v.expected {
+			return &FieldError{FieldName: "ImageData", Value: fmt.Sprint(v.value),
+				Msg: fmt.Sprintf(msgTIFFTagValue, tiffTagNames[v.tag], v.value, v.expected)}
+		}
+	}
+	if !isX9Resolution(info.XResolution) || !isX9Resolution(info.YResolution) {
+		return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFResolution, info.XResolution, info.YResolution)}
+	}
+	if len(info.StripOffsets) != 1 || len(info.StripByteCounts) != 1 {
+		return &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgTIFFStrips, len(info.StripOffsets))}
+	}
+	return nil
+}
+
+func isX9Resolution(dpi float64) bool {
+	return dpi == 200 || dpi == 240
+}
+
+// imageViewCompressions are the TIFF Compression values of each ImageViewCompressionAlgorithm which can be
+// carried in TIFF
+var imageViewCompressions = map[string][]int{
+	// Group 4 facsimile
+	"00": {4},
+	// JPEG Baseline, old and new style
+	"01": {6, 7},
+	// JBIG
+	"22": {9},
+}
+
+// InspectImageView parses the TIFF ImageData of an ImageViewData and checks it against the ImageViewDetail it
+// belongs to. Image data with an ImageViewFormatIndicator of 00 must be TIFF with a Compression matching the
+// ImageViewCompressionAlgorithm, and Group 4 images (ImageViewCompressionAlgorithm 00) must also meet the TIFF
+// profile of X9.100-181. Images in other formats are not inspected, and nil is returned when the ImageIndicator
+// is 0 (no image).
+func InspectImageView(ivDetail *ImageViewDetail, ivData *ImageViewData) (*TIFFInfo, error) {
+	if ivDetail.ImageIndicator == 0 || ivDetail.ImageViewFormatIndicator != "00" {
+		return nil, nil
+	}
+	data := imageViewBytes(ivData)
+	if len(data) == 0 {
+		return nil, &FieldError{FieldName: "ImageData", Msg: fmt.Sprintf(msgImageDataMissing, ivDetail.ImageIndicator)}
+	}
+	info, err := ParseTIFF(data)
+	if err != nil {
+		return nil, err
+	}
+	if compressions, ok := imageViewCompressions[ivDetail.ImageViewCompressionAlgorithm]; ok {
+		found := false
+		for _, c := range compressions {
+			found = found || c == info.Compression
+		}
+		if !found {
+			return info, &FieldError{FieldName: "ImageViewCompressionAlgorithm", Value: ivDetail.ImageViewCompressionAlgorithm,
+				Msg: fmt.Sprintf(msgTIFFCompression, info.Compression)}
+		}
+	}
+	if ivDetail.ImageViewCompressionAlgorithm == "00" {
+		if err := info.ValidateX9Profile(); err != nil {
+			return info, err
+		}
+	}
+	return info, nil
+}
+
+// imageViewBytes returns the image data of an ImageViewData as it's written, decoding base64 image data
+func imageViewBytes(ivData *ImageViewData) []byte {
+	if decoded, err := ivData.DecodeImageData(); len(decoded) > 0 && err == nil {
+		return decoded
+	}
+	return ivData.ImageData
+}
+
+// ValidateImagesOption inspects the ImageData of every ImageViewData against its ImageViewDetail with
+// InspectImageView when validating a File, reporting corrupt images and images which don't match their detail
+// record.
+func ValidateImagesOption() ValidateOption {
+	return func(o *validateOptions) {
+		o.images = true
+	}
+}
+
+// inspectImageViews inspects each ImageViewData with the ImageViewDetail at the same index
+func inspectImageViews(ivDetail []ImageViewDetail, ivData []ImageViewData) []error {
+	errs := make([]error, len(ivData))
+	for i := range ivData {
+		if i >= len(ivDetail) {
+			errs[i] = &FieldError{FieldName: "ImageViewDetail", Msg: msgImageViewDataDetail}
+			continue
+		}
+		_, errs[i] = InspectImageView(&ivDetail[i], &ivData[i])
+	}
+	return errs
+}
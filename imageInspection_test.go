@@ -0,0 +1,175 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// mockTIFF creates little-endian TIFF image data of a single strip with the tags, which replace the tags of a
+// 200 DPI Group 4 image. A tag with a negative value is left out.
+func mockTIFF(tags map[uint16]int) []byte {
+	values := map[uint16]int{
+		tiffImageWidth: 1200, tiffImageLength: 550, tiffBitsPerSample: 1, tiffCompression: 4,
+		tiffPhotometricInterpretation: 0, tiffStripOffsets: 0, tiffRowsPerStrip: 550, tiffStripByteCounts: 16,
+		tiffXResolution: 200, tiffYResolution: 200, tiffResolutionUnit: 2,
+	}
+	for tag, v := range tags {
+		if v < 0 {
+			delete(values, tag)
+		} else {
+			values[tag] = v
+		}
+	}
+	var order []int
+	for tag := range values {
+		order = append(order, int(tag))
+	}
+	sort.Ints(order)
+
+	ifdEnd := 8 + 2 + len(order)*12 + 4
+	rationals := ifdEnd
+	strip := rationals + 16
+	data := make([]byte, strip+16)
+	copy(data, "II*\x00")
+	binary.LittleEndian.PutUint32(data[4:8], 8)
+	binary.LittleEndian.PutUint16(data[8:10], uint16(len(order)))
+	for i, tag := range order {
+		entry := data[10+i*12 : 22+i*12]
+		binary.LittleEndian.PutUint16(entry[0:2], uint16(tag))
+		binary.LittleEndian.PutUint32(entry[4:8], 1)
+		switch uint16(tag) {
+		case tiffXResolution, tiffYResolution:
+			binary.LittleEndian.PutUint16(entry[2:4], 5)
+			binary.LittleEndian.PutUint32(entry[8:12], uint32(rationals))
+			binary.LittleEndian.PutUint32(data[rationals:rationals+4], uint32(values[uint16(tag)]))
+			binary.LittleEndian.PutUint32(data[rationals+4:rationals+8], 1)
+			rationals += 8
+		case tiffStripOffsets:
+			binary.LittleEndian.PutUint16(entry[2:4], 4)
+			binary.LittleEndian.PutUint32(entry[8:12], uint32(strip))
+		default:
+			binary.LittleEndian.PutUint16(entry[2:4], 3)
+			binary.LittleEndian.PutUint16(entry[8:10], uint16(values[uint16(tag)]))
+		}
+	}
+	return data
+}
+
+// mockInspectedImageView creates an ImageViewDetail and ImageViewData of a Group 4 TIFF image
+func mockInspectedImageView(data []byte) (ImageViewDetail, ImageViewData) {
+	ivDetail := mockImageViewDetail()
+	ivData := mockImageViewData()
+	ivData.ImageData = data
+	return ivDetail, ivData
+}
+
+// TestParseTIFF validates the structure of TIFF image data is read
+func TestParseTIFF(t *testing.T) {
+	info, err := ParseTIFF(mockTIFF(nil))
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if info.ByteOrder != "II" || info.IFDCount != 1 || info.Width != 1200 || info.Height != 550 || info.Compression != 4 ||
+		info.XResolution != 200 || info.YResolution != 200 || len(info.StripOffsets) != 1 || info.StripByteCounts[0] != 16 {
+		t.Errorf("TIFFInfo: %+v", info)
+	}
+	if err := info.ValidateX9Profile(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	// not TIFF or corrupt
+	for _, data := range [][]byte{nil, []byte("\xff\xd8\xff\xe0 JFIF"), []byte("II*\x00\xff\x00\x00\x00"), mockTIFF(nil)[:100],
+		mockTIFF(map[uint16]int{tiffSamplesPerPixel: 0})} {
+		if _, err := ParseTIFF(data); err != nil {
+			if e, ok := err.(*FieldError); !ok || e.FieldName != "ImageData" {
+				t.Errorf("%T: %s", err, err)
+			}
+		} else {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+// TestInspectImageView validates TIFF image data is checked against X9.100-181 and its ImageViewDetail
+func TestInspectImageView(t *testing.T) {
+	tests := []struct {
+		tags      map[uint16]int
+		fieldName string
+	}{
+		{nil, ""},
+		{map[uint16]int{tiffXResolution: 240, tiffYResolution: 240}, ""},
+		{map[uint16]int{tiffCompression: 1}, "ImageViewCompressionAlgorithm"},
+		{map[uint16]int{tiffPhotometricInterpretation: 1}, "ImageData"},
+		{map[uint16]int{tiffBitsPerSample: 8}, "ImageData"},
+		{map[uint16]int{tiffXResolution: 300}, "ImageData"},
+		{map[uint16]int{tiffResolutionUnit: 3}, "ImageData"},
+		{map[uint16]int{tiffImageWidth: -1}, "ImageData"},
+		{map[uint16]int{tiffStripByteCounts: 1000}, "ImageData"},
+	}
+	for i, test := range tests {
+		ivDetail, ivData := mockInspectedImageView(mockTIFF(test.tags))
+		_, err := InspectImageView(&ivDetail, &ivData)
+		if test.fieldName == "" {
+			if err != nil {
+				t.Errorf("test %d: %T: %s", i, err, err)
+			}
+			continue
+		}
+		if e, ok := err.(*FieldError); !ok || e.FieldName != test.fieldName {
+			t.Errorf("test %d: %T: %s", i, err, err)
+		}
+	}
+
+	// images which aren't TIFF, or not present, aren't inspected
+	ivDetail, ivData := mockInspectedImageView([]byte("not an image"))
+	ivDetail.ImageViewFormatIndicator = "20"
+	if _, err := InspectImageView(&ivDetail, &ivData); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	ivDetail, ivData = mockInspectedImageView(nil)
+	ivDetail.ImageIndicator = 0
+	if _, err := InspectImageView(&ivDetail, &ivData); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	ivDetail.ImageIndicator = 1
+	if _, err := InspectImageView(&ivDetail, &ivData); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestFile__ValidateImagesOption validates the images of a File are inspected with ValidateImagesOption
+func TestFile__ValidateImagesOption(t *testing.T) {
+	fd, err := os.Open(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	defer fd.Close()
+	file, err := NewReader(fd, ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := file.Validate(ValidateImagesOption()); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	// a corrupt image is only reported with ValidateImagesOption
+	file.CashLetters[0].Bundles[0].Checks[0].ImageViewData[0].ImageData[0] = 'X'
+	if err := file.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	report := file.ValidationReport(ValidateImagesOption())
+	if len(report.Violations) != 1 || report.Violations[0].Path != "/cashLetters/0/bundles/0/checks/0/imageViewData/0" ||
+		report.Violations[0].FieldName != "ImageData" {
+		t.Errorf("unexpected Violations: %v", report.Violations)
+	}
+	if err := file.Validate(ValidateImagesOption()); err == nil {
+		t.Error("expected error")
+	}
+}
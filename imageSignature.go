@@ -0,0 +1,348 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	_ "crypto/md5" // registers crypto.MD5 for DigitalSignatureMethod 01
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1" // registers crypto.SHA1
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Errors specific to image digital signatures
+var (
+	msgSignatureMethod      = "is not a supported digital signature method"
+	msgSignatureKey         = "%T is not a key for digital signature method %v"
+	msgSignatureIndicator   = "must be 1 to verify a digital signature"
+	msgSignatureLength      = "does not match the length of the DigitalSignature"
+	msgSignatureProtected   = "protected data start %v and length %v exceed the %v bytes of image data"
+	msgSignatureMissing     = "must be present to verify a digital signature"
+	msgSignatureKeyNotFound = "no key found for security originator %q and key name %q"
+	msgSignaturePEM         = "no PEM encoded public key or certificate found"
+)
+
+// ErrImageSignature is returned when a digital signature does not match the protected image data.
+var ErrImageSignature = errors.New("digital signature does not match the protected image data")
+
+// Digital signature methods of ImageViewDetail.DigitalSignatureMethod supported for signing and verifying.
+// 02 (RSA with MDC-2) is not supported.
+const (
+	DigitalSignatureDSASHA1   = "00"
+	DigitalSignatureRSAMD5    = "01"
+	DigitalSignatureRSASHA1   = "03"
+	DigitalSignatureECDSASHA1 = "04"
+)
+
+// dsaSignature is the ASN.1 encoding of a DSA or ECDSA signature
+type dsaSignature struct {
+	R, S *big.Int
+}
+
+// SignImageView signs the image data of an ImageViewData with a *rsa.PrivateKey, *ecdsa.PrivateKey or
+// *dsa.PrivateKey and populates the DigitalSignature and LengthDigitalSignature of the ImageViewData and the
+// DigitalSignatureIndicator and SecurityKeySize of the ImageViewDetail.
+//
+// The DigitalSignatureMethod of the ImageViewDetail selects the algorithm. When it's blank the method for the key
+// is used: 00 (DSA), 03 (RSA with SHA-1) or 04 (ECDSA). ProtectedDataStart and ProtectedDataLength select the
+// bytes of image data which are signed, zero signs all of it. SecurityOriginatorName, SecurityAuthenticatorName
+// and SecurityKeyName are left for the caller to set.
+func SignImageView(ivDetail *ImageViewDetail, ivData *ImageViewData, key crypto.PrivateKey) error {
+	if ivDetail.DigitalSignatureMethod == "" {
+		switch key.(type) {
+		case *dsa.PrivateKey:
+			ivDetail.DigitalSignatureMethod = DigitalSignatureDSASHA1
+		case *rsa.PrivateKey:
+			ivDetail.DigitalSignatureMethod = DigitalSignatureRSASHA1
+		case *ecdsa.PrivateKey:
+			ivDetail.DigitalSignatureMethod = DigitalSignatureECDSASHA1
+		}
+	}
+	hash, err := signatureHash(ivDetail.DigitalSignatureMethod)
+	if err != nil {
+		return err
+	}
+	data, err := protectedImageData(ivDetail, ivData)
+	if err != nil {
+		return err
+	}
+	h := hash.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+
+	var sig []byte
+	var keySize int
+	switch k := key.(type) {
+	case *dsa.PrivateKey:
+		if ivDetail.DigitalSignatureMethod != DigitalSignatureDSASHA1 {
+			return signatureKeyError(ivDetail, key)
+		}
+		r, s, err := dsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return err
+		}
+		if sig, err = asn1.Marshal(dsaSignature{R: r, S: s}); err != nil {
+			return err
+		}
+		keySize = k.P.BitLen()
+	case *rsa.PrivateKey:
+		if ivDetail.DigitalSignatureMethod != DigitalSignatureRSAMD5 && ivDetail.DigitalSignatureMethod != DigitalSignatureRSASHA1 {
+			return signatureKeyError(ivDetail, key)
+		}
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest); err != nil {
+			return err
+		}
+		keySize = k.N.BitLen()
+	case *ecdsa.PrivateKey:
+		if ivDetail.DigitalSignatureMethod != DigitalSignatureECDSASHA1 {
+			return signatureKeyError(ivDetail, key)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return err
+		}
+		if sig, err = asn1.Marshal(dsaSignature{R: r, S: s}); err != nil {
+			return err
+		}
+		keySize = k.Curve.Params().BitSize
+	default:
+		return signatureKeyError(ivDetail, key)
+	}
+
+	ivDetail.DigitalSignatureIndicator = 1
+	ivDetail.SecurityKeySize = keySize
+	ivData.DigitalSignature = sig
+	ivData.LengthDigitalSignature = strconv.Itoa(len(sig))
+	return nil
+}
+
+// VerifyImageView verifies the DigitalSignature of an ImageViewData with a *rsa.PublicKey, *ecdsa.PublicKey or
+// *dsa.PublicKey according to the DigitalSignatureMethod, ProtectedDataStart and ProtectedDataLength of the
+// ImageViewDetail. ErrImageSignature is returned when the signature does not match the image data.
+func VerifyImageView(ivDetail *ImageViewDetail, ivData *ImageViewData, key crypto.PublicKey) error {
+	if ivDetail.DigitalSignatureIndicator != 1 {
+		return &FieldError{FieldName: "DigitalSignatureIndicator", Value: ivDetail.DigitalSignatureIndicatorField(), Msg: msgSignatureIndicator}
+	}
+	if len(ivData.DigitalSignature) == 0 {
+		return &FieldError{FieldName: "DigitalSignature", Msg: msgSignatureMissing}
+	}
+	sig := ivData.DigitalSignature
+	if n := ivData.parseNumField(ivData.LengthDigitalSignature); n != len(sig) {
+		if n <= 0 || n > len(sig) {
+			return &FieldError{FieldName: "LengthDigitalSignature", Value: ivData.LengthDigitalSignature, Msg: msgSignatureLength}
+		}
+		sig = sig[:n]
+	}
+	hash, err := signatureHash(ivDetail.DigitalSignatureMethod)
+	if err != nil {
+		return err
+	}
+	data, err := protectedImageData(ivDetail, ivData)
+	if err != nil {
+		return err
+	}
+	h := hash.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+
+	verified := false
+	switch k := key.(type) {
+	case *dsa.PublicKey:
+		if ivDetail.DigitalSignatureMethod != DigitalSignatureDSASHA1 {
+			return signatureKeyError(ivDetail, key)
+		}
+		var ds dsaSignature
+		if rest, err := asn1.Unmarshal(sig, &ds); err == nil && len(rest) == 0 && ds.R != nil && ds.S != nil {
+			verified = dsa.Verify(k, digest, ds.R, ds.S)
+		}
+	case *rsa.PublicKey:
+		if ivDetail.DigitalSignatureMethod != DigitalSignatureRSAMD5 && ivDetail.DigitalSignatureMethod != DigitalSignatureRSASHA1 {
+			return signatureKeyError(ivDetail, key)
+		}
+		verified = rsa.VerifyPKCS1v15(k, hash, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		if ivDetail.DigitalSignatureMethod != DigitalSignatureECDSASHA1 {
+			return signatureKeyError(ivDetail, key)
+		}
+		var ds dsaSignature
+		if rest, err := asn1.Unmarshal(sig, &ds); err == nil && len(rest) == 0 && ds.R != nil && ds.S != nil {
+			verified = ecdsa.Verify(k, digest, ds.R, ds.S)
+		}
+	default:
+		return signatureKeyError(ivDetail, key)
+	}
+	if !verified {
+		return ErrImageSignature
+	}
+	return nil
+}
+
+// ImageKeyFunc returns the public key to verify the DigitalSignature of an ImageViewData, usually found by its
+// SecurityOriginatorName and SecurityKeyName. A nil key without an error means no key is known for the image.
+type ImageKeyFunc func(ivData *ImageViewData) (crypto.PublicKey, error)
+
+// StaticImageKey returns an ImageKeyFunc which verifies every image with the same public key.
+func StaticImageKey(key crypto.PublicKey) ImageKeyFunc {
+	return func(ivData *ImageViewData) (crypto.PublicKey, error) {
+		return key, nil
+	}
+}
+
+// ParsePublicKeyPEM parses the first PEM encoded public key (PKIX or PKCS #1) or certificate in data, to verify
+// image digital signatures.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New(msgSignaturePEM)
+		}
+		switch block.Type {
+		case "PUBLIC KEY":
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			return x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return cert.PublicKey, nil
+		}
+	}
+}
+
+// ImageSignatureError is an image whose digital signature could not be verified
+type ImageSignatureError struct {
+	// Path is a JSON pointer to the ImageViewData in the File's JSON,
+	// e.g. /cashLetters/0/bundles/1/checks/2/imageViewData/0
+	Path string `json:"path"`
+	// SecurityOriginatorName is the SecurityOriginatorName of the ImageViewData
+	SecurityOriginatorName string `json:"securityOriginatorName,omitempty"`
+	// SecurityKeyName is the SecurityKeyName of the ImageViewData
+	SecurityKeyName string `json:"securityKeyName,omitempty"`
+	// Msg is why the signature could not be verified
+	Msg string `json:"msg"`
+	// Err is the error of verifying the signature
+	Err error `json:"-"`
+}
+
+func (e *ImageSignatureError) Error() string {
+	return fmt.Sprintf("%s %s", e.Path, e.Msg)
+}
+
+// Unwrap returns the error of verifying the signature
+func (e *ImageSignatureError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyImageSignatures verifies the DigitalSignature of every image of the File's CheckDetail and ReturnDetail
+// items whose ImageViewDetail has a DigitalSignatureIndicator of 1, using the key returned by keys for each image.
+// An ImageSignatureError is returned for each image whose signature does not verify, nil when all do.
+func (f *File) VerifyImageSignatures(keys ImageKeyFunc) []*ImageSignatureError {
+	var failures []*ImageSignatureError
+	for i, cl := range f.CashLetters {
+		for j, b := range cl.Bundles {
+			if b == nil {
+				continue
+			}
+			for k, cd := range b.Checks {
+				if cd == nil {
+					continue
+				}
+				path := fmt.Sprintf("/cashLetters/%d/bundles/%d/checks/%d", i, j, k)
+				failures = append(failures, verifyImageViews(path, cd.ImageViewDetail, cd.ImageViewData, keys)...)
+			}
+			for k, rd := range b.Returns {
+				if rd == nil {
+					continue
+				}
+				path := fmt.Sprintf("/cashLetters/%d/bundles/%d/returns/%d", i, j, k)
+				failures = append(failures, verifyImageViews(path, rd.ImageViewDetail, rd.ImageViewData, keys)...)
+			}
+		}
+	}
+	return failures
+}
+
+// verifyImageViews verifies the signed images of an item, each ImageViewData is paired with the ImageViewDetail
+// at the same index.
+func verifyImageViews(path string, ivDetail []ImageViewDetail, ivData []ImageViewData, keys ImageKeyFunc) []*ImageSignatureError {
+	var failures []*ImageSignatureError
+	for i := range ivData {
+		if i >= len(ivDetail) || ivDetail[i].DigitalSignatureIndicator != 1 {
+			continue
+		}
+		data := &ivData[i]
+		err := verifyImageView(&ivDetail[i], data, keys)
+		if err == nil {
+			continue
+		}
+		failures = append(failures, &ImageSignatureError{
+			Path:                   fmt.Sprintf("%s/imageViewData/%d", path, i),
+			SecurityOriginatorName: data.SecurityOriginatorName,
+			SecurityKeyName:        data.SecurityKeyName,
+			Msg:                    err.Error(),
+			Err:                    err,
+		})
+	}
+	return failures
+}
+
+func verifyImageView(ivDetail *ImageViewDetail, ivData *ImageViewData, keys ImageKeyFunc) error {
+	key, err := keys(ivData)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf(msgSignatureKeyNotFound, ivData.SecurityOriginatorName, ivData.SecurityKeyName)
+	}
+	return VerifyImageView(ivDetail, ivData, key)
+}
+
+// signatureHash returns the hash of a DigitalSignatureMethod
+func signatureHash(method string) (crypto.Hash, error) {
+	switch method {
+	case DigitalSignatureRSAMD5:
+		return crypto.MD5, nil
+	case DigitalSignatureDSASHA1, DigitalSignatureRSASHA1, DigitalSignatureECDSASHA1:
+		return crypto.SHA1, nil
+	}
+	return 0, &FieldError{FieldName: "DigitalSignatureMethod", Value: method, Msg: msgSignatureMethod}
+}
+
+func signatureKeyError(ivDetail *ImageViewDetail, key interface{}) error {
+	return &FieldError{FieldName: "DigitalSignatureMethod", Value: ivDetail.DigitalSignatureMethod,
+		Msg: fmt.Sprintf(msgSignatureKey, key, ivDetail.DigitalSignatureMethod)}
+}
+
+// protectedImageData returns the bytes of image data, as they are written, protected by the digital signature.
+// ProtectedDataStart counts the first byte as 1, zero for the first byte. A ProtectedDataLength of zero protects
+// the image data through its last byte.
+func protectedImageData(ivDetail *ImageViewDetail, ivData *ImageViewData) ([]byte, error) {
+	data := []byte(ivData.ImageDataField())
+	start, length := ivDetail.ProtectedDataStart, ivDetail.ProtectedDataLength
+	offset := 0
+	if start > 0 {
+		offset = start - 1
+	}
+	if start < 0 || length < 0 || offset > len(data) || (length > 0 && offset+length > len(data)) {
+		return nil, &FieldError{FieldName: "ProtectedDataLength", Value: ivDetail.ProtectedDataLengthField(),
+			Msg: fmt.Sprintf(msgSignatureProtected, start, length, len(data))}
+	}
+	if length == 0 {
+		return data[offset:], nil
+	}
+	return data[offset : offset+length], nil
+}
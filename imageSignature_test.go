@@ -0,0 +1,219 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+// mockSignedImageView creates an ImageViewDetail and ImageViewData with image data to sign
+func mockSignedImageView() (ImageViewDetail, ImageViewData) {
+	ivDetail := mockImageViewDetail()
+	ivDetail.DigitalSignatureMethod = ""
+	ivData := mockImageViewData()
+	ivData.ImageData = []byte("II*\x00 image data to be signed")
+	ivData.LengthImageData = "0000028"
+	return ivDetail, ivData
+}
+
+func mockDSAKey(t *testing.T) *dsa.PrivateKey {
+	key := &dsa.PrivateKey{}
+	if err := dsa.GenerateParameters(&key.Parameters, rand.Reader, dsa.L1024N160); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := dsa.GenerateKey(key, rand.Reader); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	return key
+}
+
+// TestSignImageView validates images are signed and verified with each kind of key
+func TestSignImageView(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	dsaKey := mockDSAKey(t)
+
+	tests := []struct {
+		method   string
+		key      crypto.PrivateKey
+		public   crypto.PublicKey
+		expected string
+		keySize  int
+	}{
+		{"", rsaKey, &rsaKey.PublicKey, "03", 1024},
+		{"01", rsaKey, &rsaKey.PublicKey, "01", 1024},
+		{"", ecdsaKey, &ecdsaKey.PublicKey, "04", 256},
+		{"", dsaKey, &dsaKey.PublicKey, "00", 1024},
+	}
+	for _, test := range tests {
+		ivDetail, ivData := mockSignedImageView()
+		ivDetail.DigitalSignatureMethod = test.method
+		if err := SignImageView(&ivDetail, &ivData, test.key); err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		if ivDetail.DigitalSignatureIndicator != 1 || ivDetail.DigitalSignatureMethod != test.expected ||
+			ivDetail.SecurityKeySize != test.keySize || len(ivData.DigitalSignature) == 0 {
+			t.Errorf("%T: ImageViewDetail %s", test.key, ivDetail.String())
+		}
+		if err := ivDetail.Validate(); err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+		if err := VerifyImageView(&ivDetail, &ivData, test.public); err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+
+		// a changed image must not verify
+		ivData.ImageData[5] = 'X'
+		if err := VerifyImageView(&ivDetail, &ivData, test.public); !errors.Is(err, ErrImageSignature) {
+			t.Errorf("%T: %s", err, err)
+		}
+	}
+
+	// the key must match the method
+	ivDetail, ivData := mockSignedImageView()
+	ivDetail.DigitalSignatureMethod = "04"
+	if err := SignImageView(&ivDetail, &ivData, rsaKey); err == nil {
+		t.Error("expected error")
+	}
+	ivDetail.DigitalSignatureMethod = "02"
+	if err := SignImageView(&ivDetail, &ivData, rsaKey); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "DigitalSignatureMethod" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestSignImageView__ProtectedData validates only the protected bytes of image data are signed
+func TestSignImageView__ProtectedData(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	ivDetail, ivData := mockSignedImageView()
+	ivDetail.ProtectedDataStart = 5
+	ivDetail.ProtectedDataLength = 10
+	if err := SignImageView(&ivDetail, &ivData, key); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	// bytes outside of the protected data may change
+	ivData.ImageData[0] = 'M'
+	ivData.ImageData[20] = 'X'
+	if err := VerifyImageView(&ivDetail, &ivData, &key.PublicKey); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	ivData.ImageData[4] = 'X'
+	if err := VerifyImageView(&ivDetail, &ivData, &key.PublicKey); !errors.Is(err, ErrImageSignature) {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	ivDetail.ProtectedDataLength = 100
+	if err := VerifyImageView(&ivDetail, &ivData, &key.PublicKey); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "ProtectedDataLength" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestFile__VerifyImageSignatures validates the signatures of a File's images are verified after it's written
+// and read
+func TestFile__VerifyImageSignatures(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	cd := mockCheckDetail()
+	cd.AddendumCount = 0
+	for i := 0; i < 2; i++ {
+		ivDetail, ivData := mockSignedImageView()
+		if err := SignImageView(&ivDetail, &ivData, key); err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		cd.AddImageViewDetail(ivDetail)
+		cd.AddImageViewData(ivData)
+	}
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCheckDetail(cd)
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddBundle(bundle)
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	b := &bytes.Buffer{}
+	if err := NewWriter(b, WriteVariableLineLengthOption()).Write(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	read, err := NewReader(bytes.NewReader(b.Bytes()), ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if failures := read.VerifyImageSignatures(StaticImageKey(&key.PublicKey)); len(failures) != 0 {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+
+	// the changed image is reported
+	ivData := read.CashLetters[0].Bundles[0].Checks[0].ImageViewData
+	ivData[1].ImageData[0] = 'M'
+	failures := read.VerifyImageSignatures(StaticImageKey(&key.PublicKey))
+	if len(failures) != 1 || failures[0].Path != "/cashLetters/0/bundles/0/checks/0/imageViewData/1" ||
+		!errors.Is(failures[0], ErrImageSignature) || failures[0].SecurityKeyName != "SECURE" {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+
+	// every image without a key is reported
+	failures = read.VerifyImageSignatures(StaticImageKey(nil))
+	if len(failures) != 2 {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+}
+
+// TestParsePublicKeyPEM validates public keys are parsed from PEM
+func TestParsePublicKeyPEM(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	public, err := ParsePublicKeyPEM(data)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if pk, ok := public.(*ecdsa.PublicKey); !ok || !pk.Equal(&key.PublicKey) {
+		t.Errorf("unexpected key %T", public)
+	}
+	if _, err := ParsePublicKeyPEM([]byte("not a key")); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -218,6 +218,8 @@ type ImageViewAnalysis struct {
 	validator
 	// converters is composed for ImageCashLetter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewImageViewAnalysis returns a new ImageViewAnalysis with default values for non exported fields
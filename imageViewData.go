@@ -141,6 +141,8 @@ type ImageViewData struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewImageViewData returns a new ImageViewData with default values for non exported fields
@@ -170,6 +170,8 @@ type ImageViewDetail struct {
 	validator
 	// converters is composed for ImageCashLetter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewImageViewDetail returns a new ImageViewDetail with default values for non exported fields
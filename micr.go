@@ -0,0 +1,157 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Errors specific to MICR fields
+var (
+	msgMICRCharacter     = "has %q, which is not a MICR digit, blank, dash or asterisk"
+	msgMICROnUsCharacter = "has %q, which is not a MICR digit, blank, dash, asterisk or On-Us symbol /"
+	msgMICROnUsSymbols   = "has %d On-Us symbols, at most 2 are allowed"
+	msgMICRLength        = "is longer than %d characters"
+)
+
+const (
+	// micrOnUsSymbol is the On-Us symbol of a MICR line, which X9 records as a forward slash
+	micrOnUsSymbol = "/"
+	// micrOnUsLength and micrAuxiliaryOnUsLength are the lengths of the OnUs and AuxiliaryOnUs fields
+	micrOnUsLength          = 20
+	micrAuxiliaryOnUsLength = 15
+)
+
+// MICR is the Auxiliary On-Us and On-Us fields of the MICR line of an item, as recorded in the AuxiliaryOnUs and
+// OnUs fields of a CheckDetail.
+//
+// The On-Us field holds up to three parts separated by On-Us symbols (/). The part right of the last On-Us symbol is
+// the process control field, which holds the serial number of personal checks or a transaction code. With two On-Us
+// symbols the part left of the first is a serial number, and the account number is between them. Without an On-Us
+// symbol the field is only an account number. Blank positions are not part of a value, dashes are kept, and
+// asterisks are MICR characters which couldn't be read.
+type MICR struct {
+	// AuxiliarySerialNumber is the serial number of the Auxiliary On-Us field, used on business checks
+	AuxiliarySerialNumber string `json:"auxiliarySerialNumber,omitempty"`
+	// SerialNumber is the serial number left of the account number in the On-Us field
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// AccountNumber is the account number of the On-Us field, which may include dashes
+	AccountNumber string `json:"accountNumber"`
+	// TransactionCode is the process control field right of the last On-Us symbol, which holds the serial number of
+	// personal checks or a transaction code
+	TransactionCode string `json:"transactionCode,omitempty"`
+	// AccountSymbol is true when the AccountNumber is followed by an On-Us symbol without a TransactionCode
+	AccountSymbol bool `json:"accountSymbol,omitempty"`
+}
+
+// ParseMICR parses the AuxiliaryOnUs and OnUs fields of an item. A FieldError is returned for a field which is too
+// long or has a character which isn't allowed, or for more than two On-Us symbols.
+func ParseMICR(auxiliaryOnUs, onUs string) (*MICR, error) {
+	if err := validateMICR(auxiliaryOnUs, onUs); err != nil {
+		return nil, err
+	}
+	m := &MICR{AuxiliarySerialNumber: micrValue(auxiliaryOnUs)}
+	parts := strings.Split(onUs, micrOnUsSymbol)
+	switch len(parts) {
+	case 1:
+		m.AccountNumber = micrValue(parts[0])
+	case 2:
+		m.AccountNumber, m.TransactionCode = micrValue(parts[0]), micrValue(parts[1])
+	case 3:
+		m.SerialNumber, m.AccountNumber, m.TransactionCode = micrValue(parts[0]), micrValue(parts[1]), micrValue(parts[2])
+	default:
+		return nil, &FieldError{FieldName: "OnUs", Value: onUs, Msg: fmt.Sprintf(msgMICROnUsSymbols, len(parts)-1)}
+	}
+	m.AccountSymbol = len(parts) > 1 && m.TransactionCode == ""
+	return m, nil
+}
+
+// validateMICR returns a FieldError when the AuxiliaryOnUs or OnUs field of an item is too long or has a character
+// which isn't allowed by X9
+func validateMICR(auxiliaryOnUs, onUs string) error {
+	if len(auxiliaryOnUs) > micrAuxiliaryOnUsLength {
+		return &FieldError{FieldName: "AuxiliaryOnUs", Value: auxiliaryOnUs, Msg: fmt.Sprintf(msgMICRLength, micrAuxiliaryOnUsLength)}
+	}
+	if c, ok := micrCharacters(auxiliaryOnUs, false); !ok {
+		return &FieldError{FieldName: "AuxiliaryOnUs", Value: auxiliaryOnUs, Msg: fmt.Sprintf(msgMICRCharacter, c)}
+	}
+	if len(onUs) > micrOnUsLength {
+		return &FieldError{FieldName: "OnUs", Value: onUs, Msg: fmt.Sprintf(msgMICRLength, micrOnUsLength)}
+	}
+	if c, ok := micrCharacters(onUs, true); !ok {
+		return &FieldError{FieldName: "OnUs", Value: onUs, Msg: fmt.Sprintf(msgMICROnUsCharacter, c)}
+	}
+	return nil
+}
+
+// OnUs formats the On-Us field of the MICR
+func (m *MICR) OnUs() string {
+	switch {
+	case m.SerialNumber != "":
+		return m.SerialNumber + micrOnUsSymbol + m.AccountNumber + micrOnUsSymbol + m.TransactionCode
+	case m.TransactionCode != "" || m.AccountSymbol:
+		return m.AccountNumber + micrOnUsSymbol + m.TransactionCode
+	}
+	return m.AccountNumber
+}
+
+// AuxiliaryOnUs formats the Auxiliary On-Us field of the MICR
+func (m *MICR) AuxiliaryOnUs() string {
+	return m.AuxiliarySerialNumber
+}
+
+// CheckNumber returns the serial number of the item: the AuxiliarySerialNumber of business checks, or else the
+// SerialNumber, or else the TransactionCode which holds the serial number of personal checks.
+func (m *MICR) CheckNumber() string {
+	switch {
+	case m.AuxiliarySerialNumber != "":
+		return m.AuxiliarySerialNumber
+	case m.SerialNumber != "":
+		return m.SerialNumber
+	}
+	return m.TransactionCode
+}
+
+// MICR parses the AuxiliaryOnUs and OnUs fields of the CheckDetail, see ParseMICR
+func (cd *CheckDetail) MICR() (*MICR, error) {
+	return ParseMICR(cd.AuxiliaryOnUs, cd.OnUs)
+}
+
+// SetMICR sets the AuxiliaryOnUs and OnUs fields of the CheckDetail to the fields of m, and returns a FieldError
+// when they can't be parsed back, e.g. when they're too long.
+func (cd *CheckDetail) SetMICR(m *MICR) error {
+	auxiliaryOnUs, onUs := m.AuxiliaryOnUs(), m.OnUs()
+	if _, err := ParseMICR(auxiliaryOnUs, onUs); err != nil {
+		return err
+	}
+	cd.AuxiliaryOnUs, cd.OnUs = auxiliaryOnUs, onUs
+	return nil
+}
+
+// MICR parses the OnUs field of the ReturnDetail, see ParseMICR. The AuxiliaryOnUs of a return is in its
+// ReturnDetailAddendumB.
+func (rd *ReturnDetail) MICR() (*MICR, error) {
+	return ParseMICR("", rd.OnUs)
+}
+
+// micrCharacters returns the first character of s which isn't a MICR digit, blank, dash, asterisk, or with onUs an
+// On-Us symbol
+func micrCharacters(s string, onUs bool) (rune, bool) {
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9', c == ' ', c == '-', c == '*':
+		case onUs && string(c) == micrOnUsSymbol:
+		default:
+			return c, false
+		}
+	}
+	return 0, true
+}
+
+// micrValue removes the blank positions of a MICR value
+func micrValue(s string) string {
+	return strings.Replace(s, " ", "", -1)
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"testing"
+)
+
+// TestParseMICR validates On-Us and Auxiliary On-Us fields are parsed and formatted back
+func TestParseMICR(t *testing.T) {
+	for _, test := range []struct {
+		auxiliaryOnUs, onUs, formatted string
+		expected                       MICR
+		checkNumber                    string
+	}{
+		{"", "5558881", "5558881", MICR{AccountNumber: "5558881"}, ""},
+		{"", "1211-1234-56789/", "1211-1234-56789/",
+			MICR{AccountNumber: "1211-1234-56789", AccountSymbol: true}, ""},
+		{"", "  123456789/ 1234", "123456789/1234", MICR{AccountNumber: "123456789", TransactionCode: "1234"}, "1234"},
+		{"", "0012/4567 890/45", "0012/4567890/45",
+			MICR{SerialNumber: "0012", AccountNumber: "4567890", TransactionCode: "45"}, "0012"},
+		{"   001234", "12-345*78/", "12-345*78/",
+			MICR{AuxiliarySerialNumber: "001234", AccountNumber: "12-345*78", AccountSymbol: true}, "001234"},
+		{"", "1-2/3456789/12-3", "1-2/3456789/12-3",
+			MICR{SerialNumber: "1-2", AccountNumber: "3456789", TransactionCode: "12-3"}, "1-2"},
+		{"", "", "", MICR{}, ""},
+	} {
+		m, err := ParseMICR(test.auxiliaryOnUs, test.onUs)
+		if err != nil {
+			t.Errorf("%q: %T: %s", test.onUs, err, err)
+			continue
+		}
+		if *m != test.expected {
+			t.Errorf("%q: unexpected MICR: %#v", test.onUs, m)
+		}
+		if m.OnUs() != test.formatted {
+			t.Errorf("%q: formatted %q", test.onUs, m.OnUs())
+		}
+		if m.AuxiliaryOnUs() != test.expected.AuxiliarySerialNumber {
+			t.Errorf("%q: formatted %q", test.auxiliaryOnUs, m.AuxiliaryOnUs())
+		}
+		if m.CheckNumber() != test.checkNumber {
+			t.Errorf("%q: check number %q", test.onUs, m.CheckNumber())
+		}
+	}
+}
+
+// TestParseMICR__Malformed validates malformed On-Us and Auxiliary On-Us fields return a FieldError
+func TestParseMICR__Malformed(t *testing.T) {
+	for _, test := range []struct {
+		auxiliaryOnUs, onUs, fieldName string
+	}{
+		{"", "12345A789/", "OnUs"},
+		{"", "1/2/3/4", "OnUs"},
+		{"", "123456789012345678901", "OnUs"},
+		{"1234/", "123456789/", "AuxiliaryOnUs"},
+		{"1234567890123456", "", "AuxiliaryOnUs"},
+	} {
+		_, err := ParseMICR(test.auxiliaryOnUs, test.onUs)
+		if e, ok := err.(*FieldError); !ok || e.FieldName != test.fieldName {
+			t.Errorf("%q %q: %T: %s", test.auxiliaryOnUs, test.onUs, err, err)
+		}
+	}
+}
+
+// TestCheckDetail__MICR validates MICR fields with characters X9 doesn't allow are reported by CheckDetail
+// Validate, and MICR fields are set with SetMICR
+func TestCheckDetail__MICR(t *testing.T) {
+	cd := mockCheckDetail()
+	m, err := cd.MICR()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if m.AccountNumber != "5558881" || m.CheckNumber() != "123456789" {
+		t.Errorf("unexpected MICR: %#v", m)
+	}
+	// On-Us fields which can't be parsed are still valid X9 fields
+	cd.OnUs = "5558881/1/2/3"
+	if err := cd.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if _, err := cd.MICR(); err == nil {
+		t.Error("expected error")
+	}
+	cd.OnUs = "5558881A"
+	if err := cd.Validate(); err != nil {
+		if e, ok := err.(*FieldError); ok {
+			if e.FieldName != "OnUs" {
+				t.Errorf("%T: %s", err, err)
+			}
+		}
+	} else {
+		t.Error("expected error")
+	}
+
+	if err := cd.SetMICR(&MICR{AccountNumber: "1211-1234-56789", TransactionCode: "1001"}); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if cd.OnUs != "1211-1234-56789/1001" || cd.AuxiliaryOnUs != "" {
+		t.Errorf("OnUs %q AuxiliaryOnUs %q", cd.OnUs, cd.AuxiliaryOnUs)
+	}
+	if err := cd.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if err := cd.SetMICR(&MICR{AccountNumber: "1211-1234-56789-0000", TransactionCode: "1001"}); err == nil {
+		t.Error("expected error")
+	}
+	if cd.OnUs != "1211-1234-56789/1001" {
+		t.Errorf("OnUs %q", cd.OnUs)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NonHitTotalsDetail(s) follow the AccountTotalsDetail(s) of a CashLetter and precede the first BundleHeader.
+
+// NonHitTotalsDetail Record
+type NonHitTotalsDetail struct {
+	// ID is a client defined string used as a reference to this record.
+	ID string `json:"id"`
+	// RecordType defines the type of record.
+	recordType string
+	// DestinationRoutingNumber identifies the institution that receives the totals.
+	// Format: TTTTAAAAC, where:
+	// TTTT: Federal Reserve Prefix
+	// AAAA: ABA Institution Identifier
+	// C: Payor Bank Routing Number Check Digit
+	DestinationRoutingNumber string `json:"destinationRoutingNumber"`
+	// NonHitIndicator is a code, defined by the clearing arrangement, that identifies the non-hit totals
+	// reported by this record.
+	NonHitIndicator string `json:"nonHitIndicator"`
+	// TotalItemCount identifies the total number of items that did not match a key account.
+	TotalItemCount int `json:"totalItemCount"`
+	// TotalItemAmount identifies the total amount of items that did not match a key account.
+	// All amounts fields have two implied decimal points. e.g., 100000 is $1,000.00
+	TotalItemAmount int `json:"totalItemAmount"`
+	// UserField is a field used at the discretion of users of the standard.
+	UserField string `json:"userField"`
+	// reserved is a field reserved for future use.  Reserved should be blank.
+	reserved string
+	// validator is composed for imagecashletter data validation
+	validator
+	// converters is composed for imagecashletter to golang Converters
+	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
+}
+
+// NewNonHitTotalsDetail returns a new NonHitTotalsDetail with default values for non exported fields
+func NewNonHitTotalsDetail() *NonHitTotalsDetail {
+	nhtd := &NonHitTotalsDetail{}
+	nhtd.setRecordType()
+	return nhtd
+}
+
+func (nhtd *NonHitTotalsDetail) setRecordType() {
+	if nhtd == nil {
+		return
+	}
+	nhtd.recordType = "41"
+	nhtd.reserved = strings.Repeat(" ", 38)
+}
+
+// Parse takes the input record string and parses the NonHitTotalsDetail values
+func (nhtd *NonHitTotalsDetail) Parse(record string) {
+	if utf8.RuneCountInString(record) < 42 {
+		return // line too short
+	}
+	// Character position 1-2, Always "41"
+	nhtd.setRecordType()
+	// 03-11
+	nhtd.DestinationRoutingNumber = nhtd.parseStringField(record[2:11])
+	// 12-12
+	nhtd.NonHitIndicator = nhtd.parseStringField(record[11:12])
+	// 13-24
+	nhtd.TotalItemCount = nhtd.parseNumField(record[12:24])
+	// 25-38
+	nhtd.TotalItemAmount = nhtd.parseNumField(record[24:38])
+	// 39-42
+	nhtd.UserField = nhtd.parseStringField(record[38:42])
+	// 43-80
+	nhtd.reserved = strings.Repeat(" ", 38)
+}
+
+func (nhtd *NonHitTotalsDetail) UnmarshalJSON(data []byte) error {
+	type Alias NonHitTotalsDetail
+	aux := struct {
+		*Alias
+	}{
+		(*Alias)(nhtd),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nhtd.setRecordType()
+	return nil
+}
+
+// String writes the NonHitTotalsDetail struct to a string.
+func (nhtd *NonHitTotalsDetail) String() string {
+	var buf strings.Builder
+	buf.Grow(80)
+	buf.WriteString(nhtd.recordType)
+	buf.WriteString(nhtd.DestinationRoutingNumberField())
+	buf.WriteString(nhtd.NonHitIndicatorField())
+	buf.WriteString(nhtd.TotalItemCountField())
+	buf.WriteString(nhtd.TotalItemAmountField())
+	buf.WriteString(nhtd.UserFieldField())
+	buf.WriteString(nhtd.reservedField())
+	return buf.String()
+}
+
+// Validate performs imagecashletter format rule checks on the record and returns an error if not Validated
+// The first error encountered is returned and stops the parsing.
+func (nhtd *NonHitTotalsDetail) Validate() error {
+	if err := nhtd.fieldInclusion(); err != nil {
+		return err
+	}
+	if nhtd.recordType != "41" {
+		msg := fmt.Sprintf(msgRecordType, 41)
+		return &FieldError{FieldName: "recordType", Value: nhtd.recordType, Msg: msg}
+	}
+	if err := nhtd.isNumeric(nhtd.DestinationRoutingNumber); err != nil {
+		return &FieldError{FieldName: "DestinationRoutingNumber", Value: nhtd.DestinationRoutingNumber, Msg: err.Error()}
+	}
+	if err := nhtd.isAlphanumeric(nhtd.NonHitIndicator); err != nil {
+		return &FieldError{FieldName: "NonHitIndicator", Value: nhtd.NonHitIndicator, Msg: err.Error()}
+	}
+	if err := nhtd.isAlphanumericSpecial(nhtd.UserField); err != nil {
+		return &FieldError{FieldName: "UserField", Value: nhtd.UserField, Msg: err.Error()}
+	}
+	return nil
+}
+
+// fieldInclusion validate mandatory fields are not default values. If fields are
+// invalid the Electronic Exchange will be returned.
+func (nhtd *NonHitTotalsDetail) fieldInclusion() error {
+	if nhtd.recordType == "" {
+		return &FieldError{FieldName: "recordType",
+			Value: nhtd.recordType,
+			Msg:   msgFieldInclusion + ", did you use NonHitTotalsDetail()?"}
+	}
+	if nhtd.DestinationRoutingNumber == "" {
+		return &FieldError{FieldName: "DestinationRoutingNumber",
+			Value: nhtd.DestinationRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use NonHitTotalsDetail()?"}
+	}
+	if nhtd.DestinationRoutingNumberField() == "000000000" {
+		return &FieldError{FieldName: "DestinationRoutingNumber",
+			Value: nhtd.DestinationRoutingNumber,
+			Msg:   msgFieldInclusion + ", did you use NonHitTotalsDetail()?"}
+	}
+	if nhtd.NonHitIndicator == "" {
+		return &FieldError{FieldName: "NonHitIndicator",
+			Value: nhtd.NonHitIndicator,
+			Msg:   msgFieldInclusion + ", did you use NonHitTotalsDetail()?"}
+	}
+	return nil
+}
+
+// DestinationRoutingNumberField gets the DestinationRoutingNumber field
+func (nhtd *NonHitTotalsDetail) DestinationRoutingNumberField() string {
+	return nhtd.stringField(nhtd.DestinationRoutingNumber, 9)
+}
+
+// NonHitIndicatorField gets the NonHitIndicator field
+func (nhtd *NonHitTotalsDetail) NonHitIndicatorField() string {
+	return nhtd.alphaField(nhtd.NonHitIndicator, 1)
+}
+
+// TotalItemCountField gets a string of TotalItemCount zero padded
+func (nhtd *NonHitTotalsDetail) TotalItemCountField() string {
+	return nhtd.numericField(nhtd.TotalItemCount, 12)
+}
+
+// TotalItemAmountField gets a string of TotalItemAmount zero padded
+func (nhtd *NonHitTotalsDetail) TotalItemAmountField() string {
+	return nhtd.numericField(nhtd.TotalItemAmount, 14)
+}
+
+// UserFieldField gets the UserField field
+func (nhtd *NonHitTotalsDetail) UserFieldField() string {
+	return nhtd.alphaField(nhtd.UserField, 4)
+}
+
+// reservedField gets reserved - blank space
+func (nhtd *NonHitTotalsDetail) reservedField() string {
+	return nhtd.alphaField(nhtd.reserved, 38)
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"strings"
+	"testing"
+)
+
+// mockNonHitTotalsDetail creates a NonHitTotalsDetail
+func mockNonHitTotalsDetail() *NonHitTotalsDetail {
+	nhtd := NewNonHitTotalsDetail()
+	nhtd.DestinationRoutingNumber = "231380104"
+	nhtd.NonHitIndicator = "1"
+	nhtd.TotalItemCount = 3
+	nhtd.TotalItemAmount = 300000
+	nhtd.UserField = ""
+	return nhtd
+}
+
+// TestMockNonHitTotalsDetail creates a NonHitTotalsDetail
+func TestMockNonHitTotalsDetail(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	if err := v.Validate(); err != nil {
+		t.Error("mockNonHitTotalsDetail does not validate and will break other tests: ", err)
+	}
+}
+
+func TestNonHitTotalsDetailParseErr(t *testing.T) {
+	var v NonHitTotalsDetail
+	v.Parse("asdlahsakjajf")
+	if v.DestinationRoutingNumber != "" {
+		t.Errorf("v.DestinationRoutingNumber=%s", v.DestinationRoutingNumber)
+	}
+}
+
+// TestParseNonHitTotalsDetail validates parsing a NonHitTotalsDetail
+func TestParseNonHitTotalsDetail(t *testing.T) {
+	var line = "41231380104100000000000300000000300000                                          "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	clh := mockCashLetterHeader()
+	r.addCurrentCashLetter(NewCashLetter(clh))
+	if err := r.parseNonHitTotalsDetail(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	record := r.currentCashLetter.GetNonHitTotalsDetail()[0]
+
+	if record.recordType != "41" {
+		t.Errorf("RecordType Expected '41' got: %v", record.recordType)
+	}
+	if record.DestinationRoutingNumber != "231380104" {
+		t.Errorf("DestinationRoutingNumber Expected '231380104' got: %v", record.DestinationRoutingNumber)
+	}
+	if record.NonHitIndicator != "1" {
+		t.Errorf("NonHitIndicator Expected '1' got: %v", record.NonHitIndicator)
+	}
+	if record.TotalItemCount != 3 {
+		t.Errorf("TotalItemCount Expected '3' got: %v", record.TotalItemCount)
+	}
+	if record.TotalItemAmount != 300000 {
+		t.Errorf("TotalItemAmount Expected '300000' got: %v", record.TotalItemAmount)
+	}
+
+	if record.String() != line {
+		t.Errorf("Strings do not match")
+	}
+}
+
+// TestNonHitTotalsDetailParseOutsideCashLetter validation
+func TestNonHitTotalsDetailParseOutsideCashLetter(t *testing.T) {
+	var line = "41231380104100000000000300000000300000                                          "
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	if err := r.parseNonHitTotalsDetail(); err != nil {
+		if !strings.Contains(err.Error(), msgFileNonHitTotalsDetail) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestNHTDRecordType validation
+func TestNHTDRecordType(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.recordType = "00"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestNHTDDestinationRoutingNumber validation
+func TestNHTDDestinationRoutingNumber(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.DestinationRoutingNumber = "23138010A"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestNHTDNonHitIndicator validation
+func TestNHTDNonHitIndicator(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.NonHitIndicator = "*"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "NonHitIndicator" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestNHTDUserField validation
+func TestNHTDUserField(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.UserField = "®©"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "UserField" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// Field Inclusion
+
+// TestNHTDFIRecordType validation
+func TestNHTDFIRecordType(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.recordType = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestNHTDFIDestinationRoutingNumber validation
+func TestNHTDFIDestinationRoutingNumber(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.DestinationRoutingNumber = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestNHTDFIDestinationRoutingNumberZero validation
+func TestNHTDFIDestinationRoutingNumberZero(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.DestinationRoutingNumber = "000000000"
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "DestinationRoutingNumber" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestNHTDFINonHitIndicator validation
+func TestNHTDFINonHitIndicator(t *testing.T) {
+	v := mockNonHitTotalsDetail()
+	v.NonHitIndicator = ""
+	err := v.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "NonHitIndicator" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// original holds a record as it was read by a Reader with ReadOriginalRecordsOption, so a Writer with
+// WriteOriginalRecordsOption can write the record exactly as it was read.
+type original struct {
+	// raw is the record as it was read, in its original encoding and without framing
+	raw string
+	// ebcdic is set when raw is EBCDIC encoded
+	ebcdic bool
+	// sum is the SHA-256 checksum of the record's String() when it was read
+	sum [sha256.Size]byte
+}
+
+// originalRecord is a record which holds the original it was read from
+type originalRecord interface {
+	fmt.Stringer
+	originalRecord() *original
+}
+
+func (o *original) originalRecord() *original {
+	return o
+}
+
+// RawRecord returns the record exactly as it was read, in its original encoding and including any image data,
+// when it was read by a Reader with ReadOriginalRecordsOption. RawRecord returns nil otherwise.
+func (o *original) RawRecord() []byte {
+	if o.raw == "" {
+		return nil
+	}
+	return []byte(o.raw)
+}
+
+// retainOriginal keeps line as the original of rec, which has just been parsed from line.
+func retainOriginal(rec originalRecord, line string, ebcdic bool) {
+	o := rec.originalRecord()
+	o.raw = line
+	o.ebcdic = ebcdic
+	o.sum = sha256.Sum256([]byte(rec.String()))
+}
+
+// originalLine returns the original of record when record was read in the ebcdic encoding and has not been
+// changed since it was read.
+func originalLine(record fmt.Stringer, ebcdic bool) (string, bool) {
+	rec, ok := record.(originalRecord)
+	if !ok {
+		return "", false
+	}
+	o := rec.originalRecord()
+	if o.raw == "" || o.ebcdic != ebcdic {
+		return "", false
+	}
+	if sha256.Sum256([]byte(rec.String())) != o.sum {
+		return "", false
+	}
+	return o.raw, true
+}
@@ -13,6 +13,7 @@ import (
 	"strconv"
 
 	"github.com/gdamore/encoding"
+	"github.com/moov-io/base"
 )
 
 // ParseError is returned for parsing reader errors.
@@ -46,8 +47,35 @@ type Reader struct {
 	lineNum int
 	// recordName holds the current record name being parsed.
 	recordName string
+	// lenient is set by ReadLenientOption to keep reading past recoverable errors
+	lenient bool
+	// errors holds the errors collected while reading leniently
+	errors base.ErrorList
+	// profile is set by ReadValidationProfileOption to validate records with a ValidationProfile
+	profile *ValidationProfile
+	// originalRecords is set by ReadOriginalRecordsOption to keep each record as it was read
+	originalRecords bool
+	// ebcdic is set by ReadEbcdicEncodingOption when lines are EBCDIC encoded
+	ebcdic bool
+	// maxRecordLength is the length of the largest record which can be read, set by ReadMaxRecordLengthOption
+	maxRecordLength int
+	// framing is how records are separated, set by ReadVariableLineLengthOption
+	framing Framing
+	// detectFormat is set by ReadDetectFormatOption to detect the format of input
+	detectFormat bool
+	// input is read by the scanner, once its format is detected with ReadDetectFormatOption
+	input io.Reader
+	// format is the FileFormat detected with ReadDetectFormatOption
+	format *FileFormat
 }
 
+// DefaultMaxRecordLength is the length of the largest record read by default, an ImageViewData record with the
+// largest image reference key, digital signature and image data its length fields allow.
+const DefaultMaxRecordLength = 117 + 9999 + 99999 + 9999999
+
+// errRecordTooLong is returned when splitting a record with a length prefix larger than maxRecordLength
+var errRecordTooLong = errors.New("record too long")
+
 // error creates a new ParseError based on err.
 func (r *Reader) error(err error) error {
 	return &ParseError{
@@ -57,6 +85,27 @@ func (r *Reader) error(err error) error {
 	}
 }
 
+// recordError returns a ParseError for err, which was returned validating a record. When reading leniently the
+// ParseError is collected and nil is returned so the record is kept and reading continues.
+func (r *Reader) recordError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if r.lenient {
+		r.errors.Add(r.error(err))
+		return nil
+	}
+	return r.error(err)
+}
+
+// retain keeps the current line as the original of rec, which has just been parsed from it, when reading with
+// ReadOriginalRecordsOption.
+func (r *Reader) retain(rec originalRecord) {
+	if r.originalRecords {
+		retainOriginal(rec, r.line, r.ebcdic)
+	}
+}
+
 // addCurrentCashLetter creates the current cash letter for the file being read. A successful
 // currentCashLetter will be added to r.File once parsed.
 func (r *Reader) addCurrentCashLetter(cashLetter CashLetter) {
@@ -80,16 +129,40 @@ func NewReader(r io.Reader, opts ...ReaderOption) *Reader {
 	f := NewFile()
 	f.Control = FileControl{}
 	reader := &Reader{
-		File:       *f,
-		scanner:    bufio.NewScanner(r),
-		decodeLine: Passthrough,
+		File:            *f,
+		scanner:         bufio.NewScanner(r),
+		decodeLine:      Passthrough,
+		maxRecordLength: DefaultMaxRecordLength,
+		framing:         FramingLF,
+		input:           r,
 	}
 	for _, opt := range opts {
 		opt(reader)
 	}
+	reader.setScannerBuffer()
 	return reader
 }
 
+// setScannerBuffer sets the buffer of the scanner to hold a record of maxRecordLength and its length prefix or
+// line ending. The buffer grows as needed, and the scanner reads up to the larger of its maximum and the buffer's
+// capacity, so the buffer starts no larger than the maximum.
+func (r *Reader) setScannerBuffer() {
+	size := 64 * 1024
+	if r.maxRecordLength+4 < size {
+		size = r.maxRecordLength + 4
+	}
+	r.scanner.Buffer(make([]byte, 0, size), r.maxRecordLength+4)
+}
+
+// scanError returns a ParseError for an error scanning the next record
+func (r *Reader) scanError(scanErr error) error {
+	if scanErr == bufio.ErrTooLong || scanErr == errRecordTooLong {
+		msg := fmt.Sprintf(msgRecordMaxLength, r.maxRecordLength)
+		return r.error(&FileError{FieldName: "RecordLength", Value: strconv.Itoa(r.lineNum + 1), Msg: msg})
+	}
+	return r.error(&FileError{FieldName: "LineNumber", Value: strconv.Itoa(r.lineNum), Msg: scanErr.Error()})
+}
+
 // DecodeLineFn is used to decode a scanned line into desired encoding.
 // Depending on X9 spec, cashletter could be encoded as ASCII or EBCDIC
 type DecodeLineFn func(lineIn string) (lineOut string)
@@ -113,8 +186,12 @@ type ReaderOption func(*Reader)
 
 //ReadVariableLineLengthOption allows Reader to split imagecashletter files based on encoded line lengths
 func ReadVariableLineLengthOption() ReaderOption {
+	var reader *Reader
 	scanVariableLengthLines := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if len(data) < 4 && atEOF {
+		if len(data) == 0 && atEOF {
+			// all lines have been read
+			return 0, nil, nil
+		} else if len(data) < 4 && atEOF {
 			// we ran out of bytes and we're at the end of the file
 			return 0, nil, io.ErrUnexpectedEOF
 		} else if len(data) < 4 {
@@ -125,6 +202,10 @@ func ReadVariableLineLengthOption() ReaderOption {
 		// use the 4 control bytes at the beginning of a line to determine its length
 		ctrl := data[0:4]
 		dataLen := int(binary.BigEndian.Uint32(ctrl))
+		if dataLen > reader.maxRecordLength {
+			// don't wait for the bytes of a length which can't be read
+			return 0, nil, errRecordTooLong
+		}
 		lineLen := 4 + dataLen
 		if lineLen <= len(data) {
 			// return line while accounting for control bytes
@@ -138,6 +219,8 @@ func ReadVariableLineLengthOption() ReaderOption {
 	}
 
 	return func(r *Reader) {
+		reader = r
+		r.framing = FramingLengthPrefix
 		r.scanner.Split(scanVariableLengthLines)
 	}
 }
@@ -146,6 +229,42 @@ func ReadVariableLineLengthOption() ReaderOption {
 func ReadEbcdicEncodingOption() ReaderOption {
 	return func(r *Reader) {
 		r.decodeLine = DecodeEBCDIC
+		r.ebcdic = true
+	}
+}
+
+// ReadLenientOption allows Reader to keep reading past recoverable errors. Records which fail validation are kept,
+// and records which are out of place or can't be parsed are skipped. Read returns the File read along with a
+// base.ErrorList of every ParseError found. NextRecord returns a record which failed validation along with a
+// base.ErrorList of its ParseErrors.
+func ReadLenientOption() ReaderOption {
+	return func(r *Reader) {
+		r.lenient = true
+	}
+}
+
+// ReadOriginalRecordsOption keeps each record exactly as it was read, in its original encoding, so a Writer with
+// WriteOriginalRecordsOption writes the records which are unchanged byte for byte. RawRecord returns the record
+// as it was read.
+func ReadOriginalRecordsOption() ReaderOption {
+	return func(r *Reader) {
+		r.originalRecords = true
+	}
+}
+
+// ReadMaxRecordLengthOption sets the length of the largest record which can be read, DefaultMaxRecordLength by
+// default. A record which is longer, or whose length prefix is larger with ReadVariableLineLengthOption, is
+// returned as a FileError for RecordLength without reading it.
+func ReadMaxRecordLengthOption(length int) ReaderOption {
+	return func(r *Reader) {
+		r.maxRecordLength = length
+	}
+}
+
+// ReadValidationProfileOption validates each record read with the checks and field rules of profile
+func ReadValidationProfileOption(profile *ValidationProfile) ReaderOption {
+	return func(r *Reader) {
+		r.profile = profile
 	}
 }
 
@@ -154,11 +273,13 @@ func ReadEbcdicEncodingOption() ReaderOption {
 // the appropriate error if issues are found.
 func (r *Reader) Read() (File, error) {
 	r.lineNum = 0
+	if _, err := r.Format(); err != nil {
+		return r.File, err
+	}
 	// read through the entire file
 	for r.scanner.Scan() {
 		if scanErr := r.scanner.Err(); scanErr != nil {
-			err := &FileError{FieldName: "LineNumber", Value: strconv.Itoa(r.lineNum), Msg: scanErr.Error()}
-			return r.File, r.error(err)
+			return r.File, r.scanError(scanErr)
 		}
 
 		r.line = r.scanner.Text()
@@ -166,28 +287,336 @@ func (r *Reader) Read() (File, error) {
 
 		lineLength := len(r.line)
 
-		if lineLength < 80 {
+		if lineLength < 80 && !r.isUserRecord() {
 			msg := fmt.Sprintf(msgRecordLength, lineLength)
 			err := &FileError{FieldName: "RecordLength", Value: strconv.Itoa(lineLength), Msg: msg}
-			return r.File, r.error(err)
+			if err := r.recordError(err); err != nil {
+				return r.File, err
+			}
+			continue
 		}
 		if err := r.parseLine(); err != nil {
-			return r.File, err
+			if !r.lenient {
+				return r.File, err
+			}
+			// the record is skipped
+			r.errors.Add(err)
+		}
+	}
+	if scanErr := r.scanner.Err(); scanErr != nil && !r.lenient {
+		return r.File, r.scanError(scanErr)
+	}
+	if r.lenient {
+		if scanErr := r.scanner.Err(); scanErr != nil {
+			// the rest of the file can't be read
+			r.errors.Add(r.scanError(scanErr))
+		}
+		if r.currentCashLetter.CashLetterHeader != nil {
+			// keep the CashLetter which was not closed by a CashLetterControl
+			r.recordName = "CashLetterControl"
+			r.errors.Add(r.error(&FileError{Msg: msgFileCashLetterNoControl}))
+			if b := r.currentCashLetter.currentBundle; b != nil && b.BundleHeader != nil {
+				r.currentCashLetter.AddBundle(b)
+			}
+			r.File.AddCashLetter(r.currentCashLetter)
+			r.currentCashLetter = CashLetter{}
 		}
 	}
 	if (FileHeader{}) == r.File.Header {
 		// There must be at least one File Header
 		r.recordName = "FileHeader"
-		return r.File, r.error(&FileError{Msg: msgFileHeader})
+		if err := r.recordError(&FileError{Msg: msgFileHeader}); err != nil {
+			return r.File, err
+		}
 	}
 	if (FileControl{}) == r.File.Control {
 		// There must be at least one File Control
 		r.recordName = "FileControl"
-		return r.File, r.error(&FileError{Msg: msgFileControl})
+		if err := r.recordError(&FileError{Msg: msgFileControl}); err != nil {
+			return r.File, err
+		}
+	}
+	if !r.errors.Empty() {
+		return r.File, r.errors
 	}
 	return r.File, nil
 }
 
+// Record is a single record read by NextRecord along with its location in the file.
+type Record struct {
+	// Line is the line number of the record, the first line is 1.
+	Line int
+	// Name is the name of the record type, e.g. CheckDetail
+	Name string
+	// Record is the parsed record
+	Record FileRecord
+	// UserRecord is the parsed User Record when the record is a User Record. Record is nil for User Records
+	// of a type registered with RegisterUserRecord which is not a FileRecord.
+	UserRecord UserRecord
+	// CashLetterHeader is the header of the CashLetter enclosing the record, if any.
+	CashLetterHeader *CashLetterHeader
+	// BundleHeader is the header of the Bundle enclosing the record, if any.
+	BundleHeader *BundleHeader
+}
+
+// NextRecord reads and parses the next record of the imagecashletter file. Records are validated and
+// checked for placement the same way Read does, but they are not retained once returned so large files
+// can be processed in constant memory. Addenda and image views are returned as their own records, and
+// Reader.File is not populated beyond the FileHeader and FileControl.
+//
+// The addenda of each CheckDetail and ReturnDetail are validated as the next item is read, rather than when
+// the BundleControl is read, and the ReturnDetail of a Bundle which also has CheckDetail are validated too.
+// As with Read, the control totals of Bundles, CashLetters and the File and the totals of RoutingNumberSummary
+// records are not validated. File.ValidationReport and ValidateRoutingNumberSummaryOption validate them and need
+// the complete File read by Read.
+//
+// NextRecord returns io.EOF once every record has been read. NextRecord and Read should not be used on
+// the same Reader.
+func (r *Reader) NextRecord() (*Record, error) {
+	if _, err := r.Format(); err != nil {
+		return nil, err
+	}
+	if !r.scanner.Scan() {
+		if scanErr := r.scanner.Err(); scanErr != nil {
+			return nil, r.scanError(scanErr)
+		}
+		if (FileHeader{}) == r.File.Header {
+			// There must be at least one File Header
+			r.recordName = "FileHeader"
+			return nil, r.error(&FileError{Msg: msgFileHeader})
+		}
+		if (FileControl{}) == r.File.Control {
+			// There must be at least one File Control
+			r.recordName = "FileControl"
+			return nil, r.error(&FileError{Msg: msgFileControl})
+		}
+		return nil, io.EOF
+	}
+
+	r.line = r.scanner.Text()
+	r.lineNum++
+
+	lineLength := len(r.line)
+	if lineLength < 80 && !r.isUserRecord() {
+		msg := fmt.Sprintf(msgRecordLength, lineLength)
+		err := &FileError{FieldName: "RecordLength", Value: strconv.Itoa(lineLength), Msg: msg}
+		return nil, r.error(err)
+	}
+
+	switch r.line[:2] {
+	case checkDetailPos, checkDetailEbcPos, returnDetailPos, returnDetailEbcPos:
+		// The previous item and all of its addenda have been read, so it can be released. Credits are released
+		// with the items, so the Bundle holds its last item when its BundleControl is read.
+		if err := r.releaseItems(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.parseLine(); err != nil {
+		return nil, err
+	}
+
+	rec := &Record{
+		Line:             r.lineNum,
+		Name:             r.recordName,
+		CashLetterHeader: r.currentCashLetter.CashLetterHeader,
+	}
+	if r.currentCashLetter.currentBundle != nil {
+		rec.BundleHeader = r.currentCashLetter.currentBundle.BundleHeader
+	}
+	r.lastRecord(rec)
+	if rec.Record == nil && rec.UserRecord == nil {
+		// Items outside of a bundle are dropped by Read, but NextRecord has nothing to return for them
+		return nil, r.error(&FileError{Msg: msgFileBundleOutside})
+	}
+	r.releaseRecords()
+	if !r.errors.Empty() {
+		errs := r.errors
+		r.errors = nil
+		return rec, errs
+	}
+	return rec, nil
+}
+
+// lastRecord sets rec.Record to the record parseLine just added to the Reader. rec.Record is left nil
+// when the record was not added.
+func (r *Reader) lastRecord(rec *Record) {
+	cl := &r.currentCashLetter
+	b := cl.currentBundle
+
+	switch r.line[:2] {
+	case fileHeaderPos, fileHeaderEbcPos:
+		rec.Record = &r.File.Header
+	case cashLetterHeaderPos, cashLetterHeaderEbcPos:
+		rec.Record = cl.CashLetterHeader
+	case bundleHeaderPos, bundleHeaderEbcPos:
+		rec.Record = b.BundleHeader
+	case creditPos, creditEbcPos:
+		if len(b.Credits) > 0 {
+			rec.Record = b.Credits[len(b.Credits)-1]
+		}
+	case checkDetailPos, checkDetailEbcPos:
+		if len(b.Checks) > 0 {
+			rec.Record = b.Checks[len(b.Checks)-1]
+		}
+	case checkDetailAddendumAPos, checkDetailAddendumAEbcPos:
+		cd := b.Checks[len(b.Checks)-1]
+		rec.Record = &cd.CheckDetailAddendumA[len(cd.CheckDetailAddendumA)-1]
+	case checkDetailAddendumBPos, checkDetailAddendumBEbcPos:
+		cd := b.Checks[len(b.Checks)-1]
+		rec.Record = &cd.CheckDetailAddendumB[len(cd.CheckDetailAddendumB)-1]
+	case checkDetailAddendumCPos, checkDetailAddendumCEbcPos:
+		cd := b.Checks[len(b.Checks)-1]
+		rec.Record = &cd.CheckDetailAddendumC[len(cd.CheckDetailAddendumC)-1]
+	case returnDetailPos, returnDetailEbcPos:
+		if len(b.Returns) > 0 {
+			rec.Record = b.Returns[len(b.Returns)-1]
+		}
+	case returnAddendumAPos, returnAddendumAPEbcos:
+		rd := b.Returns[len(b.Returns)-1]
+		rec.Record = &rd.ReturnDetailAddendumA[len(rd.ReturnDetailAddendumA)-1]
+	case returnAddendumBPos, returnAddendumBEbcPos:
+		rd := b.Returns[len(b.Returns)-1]
+		rec.Record = &rd.ReturnDetailAddendumB[len(rd.ReturnDetailAddendumB)-1]
+	case returnAddendumCPos, returnAddendumCEbcPos:
+		rd := b.Returns[len(b.Returns)-1]
+		rec.Record = &rd.ReturnDetailAddendumC[len(rd.ReturnDetailAddendumC)-1]
+	case returnAddendumDPos, returnAddendumDEbcPos:
+		rd := b.Returns[len(b.Returns)-1]
+		rec.Record = &rd.ReturnDetailAddendumD[len(rd.ReturnDetailAddendumD)-1]
+	case imageViewDetailPos, imageViewDetailEbcPos:
+		if cd := lastCheck(b); cd != nil {
+			rec.Record = &cd.ImageViewDetail[len(cd.ImageViewDetail)-1]
+		} else {
+			rd := b.Returns[len(b.Returns)-1]
+			rec.Record = &rd.ImageViewDetail[len(rd.ImageViewDetail)-1]
+		}
+	case imageViewDataPos, imageViewDataEbcPos:
+		if cd := lastCheck(b); cd != nil {
+			rec.Record = &cd.ImageViewData[len(cd.ImageViewData)-1]
+		} else {
+			rd := b.Returns[len(b.Returns)-1]
+			rec.Record = &rd.ImageViewData[len(rd.ImageViewData)-1]
+		}
+	case imageViewAnalysisPos, imageViewAnalysisEbcPos:
+		if cd := lastCheck(b); cd != nil {
+			rec.Record = &cd.ImageViewAnalysis[len(cd.ImageViewAnalysis)-1]
+		} else {
+			rd := b.Returns[len(b.Returns)-1]
+			rec.Record = &rd.ImageViewAnalysis[len(rd.ImageViewAnalysis)-1]
+		}
+	case creditItemPos, creditItemEbcPos:
+		rec.Record = cl.CreditItems[len(cl.CreditItems)-1]
+	case accountTotalsDetailPos, accountTotalsDetailEbcPos:
+		rec.Record = cl.AccountTotalsDetail[len(cl.AccountTotalsDetail)-1]
+	case nonHitTotalsDetailPos, nonHitTotalsDetailEbcPos:
+		rec.Record = cl.NonHitTotalsDetail[len(cl.NonHitTotalsDetail)-1]
+	case userRecordPos, userRecordEbcPos:
+		urs, _, _ := r.currentUserRecords()
+		rec.UserRecord = (*urs)[len(*urs)-1]
+		if fr, ok := rec.UserRecord.(FileRecord); ok {
+			rec.Record = fr
+		}
+	case bundleControlPos, bundleControlEbcPos:
+		// parseLine has moved the Bundle into the CashLetter
+		bundle := cl.Bundles[len(cl.Bundles)-1]
+		rec.Record = bundle.BundleControl
+		rec.BundleHeader = bundle.BundleHeader
+	case boxSummaryPos, boxSummaryEbcPos:
+		rec.Record = cl.BoxSummary[len(cl.BoxSummary)-1]
+	case routingNumberSummaryPos, routingNumberSummaryEbcPos:
+		rec.Record = cl.RoutingNumberSummary[len(cl.RoutingNumberSummary)-1]
+	case cashLetterControlPos, cashLetterControlEbcPos:
+		// parseLine has moved the CashLetter into the File
+		cashLetter := r.File.CashLetters[len(r.File.CashLetters)-1]
+		rec.Record = cashLetter.CashLetterControl
+		rec.CashLetterHeader = cashLetter.CashLetterHeader
+	case fileControlPos, fileControlEbcPos:
+		rec.Record = &r.File.Control
+	}
+}
+
+// lastCheck returns the CheckDetail image views are currently added to, following the same
+// precedence as the ImageView* parsers.
+func lastCheck(b *Bundle) *CheckDetail {
+	if b.GetChecks() == nil {
+		return nil
+	}
+	return b.Checks[len(b.Checks)-1]
+}
+
+// releaseItems validates and releases the items of the current bundle read by NextRecord.
+func (r *Reader) releaseItems() error {
+	b := r.currentCashLetter.currentBundle
+	if b == nil {
+		return nil
+	}
+	if len(b.Checks) > 0 || len(b.Returns) > 0 {
+		if err := b.Validate(); err != nil {
+			r.recordName = "Bundles"
+			if err := r.recordError(err); err != nil {
+				return err
+			}
+		}
+	}
+	b.Credits = nil
+	b.Checks = nil
+	b.Returns = nil
+	return nil
+}
+
+// releaseRecords releases completed Bundles, CashLetters and cash letter level records read by
+// NextRecord. The slices are emptied rather than set to nil so the presence checks made by
+// CashLetter.Validate still apply.
+func (r *Reader) releaseRecords() {
+	cl := &r.currentCashLetter
+	for i := range cl.Bundles {
+		cl.Bundles[i] = nil
+	}
+	if cl.Bundles != nil {
+		cl.Bundles = cl.Bundles[:0]
+	}
+	for i := range cl.CreditItems {
+		cl.CreditItems[i] = nil
+	}
+	if cl.CreditItems != nil {
+		cl.CreditItems = cl.CreditItems[:0]
+	}
+	for i := range cl.AccountTotalsDetail {
+		cl.AccountTotalsDetail[i] = nil
+	}
+	if cl.AccountTotalsDetail != nil {
+		cl.AccountTotalsDetail = cl.AccountTotalsDetail[:0]
+	}
+	for i := range cl.NonHitTotalsDetail {
+		cl.NonHitTotalsDetail[i] = nil
+	}
+	if cl.NonHitTotalsDetail != nil {
+		cl.NonHitTotalsDetail = cl.NonHitTotalsDetail[:0]
+	}
+	for i := range cl.UserRecords {
+		cl.UserRecords[i] = nil
+	}
+	if cl.UserRecords != nil {
+		cl.UserRecords = cl.UserRecords[:0]
+	}
+	if cl.UserRecordPositions != nil {
+		cl.UserRecordPositions = cl.UserRecordPositions[:0]
+	}
+	for i := range cl.BoxSummary {
+		cl.BoxSummary[i] = nil
+	}
+	if cl.BoxSummary != nil {
+		cl.BoxSummary = cl.BoxSummary[:0]
+	}
+	for i := range cl.RoutingNumberSummary {
+		cl.RoutingNumberSummary[i] = nil
+	}
+	if cl.RoutingNumberSummary != nil {
+		cl.RoutingNumberSummary = cl.RoutingNumberSummary[:0]
+	}
+	r.File.CashLetters = nil
+}
+
 func (r *Reader) parseLine() error {
 	switch r.line[:2] {
 	case fileHeaderPos, fileHeaderEbcPos:
@@ -202,6 +631,10 @@ func (r *Reader) parseLine() error {
 		if err := r.parseBundleHeader(); err != nil {
 			return err
 		}
+	case creditPos, creditEbcPos:
+		if err := r.parseCredit(); err != nil {
+			return err
+		}
 	case checkDetailPos, checkDetailEbcPos:
 		if err := r.parseCheckDetail(); err != nil {
 			return err
@@ -254,6 +687,18 @@ func (r *Reader) parseLine() error {
 		if err := r.parseCreditItem(); err != nil {
 			return err
 		}
+	case accountTotalsDetailPos, accountTotalsDetailEbcPos:
+		if err := r.parseAccountTotalsDetail(); err != nil {
+			return err
+		}
+	case nonHitTotalsDetailPos, nonHitTotalsDetailEbcPos:
+		if err := r.parseNonHitTotalsDetail(); err != nil {
+			return err
+		}
+	case userRecordPos, userRecordEbcPos:
+		if err := r.parseUserRecord(); err != nil {
+			return err
+		}
 	case bundleControlPos, bundleControlEbcPos:
 		if err := r.parseBundleControl(); err != nil {
 			return err
@@ -265,11 +710,17 @@ func (r *Reader) parseLine() error {
 		if r.currentCashLetter.currentBundle != nil {
 			if err := r.currentCashLetter.currentBundle.Validate(); err != nil {
 				r.recordName = "Bundles"
-				return r.error(err)
+				if err := r.recordError(err); err != nil {
+					return err
+				}
 			}
 			r.currentCashLetter.AddBundle(r.currentCashLetter.currentBundle)
 			r.currentCashLetter.currentBundle = new(Bundle)
 		}
+	case boxSummaryPos, boxSummaryEbcPos:
+		if err := r.parseBoxSummary(); err != nil {
+			return err
+		}
 	case routingNumberSummaryPos, routingNumberSummaryEbcPos:
 		if err := r.parseRoutingNumberSummary(); err != nil {
 			return err
@@ -288,7 +739,9 @@ func (r *Reader) parseLine() error {
 		}
 		if err := r.currentCashLetter.Validate(); err != nil {
 			r.recordName = "CashLetters"
-			return r.error(err)
+			if err := r.recordError(err); err != nil {
+				return err
+			}
 		}
 		r.File.AddCashLetter(r.currentCashLetter)
 		r.currentCashLetter = CashLetter{}
@@ -311,9 +764,10 @@ func (r *Reader) parseFileHeader() error {
 		r.error(&FileError{Msg: msgFileHeader})
 	}
 	r.File.Header.Parse(r.decodeLine(r.line))
+	r.retain(&r.File.Header)
 	// Ensure valid FileHeader
-	if err := r.File.Header.Validate(); err != nil {
-		return r.error(err)
+	if err := r.recordError(r.profile.validate(&r.File.Header)); err != nil {
+		return err
 	}
 	return nil
 }
@@ -327,9 +781,10 @@ func (r *Reader) parseCashLetterHeader() error {
 	}
 	clh := NewCashLetterHeader()
 	clh.Parse(r.decodeLine(r.line))
+	r.retain(clh)
 	// Ensure we have a valid CashLetterHeader
-	if err := clh.Validate(); err != nil {
-		return r.error(err)
+	if err := r.recordError(r.profile.validate(clh)); err != nil {
+		return err
 	}
 	// Passing CashLetterHeader into NewCashLetter creates a CashLetter
 	cl := NewCashLetter(clh)
@@ -349,8 +804,9 @@ func (r *Reader) parseBundleHeader() error {
 	// Ensure we have a valid bundle header before building a bundle.
 	bh := NewBundleHeader()
 	bh.Parse(r.decodeLine(r.line))
-	if err := bh.Validate(); err != nil {
-		return r.error(err)
+	r.retain(bh)
+	if err := r.recordError(r.profile.validate(bh)); err != nil {
+		return err
 	}
 	// Passing BundleHeader into NewBundle creates a Bundle
 	bundle := NewBundle(bh)
@@ -359,6 +815,22 @@ func (r *Reader) parseBundleHeader() error {
 
 }
 
+// parseCredit takes the input record string and parses the Credit values
+func (r *Reader) parseCredit() error {
+	r.recordName = "Credit"
+	if r.currentCashLetter.currentBundle == nil || r.currentCashLetter.currentBundle.BundleHeader == nil {
+		return r.error(&FileError{Msg: msgFileBundleOutside})
+	}
+	cr := NewCredit()
+	cr.Parse(r.decodeLine(r.line))
+	r.retain(cr)
+	if err := r.recordError(r.profile.validate(cr)); err != nil {
+		return err
+	}
+	r.currentCashLetter.currentBundle.AddCredit(cr)
+	return nil
+}
+
 // parseCheckDetail takes the input record string and parses the CheckDetail values
 func (r *Reader) parseCheckDetail() error {
 	r.recordName = "CheckDetail"
@@ -367,9 +839,10 @@ func (r *Reader) parseCheckDetail() error {
 	}
 	cd := new(CheckDetail)
 	cd.Parse(r.decodeLine(r.line))
+	r.retain(cd)
 	// Ensure valid CheckDetail
-	if err := cd.Validate(); err != nil {
-		return r.error(err)
+	if err := r.recordError(r.profile.validate(cd)); err != nil {
+		return err
 	}
 	// Add CheckDetail
 	if r.currentCashLetter.currentBundle.BundleHeader != nil {
@@ -387,8 +860,9 @@ func (r *Reader) parseCheckDetailAddendumA() error {
 	}
 	cdAddendumA := NewCheckDetailAddendumA()
 	cdAddendumA.Parse(r.decodeLine(r.line))
-	if err := cdAddendumA.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&cdAddendumA)
+	if err := r.recordError(r.profile.validate(&cdAddendumA)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetChecks()) - 1
 	//r.currentCashLetter.currentBundle.Checks[entryIndex].CheckDetailAddendumA = cdAddendumA
@@ -405,8 +879,9 @@ func (r *Reader) parseCheckDetailAddendumB() error {
 	}
 	cdAddendumB := NewCheckDetailAddendumB()
 	cdAddendumB.Parse(r.decodeLine(r.line))
-	if err := cdAddendumB.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&cdAddendumB)
+	if err := r.recordError(r.profile.validate(&cdAddendumB)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetChecks()) - 1
 	r.currentCashLetter.currentBundle.Checks[entryIndex].AddCheckDetailAddendumB(cdAddendumB)
@@ -422,8 +897,9 @@ func (r *Reader) parseCheckDetailAddendumC() error {
 	}
 	cdAddendumC := NewCheckDetailAddendumC()
 	cdAddendumC.Parse(r.decodeLine(r.line))
-	if err := cdAddendumC.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&cdAddendumC)
+	if err := r.recordError(r.profile.validate(&cdAddendumC)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetChecks()) - 1
 	r.currentCashLetter.currentBundle.Checks[entryIndex].AddCheckDetailAddendumC(cdAddendumC)
@@ -438,8 +914,9 @@ func (r *Reader) parseReturnDetail() error {
 	}
 	rd := new(ReturnDetail)
 	rd.Parse(r.decodeLine(r.line))
-	if err := rd.Validate(); err != nil {
-		return r.error(err)
+	r.retain(rd)
+	if err := r.recordError(r.profile.validate(rd)); err != nil {
+		return err
 	}
 	if r.currentCashLetter.currentBundle.BundleHeader != nil {
 		r.currentCashLetter.currentBundle.AddReturnDetail(rd)
@@ -456,8 +933,9 @@ func (r *Reader) parseReturnDetailAddendumA() error {
 	}
 	rdAddendumA := NewReturnDetailAddendumA()
 	rdAddendumA.Parse(r.decodeLine(r.line))
-	if err := rdAddendumA.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&rdAddendumA)
+	if err := r.recordError(r.profile.validate(&rdAddendumA)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 	//r.currentCashLetter.currentBundle.Returns[entryIndex].ReturnDetailAddendumA = rdAddendumA
@@ -474,8 +952,9 @@ func (r *Reader) parseReturnDetailAddendumB() error {
 	}
 	rdAddendumB := NewReturnDetailAddendumB()
 	rdAddendumB.Parse(r.decodeLine(r.line))
-	if err := rdAddendumB.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&rdAddendumB)
+	if err := r.recordError(r.profile.validate(&rdAddendumB)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 	r.currentCashLetter.currentBundle.Returns[entryIndex].AddReturnDetailAddendumB(rdAddendumB)
@@ -491,8 +970,9 @@ func (r *Reader) parseReturnDetailAddendumC() error {
 	}
 	rdAddendumC := NewReturnDetailAddendumC()
 	rdAddendumC.Parse(r.decodeLine(r.line))
-	if err := rdAddendumC.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&rdAddendumC)
+	if err := r.recordError(r.profile.validate(&rdAddendumC)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 	r.currentCashLetter.currentBundle.Returns[entryIndex].AddReturnDetailAddendumC(rdAddendumC)
@@ -509,8 +989,9 @@ func (r *Reader) parseReturnDetailAddendumD() error {
 	}
 	rdAddendumD := NewReturnDetailAddendumD()
 	rdAddendumD.Parse(r.decodeLine(r.line))
-	if err := rdAddendumD.Validate(); err != nil {
-		return r.error(err)
+	r.retain(&rdAddendumD)
+	if err := r.recordError(r.profile.validate(&rdAddendumD)); err != nil {
+		return err
 	}
 	entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 	r.currentCashLetter.currentBundle.Returns[entryIndex].AddReturnDetailAddendumD(rdAddendumD)
@@ -531,8 +1012,9 @@ func (r *Reader) ImageViewDetail() error {
 	if r.currentCashLetter.currentBundle.GetChecks() != nil {
 		ivDetail := NewImageViewDetail()
 		ivDetail.Parse(r.decodeLine(r.line))
-		if err := ivDetail.Validate(); err != nil {
-			return r.error(err)
+		r.retain(&ivDetail)
+		if err := r.recordError(r.profile.validate(&ivDetail)); err != nil {
+			return err
 		}
 		entryIndex := len(r.currentCashLetter.currentBundle.GetChecks()) - 1
 		r.currentCashLetter.currentBundle.Checks[entryIndex].AddImageViewDetail(ivDetail)
@@ -540,8 +1022,9 @@ func (r *Reader) ImageViewDetail() error {
 	} else if r.currentCashLetter.currentBundle.GetReturns() != nil {
 		ivDetail := NewImageViewDetail()
 		ivDetail.Parse(r.decodeLine(r.line))
-		if err := ivDetail.Validate(); err != nil {
-			return r.error(err)
+		r.retain(&ivDetail)
+		if err := r.recordError(r.profile.validate(&ivDetail)); err != nil {
+			return err
 		}
 		entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 		r.currentCashLetter.currentBundle.Returns[entryIndex].AddImageViewDetail(ivDetail)
@@ -567,8 +1050,9 @@ func (r *Reader) ImageViewData() error {
 	if r.currentCashLetter.currentBundle.GetChecks() != nil {
 		ivData := NewImageViewData()
 		ivData.ParseAndDecode(r.line, r.decodeLine)
-		if err := ivData.Validate(); err != nil {
-			return r.error(err)
+		r.retain(&ivData)
+		if err := r.recordError(r.profile.validate(&ivData)); err != nil {
+			return err
 		}
 		entryIndex := len(r.currentCashLetter.currentBundle.GetChecks()) - 1
 		r.currentCashLetter.currentBundle.Checks[entryIndex].AddImageViewData(ivData)
@@ -576,8 +1060,9 @@ func (r *Reader) ImageViewData() error {
 	} else if r.currentCashLetter.currentBundle.GetReturns() != nil {
 		ivData := NewImageViewData()
 		ivData.ParseAndDecode(r.line, r.decodeLine)
-		if err := ivData.Validate(); err != nil {
-			return r.error(err)
+		r.retain(&ivData)
+		if err := r.recordError(r.profile.validate(&ivData)); err != nil {
+			return err
 		}
 		entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 		r.currentCashLetter.currentBundle.Returns[entryIndex].AddImageViewData(ivData)
@@ -603,8 +1088,9 @@ func (r *Reader) ImageViewAnalysis() error {
 	if r.currentCashLetter.currentBundle.GetChecks() != nil {
 		ivAnalysis := NewImageViewAnalysis()
 		ivAnalysis.Parse(r.decodeLine(r.line))
-		if err := ivAnalysis.Validate(); err != nil {
-			return r.error(err)
+		r.retain(&ivAnalysis)
+		if err := r.recordError(r.profile.validate(&ivAnalysis)); err != nil {
+			return err
 		}
 		entryIndex := len(r.currentCashLetter.currentBundle.GetChecks()) - 1
 		r.currentCashLetter.currentBundle.Checks[entryIndex].AddImageViewAnalysis(ivAnalysis)
@@ -612,8 +1098,9 @@ func (r *Reader) ImageViewAnalysis() error {
 	} else if r.currentCashLetter.currentBundle.GetReturns() != nil {
 		ivAnalysis := NewImageViewAnalysis()
 		ivAnalysis.Parse(r.decodeLine(r.line))
-		if err := ivAnalysis.Validate(); err != nil {
-			return r.error(err)
+		r.retain(&ivAnalysis)
+		if err := r.recordError(r.profile.validate(&ivAnalysis)); err != nil {
+			return err
 		}
 		entryIndex := len(r.currentCashLetter.currentBundle.GetReturns()) - 1
 		r.currentCashLetter.currentBundle.Returns[entryIndex].AddImageViewAnalysis(ivAnalysis)
@@ -634,13 +1121,98 @@ func (r *Reader) parseCreditItem() error {
 	}
 	ci := new(CreditItem)
 	ci.Parse(r.decodeLine(r.line))
-	if err := ci.Validate(); err != nil {
-		return r.error(err)
+	r.retain(ci)
+	if err := r.recordError(r.profile.validate(ci)); err != nil {
+		return err
 	}
 	r.currentCashLetter.AddCreditItem(ci)
 	return nil
 }
 
+// parseAccountTotalsDetail takes the input record string and parses the AccountTotalsDetail values
+func (r *Reader) parseAccountTotalsDetail() error {
+	r.recordName = "AccountTotalsDetail"
+	if r.currentCashLetter.CashLetterHeader == nil {
+		return r.error(&FileError{Msg: msgFileAccountTotalsDetail})
+	}
+	atd := NewAccountTotalsDetail()
+	atd.Parse(r.decodeLine(r.line))
+	r.retain(atd)
+	if err := r.recordError(r.profile.validate(atd)); err != nil {
+		return err
+	}
+	r.currentCashLetter.AddAccountTotalsDetail(atd)
+	return nil
+}
+
+// parseNonHitTotalsDetail takes the input record string and parses the NonHitTotalsDetail values
+func (r *Reader) parseNonHitTotalsDetail() error {
+	r.recordName = "NonHitTotalsDetail"
+	if r.currentCashLetter.CashLetterHeader == nil {
+		return r.error(&FileError{Msg: msgFileNonHitTotalsDetail})
+	}
+	nhtd := NewNonHitTotalsDetail()
+	nhtd.Parse(r.decodeLine(r.line))
+	r.retain(nhtd)
+	if err := r.recordError(r.profile.validate(nhtd)); err != nil {
+		return err
+	}
+	r.currentCashLetter.AddNonHitTotalsDetail(nhtd)
+	return nil
+}
+
+// parseUserRecord takes the input record string and parses the UserRecord values. The UserRecord is added
+// to the item, Bundle or CashLetter it follows.
+func (r *Reader) parseUserRecord() error {
+	r.recordName = "UserRecord"
+	if r.currentCashLetter.CashLetterHeader == nil {
+		return r.error(&FileError{Msg: msgFileUserRecord})
+	}
+	ur := userRecordFor(r.decodeLine(r.line))
+	if rec, ok := ur.(originalRecord); ok {
+		r.retain(rec)
+	}
+	if err := r.recordError(r.profile.validate(ur)); err != nil {
+		return err
+	}
+	urs, positions, position := r.currentUserRecords()
+	*urs = append(*urs, ur)
+	if positions != nil {
+		*positions = append(*positions, position)
+	}
+	return nil
+}
+
+// currentUserRecords returns the UserRecords of the record a User Record read now follows: the last item of
+// the current Bundle, the current Bundle when it has no items or the current CashLetter outside of a Bundle.
+// The UserRecordPositions of the item or CashLetter and the position of the User Record are also returned,
+// Bundle User Records have no position.
+func (r *Reader) currentUserRecords() (*UserRecords, *[]int, int) {
+	cl := &r.currentCashLetter
+	b := cl.currentBundle
+	switch {
+	case b == nil || b.BundleHeader == nil:
+		return &cl.UserRecords, &cl.UserRecordPositions, len(cl.Bundles)
+	case lastCheck(b) != nil:
+		cd := lastCheck(b)
+		return &cd.UserRecords, &cd.UserRecordPositions, cd.itemRecordCount()
+	case len(b.Returns) > 0:
+		rd := b.Returns[len(b.Returns)-1]
+		return &rd.UserRecords, &rd.UserRecordPositions, rd.itemRecordCount()
+	default:
+		return &b.UserRecords, nil, 0
+	}
+}
+
+// isUserRecord reports if the current line is a User Record. User Records are variable length and can be
+// shorter than other records.
+func (r *Reader) isUserRecord() bool {
+	if len(r.line) < 2 {
+		return false
+	}
+	return r.line[:2] == userRecordPos || r.line[:2] == userRecordEbcPos
+}
+
 // parseBundleControl takes the input record string and parses the BundleControl values
 func (r *Reader) parseBundleControl() error {
 	r.recordName = "BundleControl"
@@ -649,12 +1221,29 @@ func (r *Reader) parseBundleControl() error {
 		return r.error(&FileError{Msg: msgFileBundleControl})
 	}
 	r.currentCashLetter.currentBundle.GetControl().Parse(r.decodeLine(r.line))
-	if err := r.currentCashLetter.currentBundle.GetControl().Validate(); err != nil {
-		return r.error(err)
+	r.retain(r.currentCashLetter.currentBundle.GetControl())
+	if err := r.recordError(r.profile.validate(r.currentCashLetter.currentBundle.GetControl())); err != nil {
+		return err
 	}
 	return nil
 }
 
+// parseBoxSummary takes the input record string and parses the BoxSummary values
+func (r *Reader) parseBoxSummary() error {
+	r.recordName = "BoxSummary"
+	if r.currentCashLetter.CashLetterHeader == nil {
+		return r.error(&FileError{Msg: msgFileBoxSummary})
+	}
+	bs := NewBoxSummary()
+	bs.Parse(r.decodeLine(r.line))
+	r.retain(bs)
+	if err := r.recordError(r.profile.validate(bs)); err != nil {
+		return err
+	}
+	r.currentCashLetter.AddBoxSummary(bs)
+	return nil
+}
+
 // parseRoutingNumberSummary takes the input record string and parses the RoutingNumberSummary values
 func (r *Reader) parseRoutingNumberSummary() error {
 	r.recordName = "RoutingNumberSummary"
@@ -664,9 +1253,11 @@ func (r *Reader) parseRoutingNumberSummary() error {
 
 	rns := NewRoutingNumberSummary()
 	rns.Parse(r.decodeLine(r.line))
-	if err := rns.Validate(); err != nil {
-		return r.error(err)
+	r.retain(rns)
+	if err := r.recordError(r.profile.validate(rns)); err != nil {
+		return err
 	}
+	r.addCurrentRoutingNumberSummary(rns)
 	return nil
 }
 
@@ -678,9 +1269,10 @@ func (r *Reader) parseCashLetterControl(collectionTypeIndicator string) error {
 		return r.error(&FileError{Msg: msgFileCashLetterControl})
 	}
 	r.currentCashLetter.GetControl().Parse(r.decodeLine(r.line))
+	r.retain(r.currentCashLetter.GetControl())
 	// Ensure valid CashLetterControl
-	if err := r.currentCashLetter.GetControl().Validate(collectionTypeIndicator); err != nil {
-		return r.error(err)
+	if err := r.recordError(r.profile.validateCashLetterControl(r.currentCashLetter.GetControl(), collectionTypeIndicator)); err != nil {
+		return err
 	}
 	return nil
 }
@@ -693,9 +1285,10 @@ func (r *Reader) parseFileControl() error {
 		return r.error(&FileError{Msg: msgFileControl})
 	}
 	r.File.Control.Parse(r.decodeLine(r.line))
+	r.retain(&r.File.Control)
 	// Ensure valid FileControl
-	if err := r.File.Control.Validate(); err != nil {
-		return r.error(err)
+	if err := r.recordError(r.profile.validate(&r.File.Control)); err != nil {
+		return err
 	}
 	return nil
 }
@@ -7,11 +7,15 @@ package imagecashletter
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/moov-io/base"
 )
 
 // TestICLFileRead validates reading an ICL file
@@ -1081,3 +1085,307 @@ func TestICLBase64ImageData(t *testing.T) {
 		t.Fatalf("unexpected ICL file:\n%s", buf.String())
 	}
 }
+
+// TestReader__NextRecord validates reading an ICL file one record at a time
+func TestReader__NextRecord(t *testing.T) {
+	testNextRecord := func(t *testing.T, path string, opts ...ReaderOption) {
+		fd, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Can not open local file: %s: \n", err)
+		}
+		defer fd.Close()
+
+		r := NewReader(fd, opts...)
+		count, checks := 0, 0
+		for {
+			rec, err := r.NextRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			count++
+			if rec.Line != count {
+				t.Errorf("line %d, expected %d", rec.Line, count)
+			}
+			switch v := rec.Record.(type) {
+			case *CheckDetail:
+				checks++
+				if rec.CashLetterHeader == nil || rec.BundleHeader == nil {
+					t.Errorf("line %d: missing CheckDetail context", rec.Line)
+				}
+				if v.ItemAmount == 0 {
+					t.Errorf("line %d: CheckDetail not parsed", rec.Line)
+				}
+			case *BundleControl:
+				if rec.BundleHeader == nil {
+					t.Errorf("line %d: missing BundleControl context", rec.Line)
+				}
+			case *FileControl:
+				if rec.CashLetterHeader != nil || rec.BundleHeader != nil {
+					t.Errorf("line %d: unexpected FileControl context", rec.Line)
+				}
+			}
+		}
+		if count != r.File.Control.TotalRecordCount {
+			t.Errorf("read %d records, FileControl has %d", count, r.File.Control.TotalRecordCount)
+		}
+		if checks != r.File.Control.TotalItemCount {
+			t.Errorf("read %d checks, FileControl has %d", checks, r.File.Control.TotalItemCount)
+		}
+		if len(r.File.CashLetters) != 0 {
+			t.Errorf("NextRecord retained %d CashLetters", len(r.File.CashLetters))
+		}
+	}
+
+	t.Run("ascii", func(t *testing.T) {
+		testNextRecord(t, filepath.Join("test", "testdata", "valid-ascii.x937"), ReadVariableLineLengthOption())
+	})
+	t.Run("ebcdic", func(t *testing.T) {
+		testNextRecord(t, filepath.Join("test", "testdata", "valid-ebcdic.x937"), ReadVariableLineLengthOption(), ReadEbcdicEncodingOption())
+	})
+}
+
+// TestReader__NextRecordErr validates NextRecord returns parsing errors
+func TestReader__NextRecordErr(t *testing.T) {
+	line := "1735T231380104121042882201809051523NCitadel           Wells Fargo        US     "
+	r := NewReader(strings.NewReader(line))
+	if _, err := r.NextRecord(); err == nil {
+		t.Error("expected error")
+	}
+
+	r = NewReader(strings.NewReader(""))
+	if _, err := r.NextRecord(); err == nil || err == io.EOF {
+		t.Errorf("expected missing FileHeader error: %v", err)
+	}
+}
+
+// TestReader__NextRecordValidation validates NextRecord rejects the files Read rejects
+func TestReader__NextRecordValidation(t *testing.T) {
+	nextRecords := func(data string) error {
+		r := NewReader(strings.NewReader(data))
+		for {
+			if _, err := r.NextRecord(); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	write := func(file *File) string {
+		b := &bytes.Buffer{}
+		if err := NewWriter(b).Write(file); err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		return b.String()
+	}
+
+	// a Credit which follows the last CheckDetail of its Bundle
+	valid := write(mockStreamFile(t))
+	var lines, credits []string
+	for _, line := range strings.Split(strings.TrimSuffix(valid, "\n"), "\n") {
+		switch line[:2] {
+		case creditPos:
+			credits = append(credits, line)
+		case bundleControlPos:
+			lines = append(append(lines, credits...), line)
+			credits = nil
+		default:
+			lines = append(lines, line)
+		}
+	}
+	creditLast := strings.Join(lines, "\n") + "\n"
+
+	// a CashLetter with RecordTypeIndicator N which has Bundles
+	file := mockStreamFile(t)
+	file.CashLetters[1].CashLetterHeader.RecordTypeIndicator = "N"
+	noItems := write(file)
+
+	// a RoutingNumberSummary which doesn't agree with the CheckDetail
+	cl := mockRoutingNumberCashLetter()
+	cl.AddRoutingNumberSummary(mockRoutingNumberSummary())
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file = NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	routingNumberSummary := write(file)
+
+	tests := []struct {
+		name  string
+		data  string
+		valid bool
+	}{
+		{"valid", valid, true},
+		{"credit last", creditLast, true},
+		{"RecordTypeIndicator", noItems, false},
+		{"RoutingNumberSummary", routingNumberSummary, true},
+	}
+	for _, test := range tests {
+		_, err := NewReader(strings.NewReader(test.data)).Read()
+		if (err == nil) != test.valid {
+			t.Errorf("%s: Read: %v", test.name, err)
+		}
+		if err := nextRecords(test.data); (err == nil) != test.valid {
+			t.Errorf("%s: NextRecord: %v", test.name, err)
+		}
+	}
+}
+
+// TestReader__Lenient validates ReadLenientOption collects every error and returns the File read
+func TestReader__Lenient(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(mockStreamFile(t)); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	var lines []string
+	checkDetail, cashLetterControl := 0, 0
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		switch line[:2] {
+		case checkDetailPos:
+			if checkDetail++; checkDetail == 1 {
+				// invalid DocumentationTypeIndicator
+				line = line[:72] + "Z" + line[73:]
+			}
+		case cashLetterControlPos:
+			if cashLetterControl++; cashLetterControl == 2 {
+				// the last CashLetter is not closed
+				continue
+			}
+		case fileHeaderPos:
+			lines = append(lines, line, "XX"+strings.Repeat(" ", 78), "short")
+			continue
+		}
+		lines = append(lines, line)
+	}
+	data := strings.Join(lines, "\n")
+
+	if _, err := NewReader(strings.NewReader(data)).Read(); err == nil {
+		t.Fatal("expected error")
+	} else if _, ok := err.(*ParseError); !ok {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	file, err := NewReader(strings.NewReader(data), ReadLenientOption()).Read()
+	errs, ok := err.(base.ErrorList)
+	if !ok {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(errs) != 4 {
+		t.Errorf("%d errors: %v", len(errs), errs)
+	}
+	expected := []struct {
+		line   int
+		record string
+	}{
+		{2, "FileHeader"},
+		{3, "FileHeader"},
+		{8, "CheckDetail"},
+		{len(lines), "CashLetterControl"},
+	}
+	for i := range errs {
+		if i >= len(expected) {
+			break
+		}
+		p, ok := errs[i].(*ParseError)
+		if !ok {
+			t.Errorf("%T: %s", errs[i], errs[i])
+			continue
+		}
+		if p.Line != expected[i].line || p.Record != expected[i].record {
+			t.Errorf("line:%d record:%s, expected line:%d record:%s", p.Line, p.Record, expected[i].line, expected[i].record)
+		}
+	}
+
+	if len(file.CashLetters) != 2 {
+		t.Fatalf("read %d CashLetters", len(file.CashLetters))
+	}
+	checks := file.CashLetters[0].Bundles[0].GetChecks()
+	if len(checks) != 2 || checks[0].DocumentationTypeIndicator != "Z" {
+		t.Errorf("invalid CheckDetail not kept: %v", checks)
+	}
+	if len(file.CashLetters[1].GetBundles()) != 1 {
+		t.Errorf("read %d Bundles", len(file.CashLetters[1].GetBundles()))
+	}
+	if file.Control.CashLetterCount != 2 {
+		t.Errorf("FileControl not read")
+	}
+}
+
+// TestReader__LargeRecords validates records larger than 64 KiB are read, up to the maximum record length
+func TestReader__LargeRecords(t *testing.T) {
+	fd, err := os.Open(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	defer fd.Close()
+	file, err := NewReader(fd, ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	// image data which isn't base64 and has no line endings, so records can also be read as lines
+	image := bytes.Repeat([]byte("*IMAGE DATA*"), 30000)
+	for _, cd := range file.CashLetters[0].Bundles[0].Checks {
+		for i := range cd.ImageViewData {
+			cd.ImageViewData[i].ImageData = image
+			cd.ImageViewData[i].LengthImageData = fmt.Sprintf("%07d", len(image))
+		}
+	}
+
+	tests := []struct {
+		name       string
+		writerOpts []WriterOption
+		readerOpts []ReaderOption
+	}{
+		{"lines", nil, nil},
+		{"variable line length", []WriterOption{WriteVariableLineLengthOption()}, []ReaderOption{ReadVariableLineLengthOption()}},
+		{"ebcdic", []WriterOption{WriteVariableLineLengthOption(), WriteEbcdicEncodingOption()},
+			[]ReaderOption{ReadVariableLineLengthOption(), ReadEbcdicEncodingOption()}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := NewWriter(&buf, test.writerOpts...).Write(&file); err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		data := buf.Bytes()
+
+		read, err := NewReader(bytes.NewReader(data), test.readerOpts...).Read()
+		if err != nil {
+			t.Fatalf("%s: %T: %s", test.name, err, err)
+		}
+		if got := read.CashLetters[0].Bundles[0].Checks[0].ImageViewData[0].ImageData; !bytes.Equal(got, image) {
+			t.Errorf("%s: read %d bytes of image data", test.name, len(got))
+		}
+
+		// records longer than the maximum record length are not read
+		opts := append(test.readerOpts, ReadMaxRecordLengthOption(100000))
+		_, err = NewReader(bytes.NewReader(data), opts...).Read()
+		if e, ok := err.(*ParseError); !ok {
+			t.Errorf("%s: %T: %s", test.name, err, err)
+		} else if fe, ok := e.Err.(*FileError); !ok || fe.FieldName != "RecordLength" {
+			t.Errorf("%s: %T: %s", test.name, e.Err, e.Err)
+		}
+	}
+
+	// a length prefix larger than the maximum record length is not read
+	r := NewReader(strings.NewReader("\x7f\xff\xff\xff0123456789"), ReadVariableLineLengthOption())
+	if _, err := r.NextRecord(); err == nil || !strings.Contains(err.Error(), "RecordLength") {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	// lines longer than a maximum record length smaller than the scanner's buffer are not read
+	fh := mockFileHeader()
+	r = NewReader(strings.NewReader(fh.String()+"\n"), ReadMaxRecordLengthOption(40))
+	if _, err := r.NextRecord(); err == nil || !strings.Contains(err.Error(), "RecordLength") {
+		t.Errorf("%T: %s", err, err)
+	}
+	// including when the framing is detected
+	r = NewReader(strings.NewReader(fh.String()+"\n"), ReadMaxRecordLengthOption(40), ReadDetectFormatOption())
+	if _, err := r.NextRecord(); err == nil || !strings.Contains(err.Error(), "RecordLength") {
+		t.Errorf("%T: %s", err, err)
+	}
+}
@@ -143,10 +143,17 @@ type ReturnDetail struct {
 	ImageViewData []ImageViewData `json:"imageViewData"`
 	// ImageViewAnalysis
 	ImageViewAnalysis []ImageViewAnalysis `json:"imageViewAnalysis"`
+	// UserRecords are the User Records which follow the ReturnDetail
+	UserRecords UserRecords `json:"userRecords,omitempty"`
+	// UserRecordPositions are the number of addendum and image view records each of the UserRecords follows.
+	// UserRecords without a position follow the image view records.
+	UserRecordPositions []int `json:"userRecordPositions,omitempty"`
 	// validator is composed for image cash letter data validation
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // CustomerReturnCode are customer return reason codes as defined in Part 6.2 of the ANSI X9.100-188-2018 Return
@@ -493,6 +500,23 @@ func (rd *ReturnDetail) GetImageViewAnalysis() []ImageViewAnalysis {
 	return rd.ImageViewAnalysis
 }
 
+// AddUserRecord appends a UserRecord to the ReturnDetail
+func (rd *ReturnDetail) AddUserRecord(ur UserRecord) UserRecords {
+	rd.UserRecords = append(rd.UserRecords, ur)
+	return rd.UserRecords
+}
+
+// GetUserRecords returns a slice of UserRecord for the ReturnDetail
+func (rd *ReturnDetail) GetUserRecords() UserRecords {
+	return rd.UserRecords
+}
+
+// itemRecordCount returns the number of addendum and image view records of the ReturnDetail
+func (rd *ReturnDetail) itemRecordCount() int {
+	return len(rd.ReturnDetailAddendumA) + len(rd.ReturnDetailAddendumB) + len(rd.ReturnDetailAddendumC) +
+		len(rd.ReturnDetailAddendumD) + len(rd.ImageViewDetail) + len(rd.ImageViewData) + len(rd.ImageViewAnalysis)
+}
+
 // SetEceInstitutionItemSequenceNumber sets EceInstitutionItemSequenceNumber
 func (rd *ReturnDetail) SetEceInstitutionItemSequenceNumber(seq int) string {
 	itemSequence := strconv.Itoa(seq)
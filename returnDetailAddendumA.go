@@ -86,6 +86,8 @@ type ReturnDetailAddendumA struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewReturnDetailAddendumA returns a new ReturnDetailAddendumA with default values for non exported fields
@@ -39,6 +39,8 @@ type ReturnDetailAddendumB struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewReturnDetailAddendumB returns a new ReturnDetailAddendumB with default values for non exported fields
@@ -51,6 +51,8 @@ type ReturnDetailAddendumC struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewReturnDetailAddendumC returns a new ReturnDetailAddendumC with default values for non exported fields
@@ -88,6 +88,8 @@ type ReturnDetailAddendumD struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewReturnDetailAddendumD returns a new ReturnDetailAddendumD with default values for non exported fields
@@ -0,0 +1,209 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Errors specific to returning items
+var (
+	msgReturnCheckDetail      = "must have a CheckDetail to return"
+	msgReturnsIndicator       = "must be E, R or J for a returns CashLetter"
+	msgReturnsIndicatorReason = "is not a return reason for ReturnsIndicator %v"
+	msgReturnsReturnDetail    = "must not be nil in a returns CashLetter"
+)
+
+// ReturnInfo describes the return of a forward item (CheckDetail) by the returning bank.
+type ReturnInfo struct {
+	// ReturnReason is a code that indicates the reason for non-payment, see CustomerReturnCodeDict and
+	// AdministrativeReturnCodeDict.
+	ReturnReason string
+	// ForwardBundleDate is the business date of the forward Bundle which contained the item.
+	ForwardBundleDate time.Time
+	// EceInstitutionItemSequenceNumber is the number assigned to the return by the returning bank. The
+	// EceInstitutionItemSequenceNumber of the CheckDetail is used when blank.
+	EceInstitutionItemSequenceNumber string
+	// ReturnNotificationIndicator is 1 for a preliminary or 2 for a final notification, 2 when blank.
+	ReturnNotificationIndicator string
+	// TimesReturned is the number of times the item has been returned, including this return. 1 when zero.
+	TimesReturned int
+
+	// PayorBankName is the short name of the payor bank.
+	PayorBankName string
+	// PayorBankSequenceNumber is the number assigned to the item by the payor bank. A ReturnDetailAddendumB is
+	// added for the payor bank when it's set.
+	PayorBankSequenceNumber string
+	// PayorBankBusinessDate is the business date the payor bank processed the item.
+	PayorBankBusinessDate time.Time
+	// PayorAccountName is the name of the payor on the account.
+	PayorAccountName string
+
+	// ReturnBankRoutingNumber is the routing number of the returning bank. A ReturnDetailAddendumD endorsing the
+	// return is added for the returning bank when it's set.
+	ReturnBankRoutingNumber string
+	// ReturnBankBusinessDate is the business date of the returning bank's endorsement.
+	ReturnBankBusinessDate time.Time
+	// ReturnBankItemSequenceNumber is the number assigned to the item by the returning bank.
+	ReturnBankItemSequenceNumber string
+	// ReturnBankIdentifier is the EndorsingBankIdentifier of the returning bank's endorsement.
+	ReturnBankIdentifier int
+}
+
+// ReturnCheckDetail returns a ReturnDetail for the return of a CheckDetail. The payor routing number, On-Us,
+// amount and indicators of the CheckDetail are kept, its BOFD (CheckDetailAddendumA), image reference
+// (CheckDetailAddendumB) and endorsement (CheckDetailAddendumC) addenda become ReturnDetailAddendumA,
+// ReturnDetailAddendumC and ReturnDetailAddendumD records, and its image records are carried to the return.
+func ReturnCheckDetail(cd *CheckDetail, info ReturnInfo) (*ReturnDetail, error) {
+	if cd == nil {
+		return nil, &FieldError{FieldName: "CheckDetail", Msg: msgReturnCheckDetail}
+	}
+
+	rd := NewReturnDetail()
+	rd.PayorBankRoutingNumber = cd.PayorBankRoutingNumber
+	rd.PayorBankCheckDigit = cd.PayorBankCheckDigit
+	rd.OnUs = cd.OnUs
+	rd.ItemAmount = cd.ItemAmount
+	rd.ReturnReason = info.ReturnReason
+	rd.DocumentationTypeIndicator = cd.DocumentationTypeIndicator
+	rd.ForwardBundleDate = info.ForwardBundleDate
+	rd.EceInstitutionItemSequenceNumber = info.EceInstitutionItemSequenceNumber
+	if rd.EceInstitutionItemSequenceNumber == "" {
+		rd.EceInstitutionItemSequenceNumber = cd.EceInstitutionItemSequenceNumber
+	}
+	rd.ExternalProcessingCode = cd.ExternalProcessingCode
+	rd.ReturnNotificationIndicator = info.ReturnNotificationIndicator
+	if rd.ReturnNotificationIndicator == "" {
+		rd.ReturnNotificationIndicator = "2"
+	}
+	rd.ArchiveTypeIndicator = cd.ArchiveTypeIndicator
+	rd.TimesReturned = info.TimesReturned
+	if rd.TimesReturned == 0 {
+		rd.TimesReturned = 1
+	}
+
+	// BOFD
+	for i, cdAddendumA := range cd.CheckDetailAddendumA {
+		rdAddendumA := NewReturnDetailAddendumA()
+		rdAddendumA.RecordNumber = i + 1
+		rdAddendumA.ReturnLocationRoutingNumber = cdAddendumA.ReturnLocationRoutingNumber
+		rdAddendumA.BOFDEndorsementDate = cdAddendumA.BOFDEndorsementDate
+		rdAddendumA.BOFDItemSequenceNumber = cdAddendumA.BOFDItemSequenceNumber
+		rdAddendumA.BOFDAccountNumber = cdAddendumA.BOFDAccountNumber
+		rdAddendumA.BOFDBranchCode = cdAddendumA.BOFDBranchCode
+		rdAddendumA.PayeeName = cdAddendumA.PayeeName
+		rdAddendumA.TruncationIndicator = cdAddendumA.TruncationIndicator
+		rdAddendumA.BOFDConversionIndicator = cdAddendumA.BOFDConversionIndicator
+		rdAddendumA.BOFDCorrectionIndicator = cdAddendumA.BOFDCorrectionIndicator
+		rdAddendumA.UserField = cdAddendumA.UserField
+		rd.AddReturnDetailAddendumA(rdAddendumA)
+	}
+
+	// Payor Bank
+	if info.PayorBankSequenceNumber != "" {
+		rdAddendumB := NewReturnDetailAddendumB()
+		rdAddendumB.PayorBankName = info.PayorBankName
+		rdAddendumB.AuxiliaryOnUs = cd.AuxiliaryOnUs
+		rdAddendumB.PayorBankSequenceNumber = info.PayorBankSequenceNumber
+		rdAddendumB.PayorBankBusinessDate = info.PayorBankBusinessDate
+		rdAddendumB.PayorAccountName = info.PayorAccountName
+		rd.AddReturnDetailAddendumB(rdAddendumB)
+	}
+
+	// Image Reference Key
+	for _, cdAddendumB := range cd.CheckDetailAddendumB {
+		rdAddendumC := NewReturnDetailAddendumC()
+		rdAddendumC.ImageReferenceKeyIndicator = cdAddendumB.ImageReferenceKeyIndicator
+		rdAddendumC.MicrofilmArchiveSequenceNumber = cdAddendumB.MicrofilmArchiveSequenceNumber
+		rdAddendumC.LengthImageReferenceKey = cdAddendumB.LengthImageReferenceKey
+		rdAddendumC.ImageReferenceKey = cdAddendumB.ImageReferenceKey
+		rdAddendumC.Description = cdAddendumB.Description
+		rdAddendumC.UserField = cdAddendumB.UserField
+		rd.AddReturnDetailAddendumC(rdAddendumC)
+	}
+
+	// Endorsements
+	for _, cdAddendumC := range cd.CheckDetailAddendumC {
+		rdAddendumD := NewReturnDetailAddendumD()
+		rdAddendumD.EndorsingBankRoutingNumber = cdAddendumC.EndorsingBankRoutingNumber
+		rdAddendumD.BOFDEndorsementBusinessDate = cdAddendumC.BOFDEndorsementBusinessDate
+		rdAddendumD.EndorsingBankItemSequenceNumber = cdAddendumC.EndorsingBankItemSequenceNumber
+		rdAddendumD.TruncationIndicator = cdAddendumC.TruncationIndicator
+		rdAddendumD.EndorsingBankConversionIndicator = cdAddendumC.EndorsingBankConversionIndicator
+		rdAddendumD.EndorsingBankCorrectionIndicator = cdAddendumC.EndorsingBankCorrectionIndicator
+		rdAddendumD.ReturnReason = cdAddendumC.ReturnReason
+		rdAddendumD.UserField = cdAddendumC.UserField
+		rdAddendumD.EndorsingBankIdentifier = cdAddendumC.EndorsingBankIdentifier
+		rd.AddReturnDetailAddendumD(rdAddendumD)
+	}
+	if info.ReturnBankRoutingNumber != "" {
+		rdAddendumD := NewReturnDetailAddendumD()
+		rdAddendumD.EndorsingBankRoutingNumber = info.ReturnBankRoutingNumber
+		rdAddendumD.BOFDEndorsementBusinessDate = info.ReturnBankBusinessDate
+		rdAddendumD.EndorsingBankItemSequenceNumber = info.ReturnBankItemSequenceNumber
+		rdAddendumD.TruncationIndicator = "N"
+		rdAddendumD.ReturnReason = info.ReturnReason
+		rdAddendumD.EndorsingBankIdentifier = info.ReturnBankIdentifier
+		rd.AddReturnDetailAddendumD(rdAddendumD)
+	}
+	for i := range rd.ReturnDetailAddendumD {
+		rd.ReturnDetailAddendumD[i].RecordNumber = i + 1
+	}
+	rd.AddendumCount = len(rd.ReturnDetailAddendumA) + len(rd.ReturnDetailAddendumB) +
+		len(rd.ReturnDetailAddendumC) + len(rd.ReturnDetailAddendumD)
+
+	// Images
+	rd.ImageViewDetail = append([]ImageViewDetail(nil), cd.ImageViewDetail...)
+	rd.ImageViewData = append([]ImageViewData(nil), cd.ImageViewData...)
+	rd.ImageViewAnalysis = append([]ImageViewAnalysis(nil), cd.ImageViewAnalysis...)
+
+	if err := rd.Validate(); err != nil {
+		return nil, err
+	}
+	if err := new(Bundle).ValidateReturnItems(rd); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// NewReturnsCashLetter returns a CashLetter of the returns, bundled with the BundlerOptions. The CashLetterHeader
+// of the CashLetter is a copy of clh with its ReturnsIndicator set to returnsIndicator (E administrative, R customer
+// or J reject returns) and its CollectionTypeIndicator to 03 (Return), unless it's already a return or return
+// notification (04, 05 or 06). clh is not modified.
+func NewReturnsCashLetter(clh *CashLetterHeader, returnsIndicator string, returns []*ReturnDetail, opts ...BundlerOption) (CashLetter, error) {
+	if clh == nil {
+		return CashLetter{}, &CashLetterError{FieldName: "CashLetterHeader", Msg: msgBundlerCashLetter}
+	}
+	if err := clh.isReturnsIndicator(returnsIndicator); err != nil || returnsIndicator == "" {
+		return CashLetter{}, &FieldError{FieldName: "ReturnsIndicator", Value: returnsIndicator, Msg: msgReturnsIndicator}
+	}
+	for _, rd := range returns {
+		if rd == nil {
+			return CashLetter{}, &FieldError{FieldName: "ReturnDetail", Msg: msgReturnsReturnDetail}
+		}
+		_, crc := CustomerReturnCodeDict[rd.ReturnReason]
+		_, arc := AdministrativeReturnCodeDict[rd.ReturnReason]
+		if (returnsIndicator == "R" && !crc) || (returnsIndicator == "E" && !arc) {
+			msg := fmt.Sprintf(msgReturnsIndicatorReason, returnsIndicator)
+			return CashLetter{}, &FieldError{FieldName: "ReturnReason", Value: rd.ReturnReason, Msg: msg}
+		}
+	}
+
+	header := *clh
+	clh = &header
+	switch clh.CollectionTypeIndicator {
+	case "03", "04", "05", "06":
+	default:
+		clh.CollectionTypeIndicator = "03"
+	}
+	clh.ReturnsIndicator = returnsIndicator
+
+	b := NewBundler(clh, opts...)
+	for _, rd := range returns {
+		b.AddReturnDetail(rd)
+	}
+	return b.CashLetter()
+}
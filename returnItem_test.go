@@ -0,0 +1,150 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// mockReturnInfo creates a ReturnInfo for a customer return by the payor bank
+func mockReturnInfo() ReturnInfo {
+	return ReturnInfo{
+		ReturnReason:                 "A",
+		ForwardBundleDate:            time.Now(),
+		PayorBankName:                "Payor Bank Name",
+		PayorBankSequenceNumber:      "1              ",
+		PayorBankBusinessDate:        time.Now(),
+		PayorAccountName:             "Payor Account Name",
+		ReturnBankRoutingNumber:      "031300012",
+		ReturnBankBusinessDate:       time.Now(),
+		ReturnBankItemSequenceNumber: "1              ",
+		ReturnBankIdentifier:         3,
+	}
+}
+
+// TestReturnCheckDetail validates a CheckDetail is converted to a ReturnDetail
+func TestReturnCheckDetail(t *testing.T) {
+	cd := mockBundleChecks().Checks[0]
+	cd.AuxiliaryOnUs = "123456789"
+	rd, err := ReturnCheckDetail(cd, mockReturnInfo())
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	if rd.PayorBankRoutingNumber != cd.PayorBankRoutingNumber || rd.PayorBankCheckDigit != cd.PayorBankCheckDigit ||
+		rd.OnUs != cd.OnUs || rd.ItemAmount != cd.ItemAmount || rd.ReturnReason != "A" ||
+		rd.EceInstitutionItemSequenceNumber != cd.EceInstitutionItemSequenceNumber {
+		t.Errorf("ReturnDetail: %s", rd.String())
+	}
+	if rd.ReturnNotificationIndicator != "2" || rd.TimesReturned != 1 {
+		t.Errorf("ReturnNotificationIndicator %s TimesReturned %d", rd.ReturnNotificationIndicator, rd.TimesReturned)
+	}
+	if len(rd.ReturnDetailAddendumA) != 1 || len(rd.ReturnDetailAddendumB) != 1 || len(rd.ReturnDetailAddendumC) != 1 ||
+		len(rd.ReturnDetailAddendumD) != 2 || rd.AddendumCount != 5 {
+		t.Fatalf("AddendumCount %d", rd.AddendumCount)
+	}
+	if a := rd.ReturnDetailAddendumA[0]; a.PayeeName != cd.CheckDetailAddendumA[0].PayeeName ||
+		a.BOFDAccountNumber != cd.CheckDetailAddendumA[0].BOFDAccountNumber || a.RecordNumber != 1 {
+		t.Errorf("ReturnDetailAddendumA: %s", a.String())
+	}
+	if b := rd.ReturnDetailAddendumB[0]; b.AuxiliaryOnUs != "123456789" || b.PayorBankName != "Payor Bank Name" {
+		t.Errorf("ReturnDetailAddendumB: %s", b.String())
+	}
+	if c := rd.ReturnDetailAddendumC[0]; c.ImageReferenceKey != cd.CheckDetailAddendumB[0].ImageReferenceKey {
+		t.Errorf("ReturnDetailAddendumC: %s", c.String())
+	}
+	if d := rd.ReturnDetailAddendumD[0]; d.EndorsingBankRoutingNumber != cd.CheckDetailAddendumC[0].EndorsingBankRoutingNumber {
+		t.Errorf("ReturnDetailAddendumD: %s", d.String())
+	}
+	if d := rd.ReturnDetailAddendumD[1]; d.EndorsingBankRoutingNumber != "031300012" || d.ReturnReason != "A" ||
+		d.EndorsingBankIdentifier != 3 || d.RecordNumber != 2 {
+		t.Errorf("ReturnDetailAddendumD: %s", d.String())
+	}
+	if len(rd.ImageViewDetail) != 1 || len(rd.ImageViewData) != 1 || len(rd.ImageViewAnalysis) != 1 {
+		t.Error("images not carried to ReturnDetail")
+	}
+
+	// the return reason must be valid
+	info := mockReturnInfo()
+	info.ReturnReason = "-"
+	if _, err := ReturnCheckDetail(cd, info); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "ReturnReason" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	if _, err := ReturnCheckDetail(nil, mockReturnInfo()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestNewReturnsCashLetter validates a returns CashLetter is created and written
+func TestNewReturnsCashLetter(t *testing.T) {
+	var returns []*ReturnDetail
+	for i := 0; i < 3; i++ {
+		rd, err := ReturnCheckDetail(mockBundleChecks().Checks[0], mockReturnInfo())
+		if err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		returns = append(returns, rd)
+	}
+	clh := mockCashLetterHeader()
+	cl, err := NewReturnsCashLetter(clh, "R", returns, BundleMaxItemsOption(2))
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if clh.CollectionTypeIndicator != "01" || clh.ReturnsIndicator != "" || cl.CashLetterHeader == clh {
+		t.Errorf("CashLetterHeader modified: %s", clh.String())
+	}
+	if cl.CashLetterHeader.CollectionTypeIndicator != "03" || cl.CashLetterHeader.ReturnsIndicator != "R" {
+		t.Errorf("CashLetterHeader: %s", cl.CashLetterHeader.String())
+	}
+	if len(cl.Bundles) != 2 || cl.Bundles[0].BundleHeader.CollectionTypeIndicator != "03" {
+		t.Fatalf("%d Bundles", len(cl.Bundles))
+	}
+	if cl.CashLetterControl.CashLetterItemsCount != 3 {
+		t.Errorf("CashLetterControl: %s", cl.CashLetterControl.String())
+	}
+
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	read, err := NewReader(bytes.NewReader(b.Bytes())).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if rd := read.CashLetters[0].Bundles[0].Returns[0]; len(rd.ReturnDetailAddendumD) != 2 || rd.ReturnDetailAddendumD[1].RecordNumber != 2 {
+		t.Errorf("ReturnDetail read: %s", rd.String())
+	}
+
+	// ReturnsIndicator and return reasons
+	if _, err := NewReturnsCashLetter(mockCashLetterHeader(), "", returns); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := NewReturnsCashLetter(mockCashLetterHeader(), "R", append(returns, nil)); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "ReturnDetail" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	returns[0].ReturnReason = "V"
+	if _, err := NewReturnsCashLetter(mockCashLetterHeader(), "R", returns); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "ReturnReason" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
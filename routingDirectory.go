@@ -0,0 +1,384 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors specific to a RoutingDirectory
+var (
+	msgRoutingDirectoryLine    = "is not a %s routing directory line"
+	msgRoutingDirectoryFormat  = "is not a FedACH, Fedwire or check routing directory"
+	msgRoutingDirectoryUnknown = "is not in the routing directory"
+	msgRoutingDirectoryChanged = "has been replaced by routing number %v"
+	msgRoutingDirectoryCheck   = "is not check eligible in the routing directory"
+)
+
+// RoutingDirectoryFormat is the format of a routing directory file
+type RoutingDirectoryFormat string
+
+const (
+	// RoutingDirectoryFedACH is the fixed width FedACH participant directory of the Federal Reserve E-Payments
+	// Routing Directory, with 155 character lines
+	RoutingDirectoryFedACH RoutingDirectoryFormat = "fedach"
+	// RoutingDirectoryFedwire is the fixed width Fedwire Funds Service participant directory of the Federal Reserve
+	// E-Payments Routing Directory, with 101 character lines
+	RoutingDirectoryFedwire RoutingDirectoryFormat = "fedwire"
+	// RoutingDirectoryCheck is a CSV file of check eligible routing numbers, e.g. the endpoints of a clearing
+	// partner, with the columns routing number, name, city and state. Only the routing number is required, and a
+	// first line which doesn't start with a routing number is a header.
+	RoutingDirectoryCheck RoutingDirectoryFormat = "check"
+)
+
+// RoutingParticipant is a financial institution of a RoutingDirectory
+type RoutingParticipant struct {
+	// RoutingNumber is the 9 digit routing number of the institution
+	RoutingNumber string `json:"routingNumber"`
+	// Name is the name of the institution
+	Name string `json:"name"`
+	// Address is the street address of the institution
+	Address string `json:"address,omitempty"`
+	// City is the city of the institution
+	City string `json:"city,omitempty"`
+	// State is the state abbreviation of the institution
+	State string `json:"state,omitempty"`
+	// PostalCode is the ZIP code of the institution, with its 4 digit extension when it has one
+	PostalCode string `json:"postalCode,omitempty"`
+	// PhoneNumber is the 10 digit phone number of the institution
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+	// NewRoutingNumber is the routing number which replaces RoutingNumber, when the FedACH directory lists the
+	// routing number as changed
+	NewRoutingNumber string `json:"newRoutingNumber,omitempty"`
+	// Revised is the date the institution was last changed in a FedACH or Fedwire directory
+	Revised time.Time `json:"revised"`
+	// ACH is true when the institution is in a FedACH directory
+	ACH bool `json:"ach"`
+	// Wire is true when the institution is in a Fedwire directory and can receive funds transfers
+	Wire bool `json:"wire"`
+	// Check is true when the institution is in a check directory
+	Check bool `json:"check"`
+}
+
+// Active returns true when the routing number hasn't been replaced by a NewRoutingNumber
+func (p *RoutingParticipant) Active() bool {
+	return p.NewRoutingNumber == ""
+}
+
+// RoutingDirectory is a directory of routing numbers loaded from FedACH, Fedwire and check directory files.
+// Each file read adds to the RoutingDirectory, so an institution in more than one file is looked up with the
+// details of each. A RoutingDirectory is safe for concurrent use.
+type RoutingDirectory struct {
+	mu           sync.RWMutex
+	participants map[string]*RoutingParticipant
+	// check is true when a check directory has been read, so institutions not in it are not check eligible
+	check bool
+}
+
+// NewRoutingDirectory returns an empty RoutingDirectory
+func NewRoutingDirectory() *RoutingDirectory {
+	return &RoutingDirectory{participants: make(map[string]*RoutingParticipant)}
+}
+
+// LoadRoutingDirectory returns a RoutingDirectory of the routing directory files at paths, detecting the
+// RoutingDirectoryFormat of each file.
+func LoadRoutingDirectory(paths ...string) (*RoutingDirectory, error) {
+	d := NewRoutingDirectory()
+	for _, path := range paths {
+		if err := d.loadFile(path); err != nil {
+			return nil, fmt.Errorf("problem reading routing directory %s: %v", path, err)
+		}
+	}
+	return d, nil
+}
+
+func (d *RoutingDirectory) loadFile(path string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	return d.Read(fd, "")
+}
+
+// Read adds the institutions of a routing directory file in format to the RoutingDirectory. The format is detected
+// from the first line when format is blank. A FileError is returned for the first line which can't be read.
+func (d *RoutingDirectory) Read(r io.Reader, format RoutingDirectoryFormat) error {
+	br := bufio.NewReader(r)
+	if format == "" {
+		first, err := br.Peek(256)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+		format = detectRoutingDirectoryFormat(string(first))
+		if format == "" {
+			return &FileError{FieldName: "RoutingDirectory", Msg: msgRoutingDirectoryFormat}
+		}
+	}
+	var participants []*RoutingParticipant
+	switch format {
+	case RoutingDirectoryFedACH, RoutingDirectoryFedwire:
+		scanner := bufio.NewScanner(br)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimRight(scanner.Text(), "\r")
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			parse := parseFedACHParticipant
+			if format == RoutingDirectoryFedwire {
+				parse = parseFedwireParticipant
+			}
+			p, ok := parse(text)
+			if !ok {
+				return &FileError{FieldName: "LineNumber", Value: strconv.Itoa(line), Msg: fmt.Sprintf(msgRoutingDirectoryLine, format)}
+			}
+			participants = append(participants, p)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	case RoutingDirectoryCheck:
+		cr := csv.NewReader(br)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		for line := 1; ; line++ {
+			record, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			p, ok := parseCheckParticipant(record)
+			if !ok {
+				if line == 1 {
+					// a header
+					continue
+				}
+				return &FileError{FieldName: "LineNumber", Value: strconv.Itoa(line), Msg: fmt.Sprintf(msgRoutingDirectoryLine, format)}
+			}
+			participants = append(participants, p)
+		}
+	default:
+		return &FileError{FieldName: "RoutingDirectory", Value: string(format), Msg: msgRoutingDirectoryFormat}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.participants == nil {
+		d.participants = make(map[string]*RoutingParticipant)
+	}
+	for _, p := range participants {
+		d.participants[p.RoutingNumber] = mergeRoutingParticipant(d.participants[p.RoutingNumber], p)
+	}
+	if format == RoutingDirectoryCheck {
+		d.check = true
+	}
+	return nil
+}
+
+// Lookup returns the RoutingParticipant of a routing number, and false when the routing number isn't in the
+// RoutingDirectory. Routing numbers shorter than 9 digits are zero padded.
+func (d *RoutingDirectory) Lookup(routingNumber string) (*RoutingParticipant, bool) {
+	routingNumber = strings.TrimSpace(routingNumber)
+	if len(routingNumber) < 9 {
+		routingNumber = strings.Repeat("0", 9-len(routingNumber)) + routingNumber
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	p, ok := d.participants[routingNumber]
+	if !ok {
+		return nil, false
+	}
+	participant := *p
+	return &participant, true
+}
+
+// Len returns the number of routing numbers in the RoutingDirectory
+func (d *RoutingDirectory) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.participants)
+}
+
+// ValidateRoutingDirectoryOption validates the PayorBankRoutingNumber and PayorBankCheckDigit of CheckDetail and
+// ReturnDetail records and the DestinationRoutingNumber of CashLetterHeader and BundleHeader records are in the
+// RoutingDirectory, and haven't been replaced by a new routing number. When a check directory has been read the
+// routing numbers must also be check eligible. Fields which are blank are not validated.
+func ValidateRoutingDirectoryOption(d *RoutingDirectory) ValidateOption {
+	return func(o *validateOptions) {
+		o.routingDirectory = d
+	}
+}
+
+// routingDirectoryErrors returns a FieldError for each routing number field of a record which isn't an active
+// institution of the RoutingDirectory
+func (d *RoutingDirectory) routingDirectoryErrors(rec interface{}) []error {
+	var fields []routingNumberField
+	switch r := rec.(type) {
+	case *CashLetterHeader:
+		fields = []routingNumberField{{name: "DestinationRoutingNumber", value: r.DestinationRoutingNumber}}
+	case *BundleHeader:
+		fields = []routingNumberField{{name: "DestinationRoutingNumber", value: r.DestinationRoutingNumber}}
+	case *CheckDetail:
+		fields = []routingNumberField{{name: "PayorBankRoutingNumber", value: payorBankRoutingNumber(r.PayorBankRoutingNumber, r.PayorBankCheckDigit)}}
+	case *ReturnDetail:
+		fields = []routingNumberField{{name: "PayorBankRoutingNumber", value: payorBankRoutingNumber(r.PayorBankRoutingNumber, r.PayorBankCheckDigit)}}
+	}
+	var errs []error
+	for _, field := range fields {
+		if strings.TrimSpace(field.value) == "" {
+			continue
+		}
+		p, ok := d.Lookup(field.value)
+		switch {
+		case !ok:
+			errs = append(errs, &FieldError{FieldName: field.name, Value: field.value, Msg: msgRoutingDirectoryUnknown})
+		case !p.Active():
+			errs = append(errs, &FieldError{FieldName: field.name, Value: field.value,
+				Msg: fmt.Sprintf(msgRoutingDirectoryChanged, p.NewRoutingNumber)})
+		case d.checkDirectory() && !p.Check:
+			errs = append(errs, &FieldError{FieldName: field.name, Value: field.value, Msg: msgRoutingDirectoryCheck})
+		}
+	}
+	return errs
+}
+
+func (d *RoutingDirectory) checkDirectory() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.check
+}
+
+// detectRoutingDirectoryFormat returns the RoutingDirectoryFormat of a file from its first line, or blank
+func detectRoutingDirectoryFormat(data string) RoutingDirectoryFormat {
+	line := data
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimRight(line, "\r")
+	switch {
+	case strings.Contains(line, ","):
+		return RoutingDirectoryCheck
+	case len(line) >= 150 && isRoutingNumber(line[:9]):
+		return RoutingDirectoryFedACH
+	case len(line) >= 93 && len(line) <= 101 && isRoutingNumber(line[:9]):
+		return RoutingDirectoryFedwire
+	}
+	return ""
+}
+
+// parseFedACHParticipant parses a line of a FedACH directory
+func parseFedACHParticipant(line string) (*RoutingParticipant, bool) {
+	if len(line) < 150 || !isRoutingNumber(line[0:9]) {
+		return nil, false
+	}
+	field := func(start, end int) string {
+		return strings.TrimSpace(line[start:end])
+	}
+	p := &RoutingParticipant{
+		RoutingNumber: line[0:9],
+		Name:          field(35, 71),
+		Address:       field(71, 107),
+		City:          field(107, 127),
+		State:         field(127, 129),
+		PostalCode:    field(129, 134),
+		PhoneNumber:   field(138, 148),
+		ACH:           true,
+	}
+	if ext := field(134, 138); ext != "" && ext != "0000" {
+		p.PostalCode += "-" + ext
+	}
+	// record type code 2 sends entries to the new routing number
+	if line[19] == '2' && isRoutingNumber(line[26:35]) {
+		p.NewRoutingNumber = line[26:35]
+	}
+	p.Revised, _ = time.Parse("010206", line[20:26])
+	return p, true
+}
+
+// parseFedwireParticipant parses a line of a Fedwire directory
+func parseFedwireParticipant(line string) (*RoutingParticipant, bool) {
+	if len(line) < 93 || !isRoutingNumber(line[0:9]) {
+		return nil, false
+	}
+	p := &RoutingParticipant{
+		RoutingNumber: line[0:9],
+		Name:          strings.TrimSpace(line[27:63]),
+		State:         strings.TrimSpace(line[63:65]),
+		City:          strings.TrimSpace(line[65:90]),
+		// funds transfer status
+		Wire: line[90] == 'Y',
+	}
+	if len(line) >= 101 {
+		p.Revised, _ = time.Parse("20060102", line[93:101])
+	}
+	return p, true
+}
+
+// parseCheckParticipant parses a record of a check directory
+func parseCheckParticipant(record []string) (*RoutingParticipant, bool) {
+	if len(record) == 0 || !isRoutingNumber(strings.TrimSpace(record[0])) {
+		return nil, false
+	}
+	column := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	return &RoutingParticipant{RoutingNumber: column(0), Name: column(1), City: column(2), State: column(3), Check: true}, true
+}
+
+// mergeRoutingParticipant adds the details of p to an existing RoutingParticipant, which may be nil
+func mergeRoutingParticipant(existing, p *RoutingParticipant) *RoutingParticipant {
+	if existing == nil {
+		return p
+	}
+	merged := *existing
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&merged.Name, p.Name}, {&merged.Address, p.Address}, {&merged.City, p.City}, {&merged.State, p.State},
+		{&merged.PostalCode, p.PostalCode}, {&merged.PhoneNumber, p.PhoneNumber},
+	} {
+		if *field.dst == "" {
+			*field.dst = field.src
+		}
+	}
+	if p.ACH {
+		// the FedACH directory lists changed routing numbers
+		merged.NewRoutingNumber = p.NewRoutingNumber
+	}
+	if p.Revised.After(merged.Revised) {
+		merged.Revised = p.Revised
+	}
+	merged.ACH = merged.ACH || p.ACH
+	merged.Wire = merged.Wire || p.Wire
+	merged.Check = merged.Check || p.Check
+	return &merged
+}
+
+// isRoutingNumber returns true when s is 9 digits
+func isRoutingNumber(s string) bool {
+	if len(s) != 9 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
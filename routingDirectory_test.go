@@ -0,0 +1,195 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mockFedACHLine returns a line of a FedACH directory, with the new routing number of a changed routing number
+func mockFedACHLine(routingNumber, newRoutingNumber, name, city, state string) string {
+	recordType := "1"
+	if newRoutingNumber != "" {
+		recordType = "2"
+	} else {
+		newRoutingNumber = "000000000"
+	}
+	return fmt.Sprintf("%-9s%s%-9s%s%s%-9s%-36s%-36s%-20s%-2s%-5s%-4s%-10s%s%s%-5s",
+		routingNumber, "O", "011000015", recordType, "072819", newRoutingNumber, name, "100 MAIN ST", city, state,
+		"02110", "1234", "6175551234", "1", "1", "")
+}
+
+// mockFedwireLine returns a line of a Fedwire directory
+func mockFedwireLine(routingNumber, name, city, state, status string) string {
+	return fmt.Sprintf("%-9s%-18.18s%-36s%-2s%-25s%s%s%s%s",
+		routingNumber, strings.ToUpper(name), name, state, city, status, " ", "N", "20190512")
+}
+
+// mockRoutingDirectory returns a RoutingDirectory of the routing numbers of valid-ascii.x937
+func mockRoutingDirectory(t *testing.T) *RoutingDirectory {
+	d := NewRoutingDirectory()
+	fedACH := strings.Join([]string{
+		mockFedACHLine("231380104", "", "FIRST DESTINATION BANK", "BOSTON", "MA"),
+		mockFedACHLine("061000146", "", "CLEARING BANK", "ATLANTA", "GA"),
+		mockFedACHLine("122000661", "", "PAYOR BANK", "SAN FRANCISCO", "CA"),
+		mockFedACHLine("031300012", "", "OTHER BANK", "PHILADELPHIA", "PA"),
+		mockFedACHLine("011000028", "231380104", "MERGED BANK", "BOSTON", "MA"),
+	}, "\r\n")
+	if err := d.Read(strings.NewReader(fedACH), ""); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	return d
+}
+
+// TestRoutingDirectory__Read validates FedACH, Fedwire and check directories are read and merged
+func TestRoutingDirectory__Read(t *testing.T) {
+	d := mockRoutingDirectory(t)
+	if d.Len() != 5 {
+		t.Errorf("%d routing numbers", d.Len())
+	}
+	p, ok := d.Lookup("231380104")
+	if !ok {
+		t.Fatal("231380104 was not found")
+	}
+	if p.Name != "FIRST DESTINATION BANK" || p.Address != "100 MAIN ST" || p.City != "BOSTON" || p.State != "MA" ||
+		p.PostalCode != "02110-1234" || p.PhoneNumber != "6175551234" || !p.ACH || p.Wire || p.Check || !p.Active() {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+	if p.Revised.Format("2006-01-02") != "2019-07-28" {
+		t.Errorf("Revised %v", p.Revised)
+	}
+	if p, ok := d.Lookup("011000028"); !ok || p.Active() || p.NewRoutingNumber != "231380104" {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+	if _, ok := d.Lookup("231380105"); ok {
+		t.Error("231380105 was found")
+	}
+
+	fedwire := mockFedwireLine("231380104", "First Destination Bank", "BOSTON", "MA", "Y") + "\n" +
+		mockFedwireLine("026009593", "Wire Only Bank", "NEW YORK", "NY", "Y") + "\n"
+	if err := d.Read(strings.NewReader(fedwire), ""); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	check := "routing number,name,city,state\n231380104,First Destination Bank,BOSTON,MA\n091000019\n"
+	if err := d.Read(strings.NewReader(check), RoutingDirectoryCheck); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if d.Len() != 7 {
+		t.Errorf("%d routing numbers", d.Len())
+	}
+	if p, ok := d.Lookup("231380104"); !ok || !p.ACH || !p.Wire || !p.Check || p.Name != "FIRST DESTINATION BANK" ||
+		p.Revised.Format("2006-01-02") != "2019-07-28" {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+	if p, ok := d.Lookup("026009593"); !ok || p.ACH || !p.Wire || p.Check || p.Name != "Wire Only Bank" ||
+		p.City != "NEW YORK" || p.Revised.Format("2006-01-02") != "2019-05-12" {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+	if p, ok := d.Lookup("91000019"); !ok || !p.Check {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+
+	// lines which can't be read
+	for _, test := range []struct {
+		data   string
+		format RoutingDirectoryFormat
+	}{
+		{"not a routing directory", ""},
+		{mockFedACHLine("231380104", "", "BANK", "BOSTON", "MA") + "\n23138010X" + strings.Repeat(" ", 150), ""},
+		{mockFedwireLine("231380104", "Bank", "BOSTON", "MA", "Y")[:50], RoutingDirectoryFedwire},
+		{"231380104,Bank\nbank,231380104\n", RoutingDirectoryCheck},
+		{"231380104", "aba"},
+	} {
+		if err := NewRoutingDirectory().Read(strings.NewReader(test.data), test.format); err == nil {
+			t.Errorf("%q: expected error", test.data)
+		} else if _, ok := err.(*FileError); !ok {
+			t.Errorf("%T: %s", err, err)
+		}
+	}
+}
+
+// TestLoadRoutingDirectory validates routing directory files are loaded from disk
+func TestLoadRoutingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	fedACH := filepath.Join(dir, "FedACHdir.txt")
+	fedwire := filepath.Join(dir, "fpddir.txt")
+	if err := os.WriteFile(fedACH, []byte(mockFedACHLine("231380104", "", "BANK", "BOSTON", "MA")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fedwire, []byte(mockFedwireLine("026009593", "Wire Bank", "NEW YORK", "NY", "N")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := LoadRoutingDirectory(fedACH, fedwire)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if p, ok := d.Lookup("231380104"); !ok || !p.ACH {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+	if p, ok := d.Lookup("026009593"); !ok || p.Wire {
+		t.Errorf("unexpected RoutingParticipant: %#v", p)
+	}
+	if _, err := LoadRoutingDirectory(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestFile__ValidateRoutingDirectoryOption validates routing numbers of a File which are unknown, changed or not
+// check eligible are reported with ValidateRoutingDirectoryOption
+func TestFile__ValidateRoutingDirectoryOption(t *testing.T) {
+	fd, err := os.Open(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	defer fd.Close()
+	file, err := NewReader(fd, ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	d := mockRoutingDirectory(t)
+	report := file.ValidationReport(ValidateRoutingDirectoryOption(d))
+	if !report.Valid() {
+		t.Fatalf("unexpected Violations: %v", report.Violations)
+	}
+
+	// an unknown destination and a changed payor
+	file.CashLetters[0].CashLetterHeader.DestinationRoutingNumber = "231380117"
+	cd := file.CashLetters[0].Bundles[0].Checks[0]
+	cd.PayorBankRoutingNumber, cd.PayorBankCheckDigit = "01100002", "8"
+	if err := file.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if err := file.Validate(ValidateRoutingDirectoryOption(d)); err == nil {
+		t.Error("expected error")
+	}
+	report = file.ValidationReport(ValidateRoutingDirectoryOption(d))
+	if len(report.Violations) != 2 {
+		t.Fatalf("unexpected Violations: %v", report.Violations)
+	}
+	if v := report.Violations[0]; v.Path != "/cashLetters/0/cashLetterHeader" || v.FieldName != "DestinationRoutingNumber" ||
+		v.Value != "231380117" || v.Msg != msgRoutingDirectoryUnknown {
+		t.Errorf("unexpected Violation: %v", v)
+	}
+	if v := report.Violations[1]; v.Path != "/cashLetters/0/bundles/0/checks/0" || v.FieldName != "PayorBankRoutingNumber" ||
+		v.Value != "011000028" || v.Msg != fmt.Sprintf(msgRoutingDirectoryChanged, "231380104") {
+		t.Errorf("unexpected Violation: %v", v)
+	}
+
+	// with a check directory, routing numbers must be check eligible
+	file.CashLetters[0].CashLetterHeader.DestinationRoutingNumber = "061000146"
+	cd.PayorBankRoutingNumber, cd.PayorBankCheckDigit = "12200066", "1"
+	if err := d.Read(strings.NewReader("061000146\n"), RoutingDirectoryCheck); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	report = file.ValidationReport(ValidateRoutingDirectoryOption(d))
+	if len(report.Violations) != 1 || report.Violations[0].FieldName != "PayorBankRoutingNumber" ||
+		report.Violations[0].Value != "122000661" || report.Violations[0].Msg != msgRoutingDirectoryCheck {
+		t.Errorf("unexpected Violations: %v", report.Violations)
+	}
+}
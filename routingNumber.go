@@ -0,0 +1,169 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Errors specific to routing numbers
+var (
+	msgRoutingNumberDigits     = "is not a %v digit routing number"
+	msgRoutingNumberCheckDigit = "has check digit %v, the ABA check digit is %v"
+)
+
+// routingNumberWeights are the weights of the ABA mod-10 check digit of each digit of a routing number
+var routingNumberWeights = [8]int{3, 7, 1, 3, 7, 1, 3, 7}
+
+// RoutingNumberCheckDigit returns the ABA mod-10 check digit of the first 8 digits of an 8 or 9 digit routing
+// number, e.g. for the PayorBankCheckDigit of a CheckDetail's PayorBankRoutingNumber.
+func RoutingNumberCheckDigit(routingNumber string) (string, error) {
+	if len(routingNumber) != 8 && len(routingNumber) != 9 {
+		return "", &FieldError{FieldName: "RoutingNumber", Value: routingNumber, Msg: fmt.Sprintf(msgRoutingNumberDigits, "8 or 9")}
+	}
+	sum := 0
+	for i, weight := range routingNumberWeights {
+		c := routingNumber[i]
+		if c < '0' || c > '9' {
+			return "", &FieldError{FieldName: "RoutingNumber", Value: routingNumber, Msg: fmt.Sprintf(msgRoutingNumberDigits, "8 or 9")}
+		}
+		sum += int(c-'0') * weight
+	}
+	return fmt.Sprint((10 - sum%10) % 10), nil
+}
+
+// ValidateRoutingNumber returns a FieldError when a routing number isn't 9 digits with a valid ABA mod-10 check digit
+func ValidateRoutingNumber(routingNumber string) error {
+	return checkRoutingNumber("RoutingNumber", routingNumber)
+}
+
+// checkRoutingNumber returns a FieldError for fieldName when routingNumber isn't 9 digits with a valid check digit
+func checkRoutingNumber(fieldName, routingNumber string) error {
+	if len(routingNumber) != 9 {
+		return &FieldError{FieldName: fieldName, Value: routingNumber, Msg: fmt.Sprintf(msgRoutingNumberDigits, 9)}
+	}
+	checkDigit, err := RoutingNumberCheckDigit(routingNumber)
+	if err != nil {
+		return &FieldError{FieldName: fieldName, Value: routingNumber, Msg: fmt.Sprintf(msgRoutingNumberDigits, 9)}
+	}
+	if routingNumber[8:] != checkDigit {
+		return &FieldError{FieldName: fieldName, Value: routingNumber,
+			Msg: fmt.Sprintf(msgRoutingNumberCheckDigit, routingNumber[8:], checkDigit)}
+	}
+	return nil
+}
+
+// ValidateRoutingNumbersOption validates the ABA check digit of the routing number fields X9 requires to be
+// routing numbers: the PayorBankRoutingNumber and PayorBankCheckDigit of CheckDetail and ReturnDetail records, the
+// DestinationRoutingNumber of AccountTotalsDetail, NonHitTotalsDetail and BoxSummary records, and the
+// CashLetterRoutingNumber of RoutingNumberSummary records. Fields which are blank are not validated.
+func ValidateRoutingNumbersOption() ValidateOption {
+	return func(o *validateOptions) {
+		o.routingNumbers = true
+	}
+}
+
+// ValidateAllRoutingNumbersOption validates the routing numbers of ValidateRoutingNumbersOption, and of the fields
+// where X9 also allows a number which identifies a non-financial institution, such as the FileHeader's
+// ImmediateDestination and ImmediateOrigin and the ECEInstitutionRoutingNumber of headers and image views.
+func ValidateAllRoutingNumbersOption() ValidateOption {
+	return func(o *validateOptions) {
+		o.routingNumbers = true
+		o.nonFinancialRoutingNumbers = true
+	}
+}
+
+// routingNumberField is a routing number field of a record
+type routingNumberField struct {
+	name, value string
+	// nonFinancial is true when X9 allows the field to identify a non-financial institution
+	nonFinancial bool
+}
+
+// routingNumberFields returns the routing number fields of a record
+func routingNumberFields(rec interface{}) []routingNumberField {
+	switch r := rec.(type) {
+	case *FileHeader:
+		return []routingNumberField{{"ImmediateDestination", r.ImmediateDestination, true}, {"ImmediateOrigin", r.ImmediateOrigin, true}}
+	case *CashLetterHeader:
+		return []routingNumberField{{"DestinationRoutingNumber", r.DestinationRoutingNumber, true},
+			{"ECEInstitutionRoutingNumber", r.ECEInstitutionRoutingNumber, true}}
+	case *BundleHeader:
+		return []routingNumberField{{"DestinationRoutingNumber", r.DestinationRoutingNumber, true},
+			{"ECEInstitutionRoutingNumber", r.ECEInstitutionRoutingNumber, true},
+			{"ReturnLocationRoutingNumber", r.ReturnLocationRoutingNumber, true}}
+	case *CheckDetail:
+		// the check digit is its own field
+		return []routingNumberField{{"PayorBankCheckDigit", payorBankRoutingNumber(r.PayorBankRoutingNumber, r.PayorBankCheckDigit), false}}
+	case *ReturnDetail:
+		return []routingNumberField{{"PayorBankCheckDigit", payorBankRoutingNumber(r.PayorBankRoutingNumber, r.PayorBankCheckDigit), false}}
+	case *CheckDetailAddendumA:
+		return []routingNumberField{{"ReturnLocationRoutingNumber", r.ReturnLocationRoutingNumber, true}}
+	case *CheckDetailAddendumC:
+		return []routingNumberField{{"EndorsingBankRoutingNumber", r.EndorsingBankRoutingNumber, true}}
+	case *ReturnDetailAddendumA:
+		return []routingNumberField{{"ReturnLocationRoutingNumber", r.ReturnLocationRoutingNumber, true}}
+	case *ReturnDetailAddendumD:
+		return []routingNumberField{{"EndorsingBankRoutingNumber", r.EndorsingBankRoutingNumber, true}}
+	case *ImageViewDetail:
+		return []routingNumberField{{"ImageCreatorRoutingNumber", r.ImageCreatorRoutingNumber, true}}
+	case *ImageViewData:
+		return []routingNumberField{{"EceInstitutionRoutingNumber", r.EceInstitutionRoutingNumber, true}}
+	case *Credit:
+		// assigned by the posting bank to identify the credit
+		return []routingNumberField{{"PayorBankRoutingNumber", r.PayorBankRoutingNumber, true}}
+	case *CreditItem:
+		return []routingNumberField{{"PostingBankRoutingNumber", r.PostingBankRoutingNumber, true}}
+	case *AccountTotalsDetail:
+		return []routingNumberField{{"DestinationRoutingNumber", r.DestinationRoutingNumber, false}}
+	case *NonHitTotalsDetail:
+		return []routingNumberField{{"DestinationRoutingNumber", r.DestinationRoutingNumber, false}}
+	case *BoxSummary:
+		return []routingNumberField{{"DestinationRoutingNumber", r.DestinationRoutingNumber, false}}
+	case *RoutingNumberSummary:
+		return []routingNumberField{{"CashLetterRoutingNumber", r.CashLetterRoutingNumber, false}}
+	case *UserPayeeEndorsement:
+		return []routingNumberField{{"BankRoutingNumber", r.BankRoutingNumber, true}}
+	}
+	return nil
+}
+
+// payorBankRoutingNumber joins a PayorBankRoutingNumber and PayorBankCheckDigit, or returns blank when the
+// PayorBankRoutingNumber is blank
+func payorBankRoutingNumber(routingNumber, checkDigit string) string {
+	if strings.TrimSpace(routingNumber) == "" {
+		return ""
+	}
+	if len(routingNumber) < 8 {
+		routingNumber = strings.Repeat("0", 8-len(routingNumber)) + routingNumber
+	}
+	if checkDigit == "" {
+		// a missing check digit isn't a valid check digit
+		checkDigit = " "
+	}
+	return routingNumber + checkDigit
+}
+
+// routingNumberErrors returns a FieldError for each routing number field of a record with an invalid check digit.
+// Fields where X9 allows a number which identifies a non-financial institution are only validated when
+// nonFinancial is true.
+func routingNumberErrors(rec interface{}, nonFinancial bool) []error {
+	var errs []error
+	for _, field := range routingNumberFields(rec) {
+		if (field.nonFinancial && !nonFinancial) || strings.TrimSpace(field.value) == "" {
+			continue
+		}
+		// routing numbers are written zero padded
+		value := field.value
+		if len(value) < 9 {
+			value = strings.Repeat("0", 9-len(value)) + value
+		}
+		if err := checkRoutingNumber(field.name, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -31,6 +31,8 @@ type RoutingNumberSummary struct {
 	validator
 	// converters is composed for imagecashletter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewRoutingNumberSummary returns a new RoutingNumberSummary with default values for non exported fields
@@ -60,7 +62,7 @@ func (rns *RoutingNumberSummary) Parse(record string) {
 	// 12-25
 	rns.RoutingNumberTotalAmount = rns.parseNumField(record[11:25])
 	// 26-31
-	rns.RoutingNumberItemCount = rns.parseNumField(record[26:31])
+	rns.RoutingNumberItemCount = rns.parseNumField(record[25:31])
 	// 32-55
 	rns.UserField = rns.parseStringField(record[31:55])
 	// 56-80
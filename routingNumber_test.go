@@ -0,0 +1,96 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRoutingNumberCheckDigit validates the ABA check digit of routing numbers is computed
+func TestRoutingNumberCheckDigit(t *testing.T) {
+	for routingNumber, expected := range map[string]string{
+		"23138010":  "4",
+		"12104288":  "2",
+		"031300012": "2",
+		"00000000":  "0",
+		"09100001":  "9",
+	} {
+		checkDigit, err := RoutingNumberCheckDigit(routingNumber)
+		if err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+		if checkDigit != expected {
+			t.Errorf("%s: check digit %s, expected %s", routingNumber, checkDigit, expected)
+		}
+	}
+	for _, routingNumber := range []string{"", "1234567", "1234567890", "1234-567", "12345X78"} {
+		if _, err := RoutingNumberCheckDigit(routingNumber); err == nil {
+			t.Errorf("%q: expected error", routingNumber)
+		}
+	}
+}
+
+// TestValidateRoutingNumber validates routing numbers are 9 digits with a valid check digit
+func TestValidateRoutingNumber(t *testing.T) {
+	if err := ValidateRoutingNumber("231380104"); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	for _, routingNumber := range []string{"231380105", "23138010", "23138010X", "ABCDEFGHI"} {
+		err := ValidateRoutingNumber(routingNumber)
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "RoutingNumber" || e.Value != routingNumber {
+			t.Errorf("%q: %T: %s", routingNumber, err, err)
+		}
+	}
+}
+
+// TestFile__ValidateRoutingNumbersOption validates the routing numbers of a File are validated with
+// ValidateRoutingNumbersOption and ValidateAllRoutingNumbersOption
+func TestFile__ValidateRoutingNumbersOption(t *testing.T) {
+	fd, err := os.Open(filepath.Join("test", "testdata", "valid-ascii.x937"))
+	if err != nil {
+		t.Fatalf("Can not open local file: %s: \n", err)
+	}
+	defer fd.Close()
+	file, err := NewReader(fd, ReadVariableLineLengthOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := file.Validate(ValidateAllRoutingNumbersOption()); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	// a PayorBankCheckDigit which doesn't match the PayorBankRoutingNumber
+	cd := file.CashLetters[0].Bundles[0].Checks[0]
+	checkDigit, _ := RoutingNumberCheckDigit(cd.PayorBankRoutingNumber)
+	cd.PayorBankCheckDigit = "0"
+	if checkDigit == "0" {
+		cd.PayorBankCheckDigit = "1"
+	}
+	if err := file.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	report := file.ValidationReport(ValidateRoutingNumbersOption())
+	if len(report.Violations) != 1 || report.Violations[0].Path != "/cashLetters/0/bundles/0/checks/0" ||
+		report.Violations[0].FieldName != "PayorBankCheckDigit" || report.Violations[0].Severity != SeverityError {
+		t.Errorf("unexpected Violations: %v", report.Violations)
+	}
+	cd.PayorBankCheckDigit = checkDigit
+
+	// fields which may identify a non-financial institution are only validated with ValidateAllRoutingNumbersOption
+	file.Header.ImmediateOrigin = "123456789"
+	if err := file.Validate(ValidateRoutingNumbersOption()); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	report = file.ValidationReport(ValidateAllRoutingNumbersOption())
+	if len(report.Violations) != 1 || report.Violations[0].Path != "/fileHeader" ||
+		report.Violations[0].FieldName != "ImmediateOrigin" {
+		t.Errorf("unexpected Violations: %v", report.Violations)
+	}
+	if err := file.Validate(ValidateAllRoutingNumbersOption()); err == nil {
+		t.Error("expected error")
+	}
+}
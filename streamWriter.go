@@ -0,0 +1,370 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"io"
+)
+
+// StreamWriter writes an ImageCashLetter/X9 File one record at a time without holding the complete File
+// in memory. BundleControl, CashLetterControl and FileControl records are computed from the records written
+// and emitted when the Bundle, CashLetter or File is closed.
+//
+// A typical file is written as:
+//
+//	sw := NewStreamWriter(w)
+//	sw.WriteFileHeader(fh)
+//	sw.WriteCashLetterHeader(clh)
+//	sw.WriteBundleHeader(bh)
+//	sw.WriteCheckDetail(cd) // repeated for each item
+//	sw.CloseBundle(nil)
+//	sw.CloseCashLetter(nil)
+//	sw.Close(nil)
+//
+// Callers should use NewStreamWriter and apply WriterOptions as they would for a Writer.
+type StreamWriter struct {
+	w *Writer
+	// headerWritten is set once the FileHeader has been written
+	headerWritten bool
+	// cashLetter is the CashLetter currently being written, nil outside of a CashLetter
+	cashLetter *CashLetter
+	// bundle is the Bundle currently being written, nil outside of a Bundle. Only the last item
+	// written is retained.
+	bundle *Bundle
+	// fileControl holds the FileControl totals of the records written so far
+	fileControl FileControl
+}
+
+// NewStreamWriter returns a new StreamWriter that writes to w.
+func NewStreamWriter(w io.Writer, opts ...WriterOption) *StreamWriter {
+	return &StreamWriter{
+		w:           NewWriter(w, opts...),
+		fileControl: NewFileControl(),
+	}
+}
+
+// WriteFileHeader writes the FileHeader which must be the first record of the file.
+func (sw *StreamWriter) WriteFileHeader(fh FileHeader) error {
+	if sw.headerWritten {
+		return &FileError{FieldName: "FileHeader", Msg: msgFileHeader}
+	}
+	if err := fh.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(&fh); err != nil {
+		return err
+	}
+	sw.headerWritten = true
+	return nil
+}
+
+// WriteCashLetterHeader writes a CashLetterHeader and begins a new CashLetter.
+func (sw *StreamWriter) WriteCashLetterHeader(clh *CashLetterHeader) error {
+	if !sw.headerWritten {
+		return &FileError{FieldName: "FileHeader", Msg: msgFileHeader}
+	}
+	if sw.cashLetter != nil {
+		return &FileError{FieldName: "CashLetterHeader", Msg: msgFileCashLetterInside}
+	}
+	if err := clh.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(clh); err != nil {
+		return err
+	}
+	cl := NewCashLetter(clh)
+	sw.cashLetter = &cl
+	return nil
+}
+
+// WriteCreditItem writes a CreditItem to the current CashLetter.
+func (sw *StreamWriter) WriteCreditItem(ci *CreditItem) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "CreditItem", Msg: msgFileCreditItem}
+	}
+	if err := ci.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(ci); err != nil {
+		return err
+	}
+	clc := sw.cashLetter.CashLetterControl
+	clc.CashLetterItemsCount = clc.CashLetterItemsCount + 1
+	clc.CreditTotalIndicator = 1
+	sw.fileControl.CreditTotalIndicator = 1
+	return nil
+}
+
+// WriteAccountTotalsDetail writes an AccountTotalsDetail to the current CashLetter.
+func (sw *StreamWriter) WriteAccountTotalsDetail(atd *AccountTotalsDetail) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "AccountTotalsDetail", Msg: msgFileAccountTotalsDetail}
+	}
+	if err := atd.Validate(); err != nil {
+		return err
+	}
+	return sw.w.writeLine(atd)
+}
+
+// WriteNonHitTotalsDetail writes a NonHitTotalsDetail to the current CashLetter.
+func (sw *StreamWriter) WriteNonHitTotalsDetail(nhtd *NonHitTotalsDetail) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "NonHitTotalsDetail", Msg: msgFileNonHitTotalsDetail}
+	}
+	if err := nhtd.Validate(); err != nil {
+		return err
+	}
+	return sw.w.writeLine(nhtd)
+}
+
+// WriteBundleHeader writes a BundleHeader and begins a new Bundle within the current CashLetter.
+func (sw *StreamWriter) WriteBundleHeader(bh *BundleHeader) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "BundleHeader", Msg: msgFileCashLetterOutside}
+	}
+	if sw.bundle != nil {
+		return &FileError{FieldName: "BundleHeader", Msg: msgFileBundleInside}
+	}
+	if err := bh.Validate(); err != nil {
+		return err
+	}
+	// Validate a Bundle is allowed for this CashLetter
+	b := NewBundle(bh)
+	sw.cashLetter.Bundles = []*Bundle{b}
+	if err := sw.cashLetter.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(bh); err != nil {
+		return err
+	}
+	sw.bundle = b
+	return nil
+}
+
+// WriteCredit writes a Credit to the current Bundle. Credits should precede the items of the Bundle and are
+// included in the control totals.
+func (sw *StreamWriter) WriteCredit(cr *Credit) error {
+	if sw.bundle == nil {
+		return &FileError{FieldName: "Credit", Msg: msgFileBundleOutside}
+	}
+	if err := cr.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(cr); err != nil {
+		return err
+	}
+
+	bc := sw.bundle.BundleControl
+	bc.BundleItemsCount = bc.BundleItemsCount + 1
+	bc.BundleTotalAmount = bc.BundleTotalAmount + cr.ItemAmount
+	bc.CreditTotalIndicator = 1
+	sw.addItem(cr.ItemAmount, 0)
+	sw.cashLetter.CashLetterControl.CreditTotalIndicator = 1
+	sw.fileControl.CreditTotalIndicator = 1
+	return nil
+}
+
+// WriteUserRecord writes a UserRecord to the current CashLetter, following the last record written.
+func (sw *StreamWriter) WriteUserRecord(ur UserRecord) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "UserRecord", Msg: msgFileUserRecord}
+	}
+	if err := ur.Validate(); err != nil {
+		return err
+	}
+	return sw.w.writeLine(ur)
+}
+
+// WriteCheckDetail writes a CheckDetail followed by its CheckDetailAddendum, ImageView and User records to the
+// current Bundle. The CheckDetail is not retained once written.
+func (sw *StreamWriter) WriteCheckDetail(cd *CheckDetail) error {
+	if sw.bundle == nil {
+		return &FileError{FieldName: "CheckDetail", Msg: msgFileBundleOutside}
+	}
+	if err := cd.Validate(); err != nil {
+		return err
+	}
+	if err := sw.bundle.ValidateForwardItems(cd); err != nil {
+		return err
+	}
+	// Validate addendum counts for this item only
+	sw.bundle.Checks = []*CheckDetail{cd}
+	sw.bundle.Returns = nil
+	if err := sw.bundle.Validate(); err != nil {
+		return err
+	}
+
+	if err := sw.w.writeCheck(cd); err != nil {
+		return err
+	}
+
+	bc := sw.bundle.BundleControl
+	bc.BundleItemsCount = bc.BundleItemsCount + 1
+	bc.BundleTotalAmount = bc.BundleTotalAmount + cd.ItemAmount
+	if cd.MICRValidIndicator == 1 {
+		bc.MICRValidTotalAmount = bc.MICRValidTotalAmount + cd.ItemAmount
+	}
+	bc.BundleImagesCount = bc.BundleImagesCount + len(cd.ImageViewDetail)
+	sw.addItem(cd.ItemAmount, len(cd.ImageViewDetail))
+	return nil
+}
+
+// WriteReturnDetail writes a ReturnDetail followed by its ReturnDetailAddendum, ImageView and User records to
+// the current Bundle. The ReturnDetail is not retained once written.
+func (sw *StreamWriter) WriteReturnDetail(rd *ReturnDetail) error {
+	if sw.bundle == nil {
+		return &FileError{FieldName: "ReturnDetail", Msg: msgFileBundleOutside}
+	}
+	if err := rd.Validate(); err != nil {
+		return err
+	}
+	if err := sw.bundle.ValidateReturnItems(rd); err != nil {
+		return err
+	}
+	// Validate addendum counts for this item only
+	sw.bundle.Checks = nil
+	sw.bundle.Returns = []*ReturnDetail{rd}
+	if err := sw.bundle.Validate(); err != nil {
+		return err
+	}
+
+	if err := sw.w.writeReturn(rd); err != nil {
+		return err
+	}
+
+	bc := sw.bundle.BundleControl
+	bc.BundleItemsCount = bc.BundleItemsCount + 1
+	bc.BundleTotalAmount = bc.BundleTotalAmount + rd.ItemAmount
+	bc.BundleImagesCount = bc.BundleImagesCount + len(rd.ImageViewDetail)
+	sw.addItem(rd.ItemAmount, len(rd.ImageViewDetail))
+	return nil
+}
+
+// addItem adds an item to the CashLetterControl and FileControl totals.
+func (sw *StreamWriter) addItem(amount, images int) {
+	clc := sw.cashLetter.CashLetterControl
+	clc.CashLetterItemsCount = clc.CashLetterItemsCount + 1
+	clc.CashLetterTotalAmount = clc.CashLetterTotalAmount + amount
+	clc.CashLetterImagesCount = clc.CashLetterImagesCount + images
+
+	sw.fileControl.TotalItemCount = sw.fileControl.TotalItemCount + 1
+	sw.fileControl.FileTotalAmount = sw.fileControl.FileTotalAmount + amount
+}
+
+// CloseBundle writes the BundleControl of the current Bundle from the items written to it. UserField is
+// copied from bc which may be nil.
+func (sw *StreamWriter) CloseBundle(bc *BundleControl) error {
+	if sw.bundle == nil {
+		return &FileError{FieldName: "BundleControl", Msg: msgFileBundleControl}
+	}
+	control := sw.bundle.BundleControl
+	if control.BundleItemsCount == 0 {
+		return &BundleError{BundleSequenceNumber: sw.bundle.BundleHeader.BundleSequenceNumber, FieldName: "entries", Msg: msgBundleEntries}
+	}
+	if bc != nil {
+		control.UserField = bc.UserField
+	}
+	if err := control.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(control); err != nil {
+		return err
+	}
+	sw.cashLetter.CashLetterControl.CashLetterBundleCount++
+	sw.bundle = nil
+	return nil
+}
+
+// WriteBoxSummary closes any open Bundle and writes a BoxSummary to the current CashLetter.
+func (sw *StreamWriter) WriteBoxSummary(bs *BoxSummary) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "BoxSummary", Msg: msgFileBoxSummary}
+	}
+	if sw.bundle != nil {
+		if err := sw.CloseBundle(nil); err != nil {
+			return err
+		}
+	}
+	if err := bs.Validate(); err != nil {
+		return err
+	}
+	return sw.w.writeLine(bs)
+}
+
+// WriteRoutingNumberSummary writes a RoutingNumberSummary to the current CashLetter.
+func (sw *StreamWriter) WriteRoutingNumberSummary(rns *RoutingNumberSummary) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "RoutingNumberSummary", Msg: msgFileRoutingNumberSummary}
+	}
+	if err := rns.Validate(); err != nil {
+		return err
+	}
+	// Validate the RoutingNumberSummary is allowed for this CashLetter
+	sw.cashLetter.RoutingNumberSummary = []*RoutingNumberSummary{rns}
+	if err := sw.cashLetter.Validate(); err != nil {
+		return err
+	}
+	return sw.w.writeLine(rns)
+}
+
+// CloseCashLetter closes any open Bundle and writes the CashLetterControl of the current CashLetter from the
+// records written to it. ECEInstitutionName and SettlementDate are copied from clc which may be nil.
+func (sw *StreamWriter) CloseCashLetter(clc *CashLetterControl) error {
+	if sw.cashLetter == nil {
+		return &FileError{FieldName: "CashLetterControl", Msg: msgFileCashLetterControl}
+	}
+	if sw.bundle != nil {
+		if err := sw.CloseBundle(nil); err != nil {
+			return err
+		}
+	}
+	control := sw.cashLetter.CashLetterControl
+	header := sw.cashLetter.CashLetterHeader
+	if clc != nil && clc.ECEInstitutionName != "" {
+		control.ECEInstitutionName = clc.ECEInstitutionName
+	} else {
+		control.ECEInstitutionName = header.ECEInstitutionRoutingNumber
+	}
+	if clc != nil && !clc.SettlementDate.IsZero() {
+		control.SettlementDate = clc.SettlementDate
+	}
+	if err := control.Validate(header.CollectionTypeIndicator); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(control); err != nil {
+		return err
+	}
+	sw.fileControl.CashLetterCount = sw.fileControl.CashLetterCount + 1
+	sw.cashLetter = nil
+	return nil
+}
+
+// Close closes any open Bundle and CashLetter, writes the FileControl computed from the records written and
+// flushes the underlying io.Writer. ImmediateOriginContactName and ImmediateOriginContactPhoneNumber are copied
+// from fc which may be nil.
+func (sw *StreamWriter) Close(fc *FileControl) error {
+	if !sw.headerWritten {
+		return &FileError{FieldName: "FileHeader", Msg: msgFileHeader}
+	}
+	if sw.cashLetter != nil {
+		if err := sw.CloseCashLetter(nil); err != nil {
+			return err
+		}
+	}
+	control := sw.fileControl
+	if fc != nil {
+		control.ImmediateOriginContactName = fc.ImmediateOriginContactName
+		control.ImmediateOriginContactPhoneNumber = fc.ImmediateOriginContactPhoneNumber
+	}
+	// add 1 for the FileControl
+	control.TotalRecordCount = sw.w.lineNum + 1
+	if err := control.Validate(); err != nil {
+		return err
+	}
+	if err := sw.w.writeLine(&control); err != nil {
+		return err
+	}
+	return sw.w.w.Flush()
+}
@@ -0,0 +1,312 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// mockStreamFile creates a File with a CreditItem, a Credit, forward and return bundles
+func mockStreamFile(t *testing.T) *File {
+	file := NewFile().SetHeader(mockFileHeader())
+
+	cd := mockCheckDetail()
+	cd.AddCheckDetailAddendumA(mockCheckDetailAddendumA())
+	cd.AddCheckDetailAddendumB(mockCheckDetailAddendumB())
+	cd.AddCheckDetailAddendumC(mockCheckDetailAddendumC())
+	cd.AddImageViewDetail(mockImageViewDetail())
+	cd.AddImageViewData(mockImageViewData())
+	cd.AddImageViewAnalysis(mockImageViewAnalysis())
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCredit(mockCredit())
+	bundle.AddCheckDetail(cd)
+	bundle.AddCheckDetail(cd)
+
+	rd := mockReturnDetail()
+	rd.AddReturnDetailAddendumA(mockReturnDetailAddendumA())
+	rd.AddReturnDetailAddendumB(mockReturnDetailAddendumB())
+	rd.AddReturnDetailAddendumC(mockReturnDetailAddendumC())
+	rd.AddReturnDetailAddendumD(mockReturnDetailAddendumD())
+	rd.AddImageViewDetail(mockImageViewDetail())
+	rd.AddImageViewData(mockImageViewData())
+	rd.AddImageViewAnalysis(mockImageViewAnalysis())
+	returnBundle := NewBundle(mockBundleHeader())
+	returnBundle.BundleHeader.BundleSequenceNumber = "2"
+	returnBundle.AddReturnDetail(rd)
+
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddCreditItem(mockCreditItem())
+	cl.AddBundle(bundle)
+	cl.AddBundle(returnBundle)
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file.AddCashLetter(cl)
+
+	clTwo := NewCashLetter(mockCashLetterHeader())
+	clTwo.CashLetterHeader.CashLetterID = "A2"
+	clTwo.AddBundle(bundle)
+	if err := clTwo.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file.AddCashLetter(clTwo)
+
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	return file
+}
+
+// streamFile writes file using a StreamWriter
+func streamFile(sw *StreamWriter, file *File) error {
+	if err := sw.WriteFileHeader(file.Header); err != nil {
+		return err
+	}
+	for _, cl := range file.CashLetters {
+		if err := sw.WriteCashLetterHeader(cl.CashLetterHeader); err != nil {
+			return err
+		}
+		for _, ci := range cl.CreditItems {
+			if err := sw.WriteCreditItem(ci); err != nil {
+				return err
+			}
+		}
+		for _, ur := range cl.UserRecords {
+			if err := sw.WriteUserRecord(ur); err != nil {
+				return err
+			}
+		}
+		for _, b := range cl.Bundles {
+			if err := sw.WriteBundleHeader(b.BundleHeader); err != nil {
+				return err
+			}
+			for _, cr := range b.Credits {
+				if err := sw.WriteCredit(cr); err != nil {
+					return err
+				}
+			}
+			for _, ur := range b.UserRecords {
+				if err := sw.WriteUserRecord(ur); err != nil {
+					return err
+				}
+			}
+			for _, cd := range b.Checks {
+				if err := sw.WriteCheckDetail(cd); err != nil {
+					return err
+				}
+			}
+			for _, rd := range b.Returns {
+				if err := sw.WriteReturnDetail(rd); err != nil {
+					return err
+				}
+			}
+			if err := sw.CloseBundle(b.BundleControl); err != nil {
+				return err
+			}
+		}
+		for _, bs := range cl.BoxSummary {
+			if err := sw.WriteBoxSummary(bs); err != nil {
+				return err
+			}
+		}
+		for _, rns := range cl.RoutingNumberSummary {
+			if err := sw.WriteRoutingNumberSummary(rns); err != nil {
+				return err
+			}
+		}
+		if err := sw.CloseCashLetter(cl.CashLetterControl); err != nil {
+			return err
+		}
+	}
+	return sw.Close(&file.Control)
+}
+
+// TestStreamWriter writes a File with a StreamWriter and compares it to the Writer output
+func TestStreamWriter(t *testing.T) {
+	file := mockStreamFile(t)
+
+	tests := map[string][]WriterOption{
+		"ascii":  nil,
+		"ebcdic": {WriteVariableLineLengthOption(), WriteEbcdicEncodingOption()},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			expected := &bytes.Buffer{}
+			if err := NewWriter(expected, opts...).Write(file); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+
+			b := &bytes.Buffer{}
+			if err := streamFile(NewStreamWriter(b, opts...), file); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			if !bytes.Equal(expected.Bytes(), b.Bytes()) {
+				t.Errorf("streamed file does not match written file")
+			}
+		})
+	}
+}
+
+// TestStreamWriter__UserRecords writes a File with User Records with a StreamWriter and compares it to the
+// Writer output
+func TestStreamWriter__UserRecords(t *testing.T) {
+	file := mockUserRecordsFile(t)
+
+	expected := &bytes.Buffer{}
+	if err := NewWriter(expected).Write(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	b := &bytes.Buffer{}
+	if err := streamFile(NewStreamWriter(b), file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if !bytes.Equal(expected.Bytes(), b.Bytes()) {
+		t.Errorf("streamed file does not match written file")
+	}
+
+	sw := NewStreamWriter(&bytes.Buffer{})
+	if err := sw.WriteUserRecord(mockUserGeneral()); err != nil {
+		if !strings.Contains(err.Error(), msgFileUserRecord) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestStreamWriter__RoutingNumberSummary writes RoutingNumberSummary records for the items of more than one
+// Bundle with a StreamWriter and compares it to the Writer output
+func TestStreamWriter__RoutingNumberSummary(t *testing.T) {
+	cl := mockRoutingNumberCashLetter()
+	if err := cl.Create(CreateRoutingNumberSummaryOption()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file := NewFile().SetHeader(mockFileHeader())
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	expected := &bytes.Buffer{}
+	if err := NewWriter(expected).Write(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	b := &bytes.Buffer{}
+	if err := streamFile(NewStreamWriter(b), file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if !bytes.Equal(expected.Bytes(), b.Bytes()) {
+		t.Errorf("streamed file does not match written file")
+	}
+
+	read, err := NewReader(strings.NewReader(b.String())).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := read.Validate(ValidateRoutingNumberSummaryOption()); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestStreamWriter__Totals ensures controls are computed when closed without templates
+func TestStreamWriter__Totals(t *testing.T) {
+	b := &bytes.Buffer{}
+	sw := NewStreamWriter(b)
+
+	if err := sw.WriteFileHeader(mockFileHeader()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := sw.WriteCashLetterHeader(mockCashLetterHeader()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := sw.WriteBundleHeader(mockBundleHeader()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	for i := 0; i < 3; i++ {
+		cd := mockCheckDetail()
+		cd.AddCheckDetailAddendumA(mockCheckDetailAddendumA())
+		cd.AddCheckDetailAddendumB(mockCheckDetailAddendumB())
+		cd.AddCheckDetailAddendumC(mockCheckDetailAddendumC())
+		if err := sw.WriteCheckDetail(cd); err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+	}
+	// Close the open Bundle and CashLetter
+	if err := sw.Close(nil); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	r := NewReader(strings.NewReader(b.String()))
+	file, err := r.Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := file.Validate(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if file.Control.TotalRecordCount != 18 {
+		t.Errorf("TotalRecordCount: %d", file.Control.TotalRecordCount)
+	}
+	if file.Control.TotalItemCount != 3 {
+		t.Errorf("TotalItemCount: %d", file.Control.TotalItemCount)
+	}
+	if file.Control.FileTotalAmount != 300000 {
+		t.Errorf("FileTotalAmount: %d", file.Control.FileTotalAmount)
+	}
+	bc := file.CashLetters[0].Bundles[0].BundleControl
+	if bc.BundleItemsCount != 3 || bc.BundleTotalAmount != 300000 {
+		t.Errorf("BundleControl: %s", bc.String())
+	}
+	clc := file.CashLetters[0].CashLetterControl
+	if clc.CashLetterBundleCount != 1 || clc.CashLetterItemsCount != 3 {
+		t.Errorf("CashLetterControl: %s", clc.String())
+	}
+}
+
+// TestStreamWriter__OrderErr ensures records written out of order return an error
+func TestStreamWriter__OrderErr(t *testing.T) {
+	sw := NewStreamWriter(&bytes.Buffer{})
+	if err := sw.WriteCashLetterHeader(mockCashLetterHeader()); err != nil {
+		if !strings.Contains(err.Error(), msgFileHeader) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+
+	if err := sw.WriteFileHeader(mockFileHeader()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := sw.WriteBundleHeader(mockBundleHeader()); err != nil {
+		if !strings.Contains(err.Error(), msgFileCashLetterOutside) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	if err := sw.WriteCheckDetail(mockCheckDetail()); err != nil {
+		if !strings.Contains(err.Error(), msgFileBundleOutside) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+
+	if err := sw.WriteCashLetterHeader(mockCashLetterHeader()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := sw.WriteBundleHeader(mockBundleHeader()); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := sw.CloseBundle(nil); err != nil {
+		if !strings.Contains(err.Error(), msgBundleEntries) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
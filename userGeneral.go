@@ -15,9 +15,9 @@ import (
 
 // The User General Format Record is conditional, and contains a user controlled number of fields.  The record is only
 // used based on clearing arrangements.  The Record can occur anywhere in the file based on those clearing arrangements.
-// Any totaling of dollar amounts would also be determined by clearing arrangements.  The current implementation of
-// the User General Format Record is for the Concrete Type Only.  For reader and writer implementation, please adjust
-// based on specific clearing arrangements, or contact MOOV for your particular implementation.
+// Any totaling of dollar amounts would also be determined by clearing arrangements.  The Reader parses User Records
+// which are not a UserPayeeEndorsement as a UserGeneral.  Formats defined by specific clearing arrangements can be
+// parsed as their own type by registering it with RegisterUserRecord.
 
 // UserGeneral Record
 type UserGeneral struct {
@@ -78,6 +78,8 @@ type UserGeneral struct {
 	validator
 	// converters is composed for image cash letter to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewUserGeneral returns a new UserGeneral with default values for non exported fields
@@ -88,6 +90,9 @@ func NewUserGeneral() *UserGeneral {
 }
 
 func (ug *UserGeneral) setRecordType() {
+	if ug == nil {
+		return
+	}
 	ug.recordType = "68"
 }
 
@@ -112,7 +117,9 @@ func (ug *UserGeneral) Parse(record string) {
 	// 39-45
 	ug.LengthUserData = ug.parseStringField(record[38:45])
 	// 46-45+(lud)
-	ug.UserData = ug.parseStringField(record[45 : 45+ug.parseNumField(ug.LengthUserData)])
+	if end := 45 + ug.parseNumField(ug.LengthUserData); end >= 45 && end <= len(record) {
+		ug.UserData = ug.parseStringField(record[45:end])
+	}
 }
 
 func (ug *UserGeneral) UnmarshalJSON(data []byte) error {
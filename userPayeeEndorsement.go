@@ -106,6 +106,8 @@ type UserPayeeEndorsement struct {
 	validator
 	// converters is composed for  to golang Converters
 	converters
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
 }
 
 // NewUserPayeeEndorsement returns a new UserPayeeEndorsement with default values for non exported fields
@@ -0,0 +1,247 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// User Records are conditional and can occur anywhere in a CashLetter based on clearing arrangements. The Reader
+// parses a User Record with a format registered with RegisterUserRecord as the registered type, UserRecordFormatType
+// 001 as a UserPayeeEndorsement and all other formats as a UserGeneral. A User Record which does not validate, or
+// would not be written back unchanged, as that type is kept as a UserRaw.
+//
+// User Records are kept with the record they follow and written back in the same place:
+//
+// FileHeader
+// CashLetterHeader Record
+// CreditItem, AccountTotalsDetail, NonHitTotalsDetail
+// CashLetter.UserRecords
+// BundleHeader Record
+// Credit
+// Bundle.UserRecords
+// CheckDetail or ReturnDetail
+// Addendum Records
+// ImageView Records
+// CheckDetail.UserRecords or ReturnDetail.UserRecords
+// BundleControl
+// CashLetterControl
+// FileControl
+//
+// User Records between or after Bundles are kept in CashLetter.UserRecords, and User Records between the addendum
+// and image view records of an item in its UserRecords. UserRecordPositions holds where each was read, so it is
+// written back there.
+
+// UserRecord is a User Record (type 68). UserGeneral, UserPayeeEndorsement and UserRaw are UserRecords.
+type UserRecord interface {
+	// Parse takes the input record string and parses the User Record values
+	Parse(record string)
+	// String writes the User Record to a string
+	String() string
+	// Validate performs imagecashletter format rule checks on the User Record
+	Validate() error
+}
+
+// userRecordPosition returns the position of the i-th of UserRecords with positions, no greater than last.
+// A UserRecord without a position is at missing.
+func userRecordPosition(positions []int, i, missing, last int) int {
+	p := missing
+	if i < len(positions) {
+		p = positions[i]
+	}
+	if p < 0 {
+		return 0
+	}
+	if p > last {
+		return last
+	}
+	return p
+}
+
+// UserRecords is an array of UserRecord which can be encoded to and decoded from JSON
+type UserRecords []UserRecord
+
+// UnmarshalJSON decodes each User Record as a UserRaw when a record is present, as the registered type for its
+// ownerIdentifier and userRecordFormatType, as a UserPayeeEndorsement for UserRecordFormatType 001 and as a
+// UserGeneral otherwise.
+func (urs *UserRecords) UnmarshalJSON(data []byte) error {
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return err
+	}
+	if records == nil {
+		*urs = nil
+		return nil
+	}
+	out := make(UserRecords, 0, len(records))
+	for _, data := range records {
+		var format struct {
+			OwnerIdentifier      string  `json:"ownerIdentifier"`
+			UserRecordFormatType string  `json:"userRecordFormatType"`
+			Record               *string `json:"record"`
+		}
+		if err := json.Unmarshal(data, &format); err != nil {
+			return err
+		}
+		var ur UserRecord
+		fn := registeredUserRecord(format.OwnerIdentifier, format.UserRecordFormatType)
+		switch {
+		case format.Record != nil:
+			ur = NewUserRaw()
+		case fn != nil:
+			ur = fn()
+		case format.UserRecordFormatType == "001":
+			ur = NewUserPayeeEndorsement()
+		default:
+			ur = NewUserGeneral()
+		}
+		if err := json.Unmarshal(data, ur); err != nil {
+			return err
+		}
+		out = append(out, ur)
+	}
+	*urs = out
+	return nil
+}
+
+// UserRecordFormat identifies the User Records of a format registered with RegisterUserRecord.
+type UserRecordFormat struct {
+	// OwnerIdentifier is the OwnerIdentifier of the User Records, an empty OwnerIdentifier matches
+	// User Records of any owner.
+	OwnerIdentifier string
+	// UserRecordFormatType is the UserRecordFormatType of the User Records
+	UserRecordFormatType string
+}
+
+var userRecordFormats = struct {
+	sync.RWMutex
+	m map[UserRecordFormat]func() UserRecord
+}{m: make(map[UserRecordFormat]func() UserRecord)}
+
+// RegisterUserRecord registers fn to create the UserRecord for User Records of format. The Reader parses
+// User Records of format with the UserRecord returned by fn, and UserRecords decodes them from JSON with it,
+// so they are written back by the Writer as the registered type. A registered UserRecord is used when it
+// validates and writes the User Record back unchanged, otherwise the User Record is kept as a UserRaw.
+//
+// Registering a nil fn removes the format.
+func RegisterUserRecord(format UserRecordFormat, fn func() UserRecord) {
+	userRecordFormats.Lock()
+	defer userRecordFormats.Unlock()
+	if fn == nil {
+		delete(userRecordFormats.m, format)
+		return
+	}
+	userRecordFormats.m[format] = fn
+}
+
+// registeredUserRecord returns the func registered for ownerIdentifier and userRecordFormatType, preferring
+// a format registered for the owner to one registered for any owner.
+func registeredUserRecord(ownerIdentifier, userRecordFormatType string) func() UserRecord {
+	userRecordFormats.RLock()
+	defer userRecordFormats.RUnlock()
+	if fn, ok := userRecordFormats.m[UserRecordFormat{ownerIdentifier, userRecordFormatType}]; ok {
+		return fn
+	}
+	return userRecordFormats.m[UserRecordFormat{UserRecordFormatType: userRecordFormatType}]
+}
+
+// userRecordFor parses record as the UserRecord registered for its OwnerIdentifier and UserRecordFormatType
+// or the UserRecord for its UserRecordFormatType. A UserRaw is returned when the record does not validate or
+// would not be written back unchanged.
+func userRecordFor(record string) UserRecord {
+	var ur UserRecord
+	var fn func() UserRecord
+	if len(record) >= 35 {
+		fn = registeredUserRecord(strings.TrimSpace(record[3:12]), strings.TrimSpace(record[32:35]))
+	}
+	switch {
+	case fn != nil:
+		ur = fn()
+	case len(record) >= 35 && record[32:35] == "001":
+		ur = NewUserPayeeEndorsement()
+	default:
+		ur = NewUserGeneral()
+	}
+	ur.Parse(record)
+	if ur.Validate() == nil && ur.String() == record {
+		return ur
+	}
+	raw := NewUserRaw()
+	raw.Parse(record)
+	return raw
+}
+
+// UserRaw Record holds a User Record whose format is not known. The record is written back exactly as it was read.
+type UserRaw struct {
+	// ID is a client defined string used as a reference to this record.
+	ID string `json:"id"`
+	// RecordType defines the type of record.
+	recordType string
+	// Record is the entire User Record, including the record type
+	Record string `json:"record"`
+	// original holds the record as it was read, see ReadOriginalRecordsOption
+	original
+}
+
+// NewUserRaw returns a new UserRaw with default values for non exported fields
+func NewUserRaw() *UserRaw {
+	ur := &UserRaw{}
+	ur.setRecordType()
+	return ur
+}
+
+func (ur *UserRaw) setRecordType() {
+	if ur == nil {
+		return
+	}
+	ur.recordType = "68"
+}
+
+// Parse takes the input record string and keeps it as the UserRaw Record
+func (ur *UserRaw) Parse(record string) {
+	// Character position 1-2, Always "68"
+	ur.setRecordType()
+	ur.Record = record
+}
+
+func (ur *UserRaw) UnmarshalJSON(data []byte) error {
+	type Alias UserRaw
+	aux := struct {
+		*Alias
+	}{
+		(*Alias)(ur),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	ur.setRecordType()
+	return nil
+}
+
+// String writes the UserRaw Record unchanged.
+func (ur *UserRaw) String() string {
+	return ur.Record
+}
+
+// Validate performs imagecashletter format rule checks on the record and returns an error if not Validated
+func (ur *UserRaw) Validate() error {
+	if ur.recordType == "" {
+		return &FieldError{FieldName: "recordType",
+			Value: ur.recordType,
+			Msg:   msgFieldInclusion + ", did you use UserRaw()?"}
+	}
+	if ur.recordType != "68" {
+		msg := fmt.Sprintf(msgRecordType, 68)
+		return &FieldError{FieldName: "recordType", Value: ur.recordType, Msg: msg}
+	}
+	if len(ur.Record) < 2 || ur.Record[:2] != "68" {
+		msg := fmt.Sprintf(msgRecordType, 68)
+		return &FieldError{FieldName: "Record", Value: ur.Record, Msg: msg}
+	}
+	return nil
+}
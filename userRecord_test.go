@@ -0,0 +1,477 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// mockUserRaw creates a UserRaw
+func mockUserRaw() *UserRaw {
+	ur := NewUserRaw()
+	ur.Parse("685123456789ZZ1                 9990010000010  Raw data")
+	return ur
+}
+
+// TestMockUserRaw creates a UserRaw
+func TestMockUserRaw(t *testing.T) {
+	ur := mockUserRaw()
+	if err := ur.Validate(); err != nil {
+		t.Error("mockUserRaw does not validate and will break other tests: ", err)
+	}
+	if ur.recordType != "68" {
+		t.Error("recordType does not validate")
+	}
+	if ur.String() != "685123456789ZZ1                 9990010000010  Raw data" {
+		t.Errorf("Strings do not match")
+	}
+}
+
+// TestUserRawRecordType validation
+func TestUserRawRecordType(t *testing.T) {
+	ur := mockUserRaw()
+	ur.recordType = "00"
+	err := ur.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestUserRawRecord validation
+func TestUserRawRecord(t *testing.T) {
+	ur := mockUserRaw()
+	ur.Record = "25"
+	err := ur.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "Record" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestUserRawFIRecordType validation
+func TestUserRawFIRecordType(t *testing.T) {
+	ur := &UserRaw{Record: "68"}
+	err := ur.Validate()
+	if e, ok := err.(*FieldError); !ok || e.FieldName != "recordType" {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestUserRecordFor validates User Records are parsed as the type for their UserRecordFormatType
+func TestUserRecordFor(t *testing.T) {
+	ug := mockUserGeneral().String()
+	if ur, ok := userRecordFor(ug).(*UserGeneral); !ok {
+		t.Errorf("unexpected %T", ur)
+	} else if ur.UserData != "This is a payment for your information" {
+		t.Errorf("UserData: %q", ur.UserData)
+	}
+
+	upe := mockUserPayeeEndorsement().String()
+	if ur, ok := userRecordFor(upe).(*UserPayeeEndorsement); !ok {
+		t.Errorf("unexpected %T", ur)
+	} else if ur.PayeeName != "Payee Name" {
+		t.Errorf("PayeeName: %q", ur.PayeeName)
+	}
+
+	lines := map[string]string{
+		"padded":      ug + "     ",
+		"short":       "683230918276ZZ1                 0001  0000099Not enough user data",
+		"invalid":     "683230918276ZZ1                 0001  000000AThis is a payment",
+		"payee short": upe[:100],
+	}
+	for name, line := range lines {
+		t.Run(name, func(t *testing.T) {
+			ur := userRecordFor(line)
+			if _, ok := ur.(*UserRaw); !ok {
+				t.Errorf("unexpected %T", ur)
+			}
+			if ur.String() != line {
+				t.Errorf("Strings do not match")
+			}
+		})
+	}
+}
+
+// TestUserRecordsJSON validates UserRecords are decoded as their concrete types
+func TestUserRecordsJSON(t *testing.T) {
+	urs := UserRecords{mockUserGeneral(), mockUserPayeeEndorsement(), mockUserRaw()}
+	bs, err := json.Marshal(urs)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	var read UserRecords
+	if err := json.Unmarshal(bs, &read); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(read) != len(urs) {
+		t.Fatalf("read %d UserRecords", len(read))
+	}
+	if _, ok := read[0].(*UserGeneral); !ok {
+		t.Errorf("unexpected %T", read[0])
+	}
+	if _, ok := read[1].(*UserPayeeEndorsement); !ok {
+		t.Errorf("unexpected %T", read[1])
+	}
+	if _, ok := read[2].(*UserRaw); !ok {
+		t.Errorf("unexpected %T", read[2])
+	}
+	for i := range urs {
+		if err := read[i].Validate(); err != nil {
+			t.Errorf("%T: %s", err, err)
+		}
+		if read[i].String() != urs[i].String() {
+			t.Errorf("%T: Strings do not match", read[i])
+		}
+	}
+
+	if err := json.Unmarshal([]byte("null"), &read); err != nil || read != nil {
+		t.Errorf("unexpected %v: %v", read, err)
+	}
+	if err := json.Unmarshal([]byte(`[{"userRecordFormatType": 1}]`), &read); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestUserRecordParseOutsideCashLetter validation
+func TestUserRecordParseOutsideCashLetter(t *testing.T) {
+	line := mockUserGeneral().String()
+	r := NewReader(strings.NewReader(line))
+	r.line = line
+	if err := r.parseUserRecord(); err != nil {
+		if !strings.Contains(err.Error(), msgFileUserRecord) {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// mockUserRecordsFile creates a File with User Records following the CashLetter, Bundle and item records
+func mockUserRecordsFile(t *testing.T) *File {
+	file := NewFile().SetHeader(mockFileHeader())
+
+	cd := mockCheckDetail()
+	cd.AddCheckDetailAddendumA(mockCheckDetailAddendumA())
+	cd.AddCheckDetailAddendumB(mockCheckDetailAddendumB())
+	cd.AddCheckDetailAddendumC(mockCheckDetailAddendumC())
+	cd.AddUserRecord(mockUserPayeeEndorsement())
+	cd.AddUserRecord(mockUserRaw())
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCredit(mockCredit())
+	bundle.AddUserRecord(mockUserGeneral())
+	bundle.AddCheckDetail(cd)
+
+	rd := mockReturnDetail()
+	rd.AddReturnDetailAddendumA(mockReturnDetailAddendumA())
+	rd.AddReturnDetailAddendumB(mockReturnDetailAddendumB())
+	rd.AddReturnDetailAddendumC(mockReturnDetailAddendumC())
+	rd.AddReturnDetailAddendumD(mockReturnDetailAddendumD())
+	rd.AddUserRecord(mockUserGeneral())
+	returnBundle := NewBundle(mockBundleHeader())
+	returnBundle.BundleHeader.BundleSequenceNumber = "2"
+	returnBundle.AddReturnDetail(rd)
+
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddUserRecord(mockUserGeneral())
+	cl.AddBundle(bundle)
+	cl.AddBundle(returnBundle)
+	if err := cl.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	return file
+}
+
+// TestICLWriteUserRecords writes and reads back an ICL file with User Records
+func TestICLWriteUserRecords(t *testing.T) {
+	file := mockUserRecordsFile(t)
+	if file.Control.TotalRecordCount != 23 {
+		t.Errorf("TotalRecordCount: %d", file.Control.TotalRecordCount)
+	}
+
+	tests := map[string]struct {
+		writeOpts []WriterOption
+		readOpts  []ReaderOption
+	}{
+		"ascii":  {},
+		"ebcdic": {[]WriterOption{WriteVariableLineLengthOption(), WriteEbcdicEncodingOption()}, []ReaderOption{ReadVariableLineLengthOption(), ReadEbcdicEncodingOption()}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := NewWriter(b, tc.writeOpts...).Write(file); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+
+			f, err := NewReader(bytes.NewReader(b.Bytes()), tc.readOpts...).Read()
+			if err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			cl := f.CashLetters[0]
+			if urs := cl.GetUserRecords(); len(urs) != 1 {
+				t.Errorf("CashLetter UserRecords: %v", urs)
+			} else if _, ok := urs[0].(*UserGeneral); !ok {
+				t.Errorf("unexpected %T", urs[0])
+			}
+			if urs := cl.Bundles[0].GetUserRecords(); len(urs) != 1 {
+				t.Errorf("Bundle UserRecords: %v", urs)
+			}
+			if urs := cl.Bundles[0].Checks[0].GetUserRecords(); len(urs) != 2 {
+				t.Errorf("CheckDetail UserRecords: %v", urs)
+			} else {
+				if _, ok := urs[0].(*UserPayeeEndorsement); !ok {
+					t.Errorf("unexpected %T", urs[0])
+				}
+				if _, ok := urs[1].(*UserRaw); !ok {
+					t.Errorf("unexpected %T", urs[1])
+				}
+			}
+			if urs := cl.Bundles[1].Returns[0].GetUserRecords(); len(urs) != 1 {
+				t.Errorf("ReturnDetail UserRecords: %v", urs)
+			}
+
+			// User Records are written back in place
+			written := &bytes.Buffer{}
+			if err := NewWriter(written, tc.writeOpts...).Write(&f); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			if !bytes.Equal(b.Bytes(), written.Bytes()) {
+				t.Errorf("written file does not match read file")
+			}
+		})
+	}
+}
+
+// TestReader__NextRecordUserRecords validates NextRecord returns User Records
+func TestReader__NextRecordUserRecords(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(mockUserRecordsFile(t)); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	r := NewReader(b)
+	count := 0
+	for {
+		rec, err := r.NextRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		switch rec.Record.(type) {
+		case *UserGeneral, *UserPayeeEndorsement, *UserRaw:
+			count++
+		}
+	}
+	if count != 5 {
+		t.Errorf("read %d UserRecords", count)
+	}
+}
+
+// userRemittance is a User Record format defined by a clearing arrangement
+type userRemittance struct {
+	OwnerIdentifier string `json:"ownerIdentifier"`
+	InvoiceNumber   string `json:"invoiceNumber"`
+	Amount          int    `json:"amount"`
+	validator
+	converters
+}
+
+func (ur *userRemittance) Parse(record string) {
+	if len(record) < 65 {
+		return
+	}
+	ur.OwnerIdentifier = ur.parseStringField(record[3:12])
+	ur.InvoiceNumber = ur.parseStringField(record[45:55])
+	ur.Amount = ur.parseNumField(record[55:65])
+}
+
+func (ur *userRemittance) String() string {
+	return "685" + ur.stringField(ur.OwnerIdentifier, 9) + ur.alphaField("", 20) + "100001" +
+		ur.numericField(20, 7) + ur.alphaField(ur.InvoiceNumber, 10) + ur.numericField(ur.Amount, 10)
+}
+
+func (ur *userRemittance) Validate() error {
+	if err := ur.isAlphanumeric(ur.InvoiceNumber); err != nil {
+		return &FieldError{FieldName: "InvoiceNumber", Value: ur.InvoiceNumber, Msg: err.Error()}
+	}
+	return nil
+}
+
+// TestRegisterUserRecord validates registered User Record formats are read, decoded and written as their type
+func TestRegisterUserRecord(t *testing.T) {
+	format := UserRecordFormat{OwnerIdentifier: "123456780", UserRecordFormatType: "100"}
+	RegisterUserRecord(format, func() UserRecord { return &userRemittance{} })
+	defer RegisterUserRecord(format, nil)
+
+	line := (&userRemittance{OwnerIdentifier: "123456780", InvoiceNumber: "INV1234", Amount: 10000}).String()
+	ur, ok := userRecordFor(line).(*userRemittance)
+	if !ok {
+		t.Fatalf("unexpected %T", ur)
+	}
+	if ur.InvoiceNumber != "INV1234" || ur.Amount != 10000 {
+		t.Errorf("unexpected %#v", ur)
+	}
+
+	// Other owners and invalid records are not parsed as the registered type
+	other := (&userRemittance{OwnerIdentifier: "231380104", InvoiceNumber: "INV1234"}).String()
+	if ur := userRecordFor(other); ur.String() != other {
+		t.Errorf("Strings do not match")
+	} else if _, ok := ur.(*userRemittance); ok {
+		t.Errorf("unexpected %T", ur)
+	}
+	invalid := (&userRemittance{OwnerIdentifier: "123456780", InvoiceNumber: "INV-1234"}).String()
+	if ur, ok := userRecordFor(invalid).(*UserRaw); !ok {
+		t.Errorf("unexpected %T", ur)
+	} else if ur.String() != invalid {
+		t.Errorf("Strings do not match")
+	}
+
+	// A format registered for any owner
+	anyOwner := UserRecordFormat{UserRecordFormatType: "100"}
+	RegisterUserRecord(anyOwner, func() UserRecord { return &userRemittance{} })
+	if ur, ok := userRecordFor(other).(*userRemittance); !ok {
+		t.Errorf("unexpected %T", ur)
+	}
+	RegisterUserRecord(anyOwner, nil)
+	if ur, ok := userRecordFor(other).(*userRemittance); ok {
+		t.Errorf("unexpected %T", ur)
+	}
+
+	var urs UserRecords
+	if err := json.Unmarshal([]byte(`[{"ownerIdentifier": "123456780", "userRecordFormatType": "100", "invoiceNumber": "INV1234"}]`), &urs); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if ur, ok := urs[0].(*userRemittance); !ok || ur.InvoiceNumber != "INV1234" {
+		t.Errorf("unexpected %#v", urs[0])
+	}
+}
+
+// TestICLWriteRegisteredUserRecord writes and reads back an ICL file with a registered User Record format
+func TestICLWriteRegisteredUserRecord(t *testing.T) {
+	format := UserRecordFormat{OwnerIdentifier: "123456780", UserRecordFormatType: "100"}
+	RegisterUserRecord(format, func() UserRecord { return &userRemittance{} })
+	defer RegisterUserRecord(format, nil)
+
+	file := mockUserRecordsFile(t)
+	cd := file.CashLetters[0].Bundles[0].Checks[0]
+	cd.AddUserRecord(&userRemittance{OwnerIdentifier: "123456780", InvoiceNumber: "INV1234", Amount: 10000})
+	if err := file.Create(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	expected := b.String()
+
+	f, err := NewReader(strings.NewReader(expected)).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	urs := f.CashLetters[0].Bundles[0].Checks[0].GetUserRecords()
+	if len(urs) != 3 {
+		t.Fatalf("CheckDetail UserRecords: %v", urs)
+	}
+	if ur, ok := urs[2].(*userRemittance); !ok || ur.Amount != 10000 {
+		t.Errorf("unexpected %#v", urs[2])
+	}
+
+	b.Reset()
+	if err := NewWriter(b).Write(&f); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if b.String() != expected {
+		t.Errorf("written file does not match read file")
+	}
+
+	// NextRecord returns registered types which are not a FileRecord as the UserRecord
+	r := NewReader(strings.NewReader(expected))
+	found := false
+	for {
+		rec, err := r.NextRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%T: %s", err, err)
+		}
+		if _, ok := rec.UserRecord.(*userRemittance); ok {
+			found = true
+			if rec.Record != nil {
+				t.Errorf("unexpected %T", rec.Record)
+			}
+		}
+	}
+	if !found {
+		t.Error("userRemittance not read")
+	}
+}
+
+// TestICLWriteUserRecordsInPlace validates User Records between Bundles, after the last Bundle and between the
+// records of an item are written back where they were read
+func TestICLWriteUserRecordsInPlace(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(mockUserRecordsFile(t)); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	ur := mockUserGeneral().String()
+	var lines []string
+	bundleControls := 0
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		lines = append(lines, line)
+		switch line[:2] {
+		case bundleControlPos:
+			// following each Bundle
+			bundleControls++
+			lines = append(lines, ur)
+		case checkDetailPos, returnAddendumBPos:
+			// between an item and its addenda
+			lines = append(lines, ur)
+		}
+	}
+	if bundleControls != 2 {
+		t.Fatalf("read %d BundleControls", bundleControls)
+	}
+	// TotalRecordCount includes the added User Records
+	control := NewFileControl()
+	control.Parse(lines[len(lines)-1])
+	control.TotalRecordCount += 4
+	lines[len(lines)-1] = control.String()
+	read := strings.Join(lines, "\n") + "\n"
+
+	f, err := NewReader(strings.NewReader(read)).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	cl := f.CashLetters[0]
+	if len(cl.UserRecords) != 3 || len(cl.UserRecordPositions) != 3 ||
+		cl.UserRecordPositions[1] != 1 || cl.UserRecordPositions[2] != 2 {
+		t.Errorf("CashLetter UserRecords: %v positions %v", cl.UserRecords, cl.UserRecordPositions)
+	}
+	if cd := cl.Bundles[0].Checks[0]; len(cd.UserRecordPositions) != 3 || cd.UserRecordPositions[0] != 0 {
+		t.Errorf("CheckDetail UserRecordPositions: %v", cd.UserRecordPositions)
+	}
+	if rd := cl.Bundles[1].Returns[0]; len(rd.UserRecordPositions) != 2 || rd.UserRecordPositions[0] != 2 {
+		t.Errorf("ReturnDetail UserRecordPositions: %v", rd.UserRecordPositions)
+	}
+
+	written := &bytes.Buffer{}
+	if err := NewWriter(written).Write(&f); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if written.String() != read {
+		t.Errorf("written file does not match read file:\n%s", written.String())
+	}
+}
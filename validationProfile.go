@@ -0,0 +1,360 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors specific to a ValidationProfile
+var (
+	msgProfileRequired = "is required by validation profile %s"
+	msgProfileValue    = "is not allowed by validation profile %s"
+	msgProfileCheck    = "is not a known check"
+	msgProfileField    = "is not a known record field"
+)
+
+// ValidationProfile changes the format rule checks of records for a clearing partner or arrangement. Checks are the
+// value checks of validators.go, e.g. StandardLevel or CompanionDocumentIndicatorCA, which can be disabled or given
+// the values they accept. Fields add required fields and accepted values to the fields of records.
+//
+// A ValidationProfile is selected with ValidateProfileOption for File.Validate and File.ValidationReport, and with
+// ReadValidationProfileOption for a Reader. Profiles are looked up by name with LookupValidationProfile, and can be
+// registered with RegisterValidationProfile or read from JSON with ReadValidationProfiles.
+type ValidationProfile struct {
+	// Name identifies the ValidationProfile, e.g. fed
+	Name string `json:"name"`
+	// Description describes the clearing partner or arrangement of the ValidationProfile
+	Description string `json:"description,omitempty"`
+	// Checks are rules which replace the checks of validators.go, by check name e.g. StandardLevel
+	Checks map[string]CheckRule `json:"checks,omitempty"`
+	// Fields are rules for record fields, by record and field name e.g. FileHeader.CountryCode
+	Fields map[string]FieldRule `json:"fields,omitempty"`
+}
+
+// CheckRule replaces a check of validators.go
+type CheckRule struct {
+	// Disabled turns the check off so any value is accepted
+	Disabled bool `json:"disabled,omitempty"`
+	// Values are the values accepted by the check in place of the values of the standard
+	Values []string `json:"values,omitempty"`
+}
+
+// FieldRule adds rules to a record field
+type FieldRule struct {
+	// Required fields must not be blank or zero
+	Required bool `json:"required,omitempty"`
+	// Values are the only values accepted when the field is not blank
+	Values []string `json:"values,omitempty"`
+}
+
+// profileChecks are the names of the checks of validators.go a ValidationProfile can replace
+var profileChecks = map[string]bool{
+	"CreditTotalIndicator": true, "DocumentationTypeIndicator": true, "StandardLevel": true, "ResendIndicator": true,
+	"TestFileIndicator": true, "CompanionDocumentIndicatorUS": true, "CompanionDocumentIndicatorCA": true,
+	"CollectionTypeIndicator": true, "RecordTypeIndicator": true, "ReturnsIndicator": true,
+	"ReturnAcceptanceIndicator": true, "MICRValidIndicator": true, "BOFDIndicator": true, "CorrectionIndicator": true,
+	"ArchiveTypeIndicator": true, "TruncationIndicator": true, "ConversionIndicator": true,
+	"ImageReferenceKeyIndicator": true, "EndorsingBankIdentifier": true, "ImageIndicator": true,
+	"ImageViewFormatIndicator": true, "ImageViewCompressionAlgorithm": true, "ViewSideIndicator": true,
+	"ViewDescriptor": true, "DigitalSignatureIndicator": true, "DigitalSignatureMethod": true,
+	"ImageRecreateIndicator": true, "OverrideIndicator": true, "ImageViewAnalysisValid": true,
+	"ReturnNotificationIndicator": true, "TimesReturned": true, "AccountTypeCode": true, "SourceWorkCode": true,
+	"OwnerIdentifierIndicator": true, "EndorsementIndicator": true,
+}
+
+// profileRecords are the records whose fields a ValidationProfile can have rules for
+var profileRecords = map[string]reflect.Type{}
+
+func init() {
+	for _, rec := range []interface{}{
+		FileHeader{}, CashLetterHeader{}, BundleHeader{}, CheckDetail{}, CheckDetailAddendumA{},
+		CheckDetailAddendumB{}, CheckDetailAddendumC{}, ReturnDetail{}, ReturnDetailAddendumA{},
+		ReturnDetailAddendumB{}, ReturnDetailAddendumC{}, ReturnDetailAddendumD{}, ImageViewDetail{},
+		ImageViewData{}, ImageViewAnalysis{}, Credit{}, CreditItem{}, AccountTotalsDetail{}, NonHitTotalsDetail{},
+		UserGeneral{}, UserPayeeEndorsement{}, BundleControl{}, BoxSummary{}, RoutingNumberSummary{},
+		CashLetterControl{}, FileControl{},
+	} {
+		t := reflect.TypeOf(rec)
+		profileRecords[t.Name()] = t
+	}
+}
+
+// FedValidationProfile checks files exchanged with the Federal Reserve Banks, which require an X9.100-187 file
+// from a United States institution.
+var FedValidationProfile = &ValidationProfile{
+	Name:        "fed",
+	Description: "Federal Reserve Banks",
+	Checks: map[string]CheckRule{
+		"StandardLevel": {Values: []string{"30", "35"}},
+	},
+	Fields: map[string]FieldRule{
+		"FileHeader.CountryCode": {Required: true, Values: []string{"US"}},
+	},
+}
+
+// ECCHOValidationProfile checks files exchanged under the ECCHO rules, which accept DSTU X9.37 files and every
+// CompanionDocumentIndicator reserved for United States use.
+var ECCHOValidationProfile = &ValidationProfile{
+	Name:        "eccho",
+	Description: "ECCHO rules",
+	Checks: map[string]CheckRule{
+		"StandardLevel":                {Values: []string{"03", "30", "35"}},
+		"CompanionDocumentIndicatorUS": {Values: []string{"", "0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+	},
+}
+
+// CanadianValidationProfile checks files exchanged under Payments Canada Standard 015, which requires the
+// CountryCode CA so the CompanionDocumentIndicator is checked for Canadian use.
+var CanadianValidationProfile = &ValidationProfile{
+	Name:        "ca015",
+	Description: "Payments Canada Standard 015",
+	Checks: map[string]CheckRule{
+		"CompanionDocumentIndicatorUS": {Disabled: true},
+	},
+	Fields: map[string]FieldRule{
+		"FileHeader.CountryCode": {Required: true, Values: []string{"CA"}},
+	},
+}
+
+var validationProfiles = struct {
+	sync.RWMutex
+	m map[string]*ValidationProfile
+}{m: map[string]*ValidationProfile{
+	FedValidationProfile.Name:      FedValidationProfile,
+	ECCHOValidationProfile.Name:    ECCHOValidationProfile,
+	CanadianValidationProfile.Name: CanadianValidationProfile,
+}}
+
+// RegisterValidationProfile validates profile and registers it by its Name, replacing any ValidationProfile
+// registered with the same Name.
+func RegisterValidationProfile(profile *ValidationProfile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	validationProfiles.Lock()
+	defer validationProfiles.Unlock()
+	validationProfiles.m[profile.Name] = profile
+	return nil
+}
+
+// LookupValidationProfile returns the ValidationProfile registered for name, or nil if there is none.
+func LookupValidationProfile(name string) *ValidationProfile {
+	validationProfiles.RLock()
+	defer validationProfiles.RUnlock()
+	return validationProfiles.m[name]
+}
+
+// ReadValidationProfiles reads a JSON array of ValidationProfile, e.g. from a config file, and validates each.
+func ReadValidationProfiles(r io.Reader) ([]*ValidationProfile, error) {
+	var profiles []*ValidationProfile
+	if err := json.NewDecoder(r).Decode(&profiles); err != nil {
+		return nil, fmt.Errorf("problem reading validation profiles: %v", err)
+	}
+	for _, p := range profiles {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return profiles, nil
+}
+
+// Validate ensures the ValidationProfile has a Name and its rules are for known checks and record fields
+func (p *ValidationProfile) Validate() error {
+	if p == nil {
+		return errors.New("nil ValidationProfile")
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		return &FieldError{FieldName: "Name", Value: p.Name, Msg: msgFieldInclusion}
+	}
+	for name := range p.Checks {
+		if !profileChecks[name] {
+			return &FieldError{FieldName: "Checks", Value: name, Msg: msgProfileCheck}
+		}
+	}
+	for name := range p.Fields {
+		parts := strings.SplitN(name, ".", 2)
+		t, ok := profileRecords[parts[0]]
+		if !ok || len(parts) != 2 {
+			return &FieldError{FieldName: "Fields", Value: name, Msg: msgProfileField}
+		}
+		if f, ok := t.FieldByName(parts[1]); !ok || f.PkgPath != "" || f.Anonymous {
+			return &FieldError{FieldName: "Fields", Value: name, Msg: msgProfileField}
+		}
+	}
+	return nil
+}
+
+// checkRule applies the ValidationProfile rule for the check named name to code. ok is false when the profile
+// has no rule for the check and the values of the standard are checked.
+func (v *validator) checkRule(name, code string) (ok bool, err error) {
+	if v.profile == nil {
+		return false, nil
+	}
+	rule, exists := v.profile.Checks[name]
+	if !exists || (!rule.Disabled && len(rule.Values) == 0) {
+		return false, nil
+	}
+	if rule.Disabled || contains(rule.Values, code) {
+		return true, nil
+	}
+	return true, errors.New(msgInvalid)
+}
+
+func (v *validator) setProfile(p *ValidationProfile) {
+	v.profile = p
+}
+
+// validatable is a record which performs imagecashletter format rule checks
+type validatable interface {
+	Validate() error
+}
+
+// validate performs the format rule checks of rec with the ValidationProfile. A nil ValidationProfile performs
+// the checks of the standard.
+func (p *ValidationProfile) validate(rec validatable) error {
+	if p == nil {
+		return rec.Validate()
+	}
+	if err := p.withChecks(rec).(validatable).Validate(); err != nil {
+		return err
+	}
+	return p.validateFields(rec)
+}
+
+// validateCashLetterControl performs the format rule checks of a CashLetterControl with the ValidationProfile
+func (p *ValidationProfile) validateCashLetterControl(clc *CashLetterControl, collectionTypeIndicator string) error {
+	if p == nil {
+		return clc.Validate(collectionTypeIndicator)
+	}
+	if err := p.withChecks(clc).(*CashLetterControl).Validate(collectionTypeIndicator); err != nil {
+		return err
+	}
+	return p.validateFields(clc)
+}
+
+// withChecks returns a copy of rec whose checks use the ValidationProfile, so rec is not changed. Records
+// without a validator are returned as they are.
+func (p *ValidationProfile) withChecks(rec interface{}) interface{} {
+	v := reflect.ValueOf(rec)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return rec
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	if pv, ok := c.Interface().(interface{ setProfile(*ValidationProfile) }); ok {
+		pv.setProfile(p)
+		return pv
+	}
+	return rec
+}
+
+// validateFields applies the field rules of the ValidationProfile for the record type of rec
+func (p *ValidationProfile) validateFields(rec interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(rec))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	prefix := v.Type().Name() + "."
+	names := make([]string, 0, len(p.Fields))
+	for name := range p.Fields {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule := p.Fields[name]
+		fieldName := strings.TrimPrefix(name, prefix)
+		field := v.FieldByName(fieldName)
+		if !field.IsValid() {
+			continue
+		}
+		value, blank := fieldValue(field)
+		if blank {
+			if rule.Required {
+				msg := fmt.Sprintf(msgProfileRequired, p.Name)
+				return &FieldError{FieldName: fieldName, Value: value, Msg: msg}
+			}
+			continue
+		}
+		if len(rule.Values) > 0 && !contains(rule.Values, value) {
+			msg := fmt.Sprintf(msgProfileValue, p.Name)
+			return &FieldError{FieldName: fieldName, Value: value, Msg: msg}
+		}
+	}
+	return nil
+}
+
+// fieldValue returns the value of a record field as a string and whether the field is blank or zero
+func fieldValue(field reflect.Value) (string, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		s := field.String()
+		return s, strings.TrimSpace(s) == ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), field.Int() == 0
+	}
+	if t, ok := field.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return "", true
+		}
+		return t.String(), false
+	}
+	return fmt.Sprint(field.Interface()), field.IsZero()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOption is an option for File.Validate and File.ValidationReport
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	profile *ValidationProfile
+	// images inspects the image data of each ImageViewData, see ValidateImagesOption
+	images bool
+	// routingNumbers validates the check digits of routing numbers, see ValidateRoutingNumbersOption
+	routingNumbers bool
+	// nonFinancialRoutingNumbers also validates the fields which may identify a non-financial institution, see
+	// ValidateAllRoutingNumbersOption
+	nonFinancialRoutingNumbers bool
+	// routingDirectory looks up routing numbers, see ValidateRoutingDirectoryOption
+	routingDirectory *RoutingDirectory
+	// routingNumberSummary validates RoutingNumberSummary records against the CheckDetail, see
+	// ValidateRoutingNumberSummaryOption
+	routingNumberSummary bool
+}
+
+func newValidateOptions(opts []ValidateOption) *validateOptions {
+	o := &validateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ValidateProfileOption validates each record of the File with the checks and field rules of profile. File.Validate
+// only validates every record when a ValidationProfile is given.
+func ValidateProfileOption(profile *ValidationProfile) ValidateOption {
+	return func(o *validateOptions) {
+		o.profile = profile
+	}
+}
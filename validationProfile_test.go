@@ -0,0 +1,174 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestValidationProfile__Checks validates a ValidationProfile replaces the checks of validators.go
+func TestValidationProfile__Checks(t *testing.T) {
+	fh := mockFileHeader()
+	fh.StandardLevel = "03"
+	if err := fh.Validate(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if err := FedValidationProfile.validate(&fh); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "StandardLevel" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	// the record is not changed
+	if fh.profile != nil {
+		t.Error("ValidationProfile set on record")
+	}
+
+	fh = mockFileHeader()
+	fh.CompanionDocumentIndicator = "9"
+	if err := fh.Validate(); err == nil {
+		t.Error("expected error")
+	}
+	if err := ECCHOValidationProfile.validate(&fh); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+
+	// CompanionDocumentIndicatorUS is disabled, but the CountryCode must be CA
+	if err := CanadianValidationProfile.validate(&fh); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "CountryCode" || e.Value != "US" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	fh.CountryCode = "CA"
+	fh.CompanionDocumentIndicator = "A"
+	if err := CanadianValidationProfile.validate(&fh); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	fh.CompanionDocumentIndicator = "9"
+	if err := CanadianValidationProfile.validate(&fh); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestValidationProfile__Required validates a ValidationProfile requires fields
+func TestValidationProfile__Required(t *testing.T) {
+	profile := &ValidationProfile{
+		Name: "required",
+		Fields: map[string]FieldRule{
+			"CheckDetail.DocumentationTypeIndicator": {Required: true},
+			"CheckDetail.ItemAmount":                 {Required: true},
+		},
+	}
+	if err := profile.Validate(); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	cd := mockCheckDetail()
+	if err := profile.validate(cd); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	cd.DocumentationTypeIndicator = ""
+	if err := profile.validate(cd); err != nil {
+		if !strings.Contains(err.Error(), "DocumentationTypeIndicator") || !strings.Contains(err.Error(), "required") {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+	cd = mockCheckDetail()
+	cd.ItemAmount = 0
+	if err := profile.validate(cd); err != nil {
+		if e, ok := err.(*FieldError); !ok || e.FieldName != "ItemAmount" {
+			t.Errorf("%T: %s", err, err)
+		}
+	} else {
+		t.Error("expected error")
+	}
+}
+
+// TestValidationProfile__File validates a File with a ValidationProfile
+func TestValidationProfile__File(t *testing.T) {
+	file := mockStreamFile(t)
+	if err := file.Validate(ValidateProfileOption(FedValidationProfile)); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if err := file.Validate(ValidateProfileOption(CanadianValidationProfile)); err == nil {
+		t.Error("expected error")
+	}
+
+	report := file.ValidationReport(ValidateProfileOption(CanadianValidationProfile))
+	if report.Profile != "ca015" {
+		t.Errorf("Profile: %s", report.Profile)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Path != "/fileHeader" || report.Violations[0].FieldName != "CountryCode" {
+		t.Errorf("unexpected Violations: %v", report.Violations)
+	}
+}
+
+// TestValidationProfile__Reader reads a File with a ValidationProfile
+func TestValidationProfile__Reader(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(mockStreamFile(t)); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if _, err := NewReader(bytes.NewReader(b.Bytes()), ReadValidationProfileOption(FedValidationProfile)).Read(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	_, err := NewReader(bytes.NewReader(b.Bytes()), ReadValidationProfileOption(CanadianValidationProfile)).Read()
+	if p, ok := err.(*ParseError); ok {
+		if e, ok := p.Err.(*FieldError); !ok || e.FieldName != "CountryCode" || p.Line != 1 {
+			t.Errorf("%T: %s", e, e)
+		}
+	} else {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestValidationProfile__Read reads ValidationProfiles from JSON and registers them
+func TestValidationProfile__Read(t *testing.T) {
+	profiles, err := ReadValidationProfiles(strings.NewReader(`[{
+		"name": "partner",
+		"checks": {"StandardLevel": {"values": ["35"]}, "CompanionDocumentIndicatorUS": {"disabled": true}},
+		"fields": {"CashLetterHeader.FedWorkType": {"required": true}}
+	}]`))
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(profiles) != 1 || !profiles[0].Checks["CompanionDocumentIndicatorUS"].Disabled {
+		t.Fatalf("unexpected profiles: %v", profiles)
+	}
+	if err := RegisterValidationProfile(profiles[0]); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if p := LookupValidationProfile("partner"); p != profiles[0] {
+		t.Errorf("unexpected profile: %v", p)
+	}
+	if p := LookupValidationProfile("fed"); p != FedValidationProfile {
+		t.Errorf("unexpected profile: %v", p)
+	}
+	if p := LookupValidationProfile("unknown"); p != nil {
+		t.Errorf("unexpected profile: %v", p)
+	}
+
+	tests := map[string]string{
+		"name":   `[{"checks": {"StandardLevel": {"disabled": true}}}]`,
+		"check":  `[{"name": "a", "checks": {"StandardLvl": {"disabled": true}}}]`,
+		"record": `[{"name": "a", "fields": {"FileHeadr.CountryCode": {"required": true}}}]`,
+		"field":  `[{"name": "a", "fields": {"FileHeader.Country": {"required": true}}}]`,
+		"json":   `{"name": "a"}`,
+	}
+	for name, data := range tests {
+		if _, err := ReadValidationProfiles(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := RegisterValidationProfile(&ValidationProfile{}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,444 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/moov-io/base"
+)
+
+// Errors specific to a ValidationReport
+var (
+	msgReportControlTotal = "is out-of-balance with calculated %v"
+)
+
+// Severity is how serious a Violation is
+type Severity string
+
+const (
+	// SeverityError is a Violation of the imagecashletter format rules
+	SeverityError Severity = "error"
+	// SeverityWarning is a Violation which should be reviewed but does not make the File invalid
+	SeverityWarning Severity = "warning"
+)
+
+// Violation is a single validation failure found in a File
+type Violation struct {
+	// Path is a JSON pointer to the record in the File's JSON, e.g. /cashLetters/0/bundles/1/checks/2
+	Path string `json:"path"`
+	// RecordType is the record type of the record, e.g. 25
+	RecordType string `json:"recordType,omitempty"`
+	// RecordName is the name of the record, e.g. CheckDetail
+	RecordName string `json:"recordName,omitempty"`
+	// FieldName is the field which failed validation
+	FieldName string `json:"fieldName,omitempty"`
+	// Value is the value which failed validation
+	Value string `json:"value,omitempty"`
+	// Severity is how serious the Violation is
+	Severity Severity `json:"severity"`
+	// Msg describes the Violation
+	Msg string `json:"message"`
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s %s %s %s %s", v.Path, v.RecordName, v.FieldName, v.Value, v.Msg)
+}
+
+// ValidationReport is every Violation found in a File
+type ValidationReport struct {
+	// Profile is the Name of the ValidationProfile the File was validated with
+	Profile string `json:"profile,omitempty"`
+	// Violations is every Violation found
+	Violations []Violation `json:"violations"`
+	// profile is the ValidationProfile the File is validated with
+	profile *ValidationProfile
+	// images inspects the image data of each ImageViewData
+	images bool
+	// routingNumbers validates the check digits of routing numbers, and of the fields which may identify a
+	// non-financial institution with nonFinancialRoutingNumbers
+	routingNumbers, nonFinancialRoutingNumbers bool
+	// routingDirectory looks up routing numbers
+	routingDirectory *RoutingDirectory
+	// routingNumberSummary validates RoutingNumberSummary records against the CheckDetail
+	routingNumberSummary bool
+}
+
+// Valid returns true when the ValidationReport has no Violation with SeverityError
+func (r *ValidationReport) Valid() bool {
+	for i := range r.Violations {
+		if r.Violations[i].Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns a base.ErrorList of every Violation with SeverityError, or nil when the ValidationReport is Valid.
+func (r *ValidationReport) Err() error {
+	var errs base.ErrorList
+	for i := range r.Violations {
+		if r.Violations[i].Severity == SeverityError {
+			errs.Add(&r.Violations[i])
+		}
+	}
+	if errs.Empty() {
+		return nil
+	}
+	return errs
+}
+
+// add adds a Violation with SeverityError for err
+func (r *ValidationReport) add(path, recordType, recordName string, err error) {
+	if err == nil {
+		return
+	}
+	r.Violations = append(r.Violations, newViolation(path, recordType, recordName, SeverityError, err))
+}
+
+// newViolation returns a Violation for err, taking the field name, value and message from the imagecashletter
+// error types
+func newViolation(path, recordType, recordName string, severity Severity, err error) Violation {
+	v := Violation{Path: path, RecordType: recordType, RecordName: recordName, Severity: severity}
+	switch e := err.(type) {
+	case *FieldError:
+		v.FieldName, v.Value, v.Msg = e.FieldName, e.Value, e.Msg
+	case *FileError:
+		v.FieldName, v.Value, v.Msg = e.FieldName, e.Value, e.Msg
+	case *CashLetterError:
+		v.FieldName, v.Value, v.Msg = e.FieldName, e.CashLetterID, e.Msg
+	case *BundleError:
+		v.FieldName, v.Value, v.Msg = e.FieldName, e.BundleSequenceNumber, e.Msg
+	default:
+		v.Msg = err.Error()
+	}
+	return v
+}
+
+// total adds a Violation with SeverityWarning when the control total does not match the calculated total. Control
+// records are written as they were read or built, and clearing arrangements differ in how some totals are counted.
+func (r *ValidationReport) total(path, recordType, recordName, fieldName string, control, calculated int) {
+	if control == calculated {
+		return
+	}
+	msg := fmt.Sprintf(msgReportControlTotal, calculated)
+	err := &FieldError{FieldName: fieldName, Value: strconv.Itoa(control), Msg: msg}
+	r.Violations = append(r.Violations, newViolation(path, recordType, recordName, SeverityWarning, err))
+}
+
+// missing adds a Violation for a record which is nil
+func (r *ValidationReport) missing(path, recordType, recordName string) {
+	r.add(path, recordType, recordName, &FieldError{FieldName: recordName, Msg: msgFieldInclusion})
+}
+
+// reportTotals are the control totals calculated while walking a File
+type reportTotals struct {
+	records, items, amount, micrValidAmount, images int
+}
+
+// ValidationReport validates every record of the File, CashLetter, Bundle, CheckDetail and ReturnDetail
+// tree and the control totals of each Bundle, CashLetter and the File. Unlike Validate it doesn't stop
+// at the first error, and returns every Violation found. Control totals which don't match the records
+// of the File are reported with SeverityWarning. Records are validated with the ValidationProfile given
+// with ValidateProfileOption, image data is inspected with ValidateImagesOption, the check digits of routing
+// numbers are validated with ValidateRoutingNumbersOption or ValidateAllRoutingNumbersOption, routing numbers
+// are looked up in a RoutingDirectory with ValidateRoutingDirectoryOption, and RoutingNumberSummary records are
+// validated against the CheckDetail with ValidateRoutingNumberSummaryOption.
+func (f *File) ValidationReport(opts ...ValidateOption) *ValidationReport {
+	o := newValidateOptions(opts)
+	r := &ValidationReport{Violations: []Violation{}, profile: o.profile, images: o.images,
+		routingNumbers: o.routingNumbers, nonFinancialRoutingNumbers: o.nonFinancialRoutingNumbers,
+		routingDirectory: o.routingDirectory, routingNumberSummary: o.routingNumberSummary}
+	if o.profile != nil {
+		r.Profile = o.profile.Name
+	}
+	if f == nil {
+		r.add("", "", "File", ErrNilFile)
+		return r
+	}
+	r.validate("/fileHeader", fileHeaderPos, "FileHeader", &f.Header)
+	if len(f.CashLetters) == 0 {
+		r.add("/cashLetters", "", "CashLetter", &FileError{FieldName: "CashLetters", Value: "0", Msg: msgFieldInclusion})
+	}
+
+	// add 2 for FileHeader/control
+	totals := reportTotals{records: 2}
+	ids := make(map[string]bool)
+	for i := range f.CashLetters {
+		cl := &f.CashLetters[i]
+		path := fmt.Sprintf("/cashLetters/%d", i)
+		if cl.CashLetterHeader != nil {
+			id := cl.CashLetterHeader.CashLetterID
+			if ids[id] {
+				msg := fmt.Sprintf(msgFileCashLetterID, id)
+				r.add(path+"/cashLetterHeader", cashLetterHeaderPos, "CashLetterHeader",
+					&FileError{FieldName: "CashLetterID", Value: id, Msg: msg})
+			}
+			ids[id] = true
+		}
+		r.cashLetter(path, cl, &totals)
+	}
+
+	r.add("/fileControl", fileControlPos, "FileControl", r.profile.validate(&f.Control))
+	r.total("/fileControl", fileControlPos, "FileControl", "CashLetterCount", f.Control.CashLetterCount, len(f.CashLetters))
+	r.total("/fileControl", fileControlPos, "FileControl", "TotalRecordCount", f.Control.TotalRecordCount, totals.records)
+	r.total("/fileControl", fileControlPos, "FileControl", "TotalItemCount", f.Control.TotalItemCount, totals.items)
+	r.total("/fileControl", fileControlPos, "FileControl", "FileTotalAmount", f.Control.FileTotalAmount, totals.amount)
+	return r
+}
+
+// cashLetter adds the Violations of a CashLetter and its Bundles and adds its totals to fileTotals
+func (r *ValidationReport) cashLetter(path string, cl *CashLetter, fileTotals *reportTotals) {
+	if cl.CashLetterHeader == nil {
+		r.missing(path+"/cashLetterHeader", cashLetterHeaderPos, "CashLetterHeader")
+	} else {
+		r.validate(path+"/cashLetterHeader", cashLetterHeaderPos, "CashLetterHeader", cl.CashLetterHeader)
+		r.add(path, "", "CashLetter", cl.Validate())
+	}
+
+	// add 2 for CashLetterHeader/control
+	fileTotals.records += 2 + len(cl.CreditItems) + len(cl.AccountTotalsDetail) + len(cl.NonHitTotalsDetail) +
+		len(cl.UserRecords) + len(cl.BoxSummary) + len(cl.RoutingNumberSummary)
+	// CreditItems are included in the CashLetter items count but not its total amount
+	totals := reportTotals{items: len(cl.CreditItems)}
+
+	for i, ci := range cl.CreditItems {
+		r.record(fmt.Sprintf("%s/creditItem/%d", path, i), creditItemPos, "CreditItem", ci)
+	}
+	for i, atd := range cl.AccountTotalsDetail {
+		r.record(fmt.Sprintf("%s/accountTotalsDetail/%d", path, i), accountTotalsDetailPos, "AccountTotalsDetail", atd)
+	}
+	for i, nhtd := range cl.NonHitTotalsDetail {
+		r.record(fmt.Sprintf("%s/nonHitTotalsDetail/%d", path, i), nonHitTotalsDetailPos, "NonHitTotalsDetail", nhtd)
+	}
+	r.userRecords(path, cl.UserRecords)
+	for i, b := range cl.Bundles {
+		bundlePath := fmt.Sprintf("%s/bundles/%d", path, i)
+		if b == nil {
+			r.missing(bundlePath, "", "Bundle")
+			continue
+		}
+		r.bundle(bundlePath, b, &totals, fileTotals)
+	}
+
+	boxBundleCount, boxTotalAmount := 0, 0
+	for i, bs := range cl.BoxSummary {
+		r.record(fmt.Sprintf("%s/boxSummary/%d", path, i), boxSummaryPos, "BoxSummary", bs)
+		if bs != nil {
+			boxBundleCount += bs.BoxBundleCount
+			boxTotalAmount += bs.BoxTotalAmount
+		}
+	}
+	if len(cl.BoxSummary) > 0 {
+		boxPath := path + "/boxSummary"
+		r.total(boxPath, boxSummaryPos, "BoxSummary", "BoxBundleCount", boxBundleCount, len(cl.Bundles))
+		r.total(boxPath, boxSummaryPos, "BoxSummary", "BoxTotalAmount", boxTotalAmount, totals.amount)
+	}
+	for i, rns := range cl.RoutingNumberSummary {
+		r.record(fmt.Sprintf("%s/routingNumberSummary/%d", path, i), routingNumberSummaryPos, "RoutingNumberSummary", rns)
+	}
+	if r.routingNumberSummary && cl.CashLetterHeader != nil {
+		r.add(path+"/routingNumberSummary", routingNumberSummaryPos, "RoutingNumberSummary", cl.validateRoutingNumberSummary())
+	}
+
+	controlPath := path + "/cashLetterControl"
+	if cl.CashLetterControl == nil {
+		r.missing(controlPath, cashLetterControlPos, "CashLetterControl")
+		return
+	}
+	clc := cl.CashLetterControl
+	collectionTypeIndicator := ""
+	if cl.CashLetterHeader != nil {
+		collectionTypeIndicator = cl.CashLetterHeader.CollectionTypeIndicator
+	}
+	r.add(controlPath, cashLetterControlPos, "CashLetterControl", r.profile.validateCashLetterControl(clc, collectionTypeIndicator))
+	r.total(controlPath, cashLetterControlPos, "CashLetterControl", "CashLetterBundleCount", clc.CashLetterBundleCount, len(cl.Bundles))
+	r.total(controlPath, cashLetterControlPos, "CashLetterControl", "CashLetterItemsCount", clc.CashLetterItemsCount, totals.items)
+	r.total(controlPath, cashLetterControlPos, "CashLetterControl", "CashLetterTotalAmount", clc.CashLetterTotalAmount, totals.amount)
+	r.total(controlPath, cashLetterControlPos, "CashLetterControl", "CashLetterImagesCount", clc.CashLetterImagesCount, totals.images)
+}
+
+// bundle adds the Violations of a Bundle and its items and adds its totals to clTotals and fileTotals
+func (r *ValidationReport) bundle(path string, b *Bundle, clTotals, fileTotals *reportTotals) {
+	if b.BundleHeader == nil {
+		r.missing(path+"/bundleHeader", bundleHeaderPos, "BundleHeader")
+		b = &Bundle{BundleHeader: &BundleHeader{}, Credits: b.Credits, UserRecords: b.UserRecords,
+			Checks: b.Checks, Returns: b.Returns, BundleControl: b.BundleControl}
+	} else {
+		r.validate(path+"/bundleHeader", bundleHeaderPos, "BundleHeader", b.BundleHeader)
+	}
+	if len(b.Checks) == 0 && len(b.Returns) == 0 {
+		r.add(path, "", "Bundle", &BundleError{BundleSequenceNumber: b.BundleHeader.BundleSequenceNumber,
+			FieldName: "entries", Msg: msgBundleEntries})
+	}
+
+	// add 2 for BundleHeader/control
+	fileTotals.records += 2 + len(b.Credits) + len(b.UserRecords)
+	var totals reportTotals
+
+	for i, cr := range b.Credits {
+		r.record(fmt.Sprintf("%s/credits/%d", path, i), creditPos, "Credit", cr)
+		if cr != nil {
+			totals.items++
+			totals.amount += cr.ItemAmount
+		}
+	}
+	r.userRecords(path, b.UserRecords)
+	for i, cd := range b.Checks {
+		checkPath := fmt.Sprintf("%s/checks/%d", path, i)
+		if cd == nil {
+			r.missing(checkPath, checkDetailPos, "CheckDetail")
+			continue
+		}
+		r.checkDetail(checkPath, b.BundleHeader, cd)
+		totals.items++
+		totals.amount += cd.ItemAmount
+		if cd.MICRValidIndicator == 1 {
+			totals.micrValidAmount += cd.ItemAmount
+		}
+		totals.images += len(cd.ImageViewDetail)
+		fileTotals.records += 1 + len(cd.CheckDetailAddendumA) + len(cd.CheckDetailAddendumB) + len(cd.CheckDetailAddendumC) +
+			len(cd.ImageViewDetail) + len(cd.ImageViewData) + len(cd.ImageViewAnalysis) + len(cd.UserRecords)
+	}
+	for i, rd := range b.Returns {
+		returnPath := fmt.Sprintf("%s/returns/%d", path, i)
+		if rd == nil {
+			r.missing(returnPath, returnDetailPos, "ReturnDetail")
+			continue
+		}
+		r.returnDetail(returnPath, b.BundleHeader, rd)
+		totals.items++
+		totals.amount += rd.ItemAmount
+		totals.images += len(rd.ImageViewDetail)
+		fileTotals.records += 1 + len(rd.ReturnDetailAddendumA) + len(rd.ReturnDetailAddendumB) + len(rd.ReturnDetailAddendumC) +
+			len(rd.ReturnDetailAddendumD) + len(rd.ImageViewDetail) + len(rd.ImageViewData) + len(rd.ImageViewAnalysis) + len(rd.UserRecords)
+	}
+
+	clTotals.items += totals.items
+	clTotals.amount += totals.amount
+	clTotals.images += totals.images
+	fileTotals.items += totals.items
+	fileTotals.amount += totals.amount
+
+	controlPath := path + "/bundleControl"
+	if b.BundleControl == nil {
+		r.missing(controlPath, bundleControlPos, "BundleControl")
+		return
+	}
+	bc := b.BundleControl
+	r.add(controlPath, bundleControlPos, "BundleControl", r.profile.validate(bc))
+	r.total(controlPath, bundleControlPos, "BundleControl", "BundleItemsCount", bc.BundleItemsCount, totals.items)
+	r.total(controlPath, bundleControlPos, "BundleControl", "BundleTotalAmount", bc.BundleTotalAmount, totals.amount)
+	r.total(controlPath, bundleControlPos, "BundleControl", "MICRValidTotalAmount", bc.MICRValidTotalAmount, totals.micrValidAmount)
+	r.total(controlPath, bundleControlPos, "BundleControl", "BundleImagesCount", bc.BundleImagesCount, totals.images)
+}
+
+// checkDetail adds the Violations of a CheckDetail, its addenda, image views and User Records
+func (r *ValidationReport) checkDetail(path string, bh *BundleHeader, cd *CheckDetail) {
+	r.validate(path, checkDetailPos, "CheckDetail", cd)
+	item := &Bundle{BundleHeader: bh, Checks: []*CheckDetail{cd}}
+	r.add(path, checkDetailPos, "CheckDetail", item.checkDetailAddendumCount())
+
+	for i := range cd.CheckDetailAddendumA {
+		r.record(fmt.Sprintf("%s/checkDetailAddendumA/%d", path, i), checkDetailAddendumAPos, "CheckDetailAddendumA", &cd.CheckDetailAddendumA[i])
+	}
+	for i := range cd.CheckDetailAddendumB {
+		r.record(fmt.Sprintf("%s/checkDetailAddendumB/%d", path, i), checkDetailAddendumBPos, "CheckDetailAddendumB", &cd.CheckDetailAddendumB[i])
+	}
+	for i := range cd.CheckDetailAddendumC {
+		r.record(fmt.Sprintf("%s/checkDetailAddendumC/%d", path, i), checkDetailAddendumCPos, "CheckDetailAddendumC", &cd.CheckDetailAddendumC[i])
+	}
+	r.imageViews(path, cd.ImageViewDetail, cd.ImageViewData, cd.ImageViewAnalysis)
+	r.userRecords(path, cd.UserRecords)
+}
+
+// returnDetail adds the Violations of a ReturnDetail, its addenda, image views and User Records
+func (r *ValidationReport) returnDetail(path string, bh *BundleHeader, rd *ReturnDetail) {
+	r.validate(path, returnDetailPos, "ReturnDetail", rd)
+	item := &Bundle{BundleHeader: bh, Returns: []*ReturnDetail{rd}}
+	r.add(path, returnDetailPos, "ReturnDetail", item.returnDetailAddendumCount())
+
+	for i := range rd.ReturnDetailAddendumA {
+		r.record(fmt.Sprintf("%s/returnDetailAddendumA/%d", path, i), returnAddendumAPos, "ReturnDetailAddendumA", &rd.ReturnDetailAddendumA[i])
+	}
+	for i := range rd.ReturnDetailAddendumB {
+		r.record(fmt.Sprintf("%s/returnDetailAddendumB/%d", path, i), returnAddendumBPos, "ReturnDetailAddendumB", &rd.ReturnDetailAddendumB[i])
+	}
+	for i := range rd.ReturnDetailAddendumC {
+		r.record(fmt.Sprintf("%s/returnDetailAddendumC/%d", path, i), returnAddendumCPos, "ReturnDetailAddendumC", &rd.ReturnDetailAddendumC[i])
+	}
+	for i := range rd.ReturnDetailAddendumD {
+		r.record(fmt.Sprintf("%s/returnDetailAddendumD/%d", path, i), returnAddendumDPos, "ReturnDetailAddendumD", &rd.ReturnDetailAddendumD[i])
+	}
+	r.imageViews(path, rd.ImageViewDetail, rd.ImageViewData, rd.ImageViewAnalysis)
+	r.userRecords(path, rd.UserRecords)
+}
+
+// imageViews adds the Violations of the image view records of an item
+func (r *ValidationReport) imageViews(path string, ivDetail []ImageViewDetail, ivData []ImageViewData, ivAnalysis []ImageViewAnalysis) {
+	for i := range ivDetail {
+		r.record(fmt.Sprintf("%s/imageViewDetail/%d", path, i), imageViewDetailPos, "ImageViewDetail", &ivDetail[i])
+	}
+	for i := range ivData {
+		r.record(fmt.Sprintf("%s/imageViewData/%d", path, i), imageViewDataPos, "ImageViewData", &ivData[i])
+	}
+	if r.images {
+		for i, err := range inspectImageViews(ivDetail, ivData) {
+			r.add(fmt.Sprintf("%s/imageViewData/%d", path, i), imageViewDataPos, "ImageViewData", err)
+		}
+	}
+	for i := range ivAnalysis {
+		r.record(fmt.Sprintf("%s/imageViewAnalysis/%d", path, i), imageViewAnalysisPos, "ImageViewAnalysis", &ivAnalysis[i])
+	}
+}
+
+// userRecords adds the Violations of User Records, named by their type
+func (r *ValidationReport) userRecords(path string, urs UserRecords) {
+	for i, ur := range urs {
+		urPath := fmt.Sprintf("%s/userRecords/%d", path, i)
+		if isNilRecord(ur) {
+			r.missing(urPath, userRecordPos, "UserRecord")
+			continue
+		}
+		r.validate(urPath, userRecordPos, reflect.Indirect(reflect.ValueOf(ur)).Type().Name(), ur)
+	}
+}
+
+// record adds the Violation of a record, or of a nil record
+func (r *ValidationReport) record(path, recordType, recordName string, rec validatable) {
+	if isNilRecord(rec) {
+		r.missing(path, recordType, recordName)
+		return
+	}
+	r.validate(path, recordType, recordName, rec)
+}
+
+// validate adds the Violations of a record: its validation with the ValidationProfile, the check digits of its
+// routing numbers with ValidateRoutingNumbersOption, and its routing numbers in the RoutingDirectory with
+// ValidateRoutingDirectoryOption
+func (r *ValidationReport) validate(path, recordType, recordName string, rec validatable) {
+	r.add(path, recordType, recordName, r.profile.validate(rec))
+	if r.routingNumbers {
+		for _, err := range routingNumberErrors(rec, r.nonFinancialRoutingNumbers) {
+			r.add(path, recordType, recordName, err)
+		}
+	}
+	if r.routingDirectory != nil {
+		for _, err := range r.routingDirectory.routingDirectoryErrors(rec) {
+			r.add(path, recordType, recordName, err)
+		}
+	}
+}
+
+// isNilRecord returns true for a nil record or a nil pointer to a record
+func isNilRecord(rec interface{}) bool {
+	if rec == nil {
+		return true
+	}
+	v := reflect.ValueOf(rec)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package imagecashletter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/base"
+)
+
+// mockInvalidReportFile creates a File with invalid records in both CashLetters and an out-of-balance BundleControl
+func mockInvalidReportFile(t *testing.T) *File {
+	file := mockStreamFile(t)
+	file.Header.TestFileIndicator = "X"
+
+	// the Bundle, its items and controls are shared by both CashLetters, so change copies
+	b := *file.CashLetters[0].Bundles[0]
+	cd := *b.Checks[1]
+	cd.DocumentationTypeIndicator = "Z"
+	cd.CheckDetailAddendumA = []CheckDetailAddendumA{cd.CheckDetailAddendumA[0]}
+	cd.CheckDetailAddendumA[0].ReturnLocationRoutingNumber = "12345678A"
+	b.Checks = []*CheckDetail{b.Checks[0], &cd}
+	bc := *b.BundleControl
+	bc.BundleItemsCount = 4
+	b.BundleControl = &bc
+	file.CashLetters[0].Bundles[0] = &b
+
+	file.CashLetters[1].CashLetterHeader.CashLetterID = file.CashLetters[0].CashLetterHeader.CashLetterID
+	return file
+}
+
+// TestValidationReport validates a valid File has no Violations
+func TestValidationReport(t *testing.T) {
+	file := mockStreamFile(t)
+	report := file.ValidationReport()
+	if len(report.Violations) != 0 {
+		t.Errorf("unexpected Violations: %v", report.Violations)
+	}
+	if !report.Valid() {
+		t.Error("File is not Valid")
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+}
+
+// TestValidationReport__Violations validates every Violation of a File is reported
+func TestValidationReport__Violations(t *testing.T) {
+	file := mockInvalidReportFile(t)
+	report := file.ValidationReport()
+
+	expected := []Violation{
+		{Path: "/fileHeader", RecordType: "01", RecordName: "FileHeader", FieldName: "TestFileIndicator", Value: "X", Severity: SeverityError},
+		{Path: "/cashLetters/0/bundles/0/checks/1", RecordType: "25", RecordName: "CheckDetail", FieldName: "DocumentationTypeIndicator", Value: "Z", Severity: SeverityError},
+		{Path: "/cashLetters/0/bundles/0/checks/1/checkDetailAddendumA/0", RecordType: "26", RecordName: "CheckDetailAddendumA", FieldName: "ReturnLocationRoutingNumber", Value: "12345678A", Severity: SeverityError},
+		{Path: "/cashLetters/0/bundles/0/bundleControl", RecordType: "70", RecordName: "BundleControl", FieldName: "BundleItemsCount", Value: "4", Severity: SeverityWarning},
+		{Path: "/cashLetters/1/cashLetterHeader", RecordType: "10", RecordName: "CashLetterHeader", FieldName: "CashLetterID", Value: file.CashLetters[1].CashLetterHeader.CashLetterID, Severity: SeverityError},
+	}
+	if len(report.Violations) != len(expected) {
+		t.Fatalf("%d Violations: %v", len(report.Violations), report.Violations)
+	}
+	for i, v := range report.Violations {
+		if v.Msg == "" {
+			t.Errorf("%s has no message", v.Path)
+		}
+		v.Msg = ""
+		if v != expected[i] {
+			t.Errorf("got %#v, expected %#v", v, expected[i])
+		}
+	}
+
+	if report.Valid() {
+		t.Error("File is Valid")
+	}
+	errs, ok := report.Err().(base.ErrorList)
+	if !ok {
+		t.Fatalf("%T: %s", report.Err(), report.Err())
+	}
+	if len(errs) != 4 {
+		t.Errorf("%d errors: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Error(), msgInvalid) {
+		t.Errorf("%T: %s", errs[0], errs[0])
+	}
+}
+
+// TestValidationReport__Missing validates nil records are reported
+func TestValidationReport__Missing(t *testing.T) {
+	var f *File
+	if report := f.ValidationReport(); report.Valid() {
+		t.Error("nil File is Valid")
+	}
+
+	file := mockStreamFile(t)
+	b := *file.CashLetters[0].Bundles[0]
+	b.BundleControl = nil
+	b.Credits = []*Credit{nil}
+	file.CashLetters[0].Bundles[0] = &b
+	file.CashLetters[1].CashLetterHeader = nil
+
+	report := file.ValidationReport()
+	paths := make(map[string]bool)
+	for _, v := range report.Violations {
+		if v.Severity == SeverityError {
+			paths[v.Path] = true
+		}
+	}
+	for _, path := range []string{
+		"/cashLetters/0/bundles/0/credits/0",
+		"/cashLetters/0/bundles/0/bundleControl",
+		"/cashLetters/1/cashLetterHeader",
+	} {
+		if !paths[path] {
+			t.Errorf("%s not reported: %v", path, report.Violations)
+		}
+	}
+}
+
+// TestValidationReport__JSON validates a ValidationReport is encoded to JSON
+func TestValidationReport__JSON(t *testing.T) {
+	report := mockInvalidReportFile(t).ValidationReport()
+	bs, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	var out struct {
+		Violations []map[string]string `json:"violations"`
+	}
+	if err := json.Unmarshal(bs, &out); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if len(out.Violations) != len(report.Violations) {
+		t.Fatalf("%d Violations: %s", len(out.Violations), string(bs))
+	}
+	v := out.Violations[1]
+	if v["path"] != "/cashLetters/0/bundles/0/checks/1" || v["recordType"] != "25" || v["fieldName"] != "DocumentationTypeIndicator" ||
+		v["severity"] != "error" || v["message"] != msgDocumentationTypeIndicator {
+		t.Errorf("unexpected Violation: %v", v)
+	}
+
+	bs, err = json.Marshal(mockStreamFile(t).ValidationReport())
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if string(bs) != `{"violations":[]}` {
+		t.Errorf("unexpected JSON: %s", string(bs))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 )
 
 var (
@@ -25,7 +26,10 @@ var (
 )
 
 // validator is common validation and formatting of golang types to imagecashletter type strings
-type validator struct{}
+type validator struct {
+	// profile changes the checks of the validator, see ValidationProfile
+	profile *ValidationProfile
+}
 
 // FieldError is returned for errors at a field level in a record
 type FieldError struct {
@@ -44,6 +48,9 @@ func (e *FieldError) Error() string {
 
 // isCreditTotalIndicator ensures CreditTotalIndicator of a FileControl, CashLetterControl, and BundleControl is valid
 func (v *validator) isCreditTotalIndicator(code int) error {
+	if ok, err := v.checkRule("CreditTotalIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// 	Credit Items are NOT included in totals
@@ -57,6 +64,9 @@ func (v *validator) isCreditTotalIndicator(code int) error {
 
 // isDocumentationTypeIndicator ensures DocumentationTypeIndicator of a CashLetterHeader and CheckDetail is valid
 func (v *validator) isDocumentationTypeIndicator(code string) error {
+	if ok, err := v.checkRule("DocumentationTypeIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Conditional value, blank/space indicates no DocumentationTypeIndicator
@@ -100,6 +110,9 @@ func (v *validator) isDocumentationTypeIndicator(code string) error {
 
 // isStandardLevel ensures StandardLevel of a FileHeader is valid
 func (v *validator) isStandardLevel(code string) error {
+	if ok, err := v.checkRule("StandardLevel", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// 03: DSTU X9.37 - 2003
@@ -115,6 +128,9 @@ func (v *validator) isStandardLevel(code string) error {
 
 // isResendIndicator ensures ResendIndicator of a FileHeader is valid
 func (v *validator) isResendIndicator(code string) error {
+	if ok, err := v.checkRule("ResendIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// The file has been previously transmitted
@@ -128,6 +144,9 @@ func (v *validator) isResendIndicator(code string) error {
 
 // isTestFileIndicator ensures TestFileIndicator of a FileHeader is valid
 func (v *validator) isTestFileIndicator(code string) error {
+	if ok, err := v.checkRule("TestFileIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Production File
@@ -141,6 +160,9 @@ func (v *validator) isTestFileIndicator(code string) error {
 
 // isCompanionDocumentIndicatorUS ensures CompanionDocumentIndicatorUS of a FileHeader is valid
 func (v *validator) isCompanionDocumentIndicatorUS(code string) error {
+	if ok, err := v.checkRule("CompanionDocumentIndicatorUS", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Conditional value, blank/space indicates no CompanionDocumentIndicator
@@ -155,6 +177,9 @@ func (v *validator) isCompanionDocumentIndicatorUS(code string) error {
 
 // isCompanionDocumentIndicatorCA ensures CompanionDocumentIndicatorCA of a FileHeader is valid
 func (v *validator) isCompanionDocumentIndicatorCA(code string) error {
+	if ok, err := v.checkRule("CompanionDocumentIndicatorCA", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Conditional value, blank/space indicates no CompanionDocumentIndicator
@@ -171,6 +196,9 @@ func (v *validator) isCompanionDocumentIndicatorCA(code string) error {
 
 // isCollectionTypeIndicator ensures CollectionTypeIndicator of a CashLetterHeader is valid
 func (v *validator) isCollectionTypeIndicator(code string) error {
+	if ok, err := v.checkRule("CollectionTypeIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Preliminary Forward Information–Used when information may change and the
@@ -213,6 +241,9 @@ func (v *validator) isCollectionTypeIndicator(code string) error {
 
 // isRecordTypeIndicator ensures CashLetterRecordTypeIndicator of a CashLetterHeader is valid
 func (v *validator) isRecordTypeIndicator(code string) error {
+	if ok, err := v.checkRule("RecordTypeIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// No electronic check records or image records (Type 2x’s, 3x’s, 5x’s); e.g., an empty cash letter.
@@ -231,6 +262,9 @@ func (v *validator) isRecordTypeIndicator(code string) error {
 
 // isReturnsIndicator ensures ReturnsIndicator of a CashLetterHeader is valid
 func (v *validator) isReturnsIndicator(code string) error {
+	if ok, err := v.checkRule("ReturnsIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Blank for Forward Presentment
@@ -251,6 +285,9 @@ func (v *validator) isReturnsIndicator(code string) error {
 
 // isReturnAcceptanceIndicator ensures ReturnAcceptanceIndicator of a CheckDetail is valid
 func (v *validator) isReturnAcceptanceIndicator(code string) error {
+	if ok, err := v.checkRule("ReturnAcceptanceIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Will not accept any electronic information
@@ -292,6 +329,9 @@ func (v *validator) isReturnAcceptanceIndicator(code string) error {
 
 // isMICRValidIndicator ensures MICRValidIndicator of a CheckDetail is valid
 func (v *validator) isMICRValidIndicator(code int) error {
+	if ok, err := v.checkRule("MICRValidIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		0,
@@ -310,6 +350,9 @@ func (v *validator) isMICRValidIndicator(code int) error {
 
 // isBOFDIndicator ensures BOFDIndicator of a CheckDetail is valid
 func (v *validator) isBOFDIndicator(code string) error {
+	if ok, err := v.checkRule("BOFDIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// ECE institution is BOFD
@@ -325,6 +368,9 @@ func (v *validator) isBOFDIndicator(code string) error {
 
 // isCorrectionIndicator ensures CorrectionIndicator of a CheckDetail is valid
 func (v *validator) isCorrectionIndicator(code int) error {
+	if ok, err := v.checkRule("CorrectionIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// No Repair
@@ -344,6 +390,9 @@ func (v *validator) isCorrectionIndicator(code int) error {
 
 // isArchiveTypeIndicator ensures ArchiveTypeIndicator of a CheckDetail is valid
 func (v *validator) isArchiveTypeIndicator(code string) error {
+	if ok, err := v.checkRule("ArchiveTypeIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Microfilm
@@ -373,6 +422,9 @@ func (v *validator) isArchiveTypeIndicator(code string) error {
 
 // isTruncationIndicator ensures TruncationIndicator of a CheckDetailAddendumA is valid
 func (v *validator) isTruncationIndicator(code string) error {
+	if ok, err := v.checkRule("TruncationIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// This institution truncated this original check item and this is first endorsement for the institution.
@@ -388,6 +440,9 @@ func (v *validator) isTruncationIndicator(code string) error {
 // isConversionIndicator ensures BOFD and Endorsing Bank ConversionIndicator of a CheckDetailAddendumA and
 // CheckDetailAddendumC is valid
 func (v *validator) isConversionIndicator(code string) error {
+	if ok, err := v.checkRule("ConversionIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Did not convert physical document
@@ -415,6 +470,9 @@ func (v *validator) isConversionIndicator(code string) error {
 
 // isImageReferenceKeyIndicator ensures ImageReferenceKeyIndicator of a CheckDetailAddendumB is valid
 func (v *validator) isImageReferenceKeyIndicator(code int) error {
+	if ok, err := v.checkRule("ImageReferenceKeyIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// ImageReferenceKeyIndicator has Defined Value of 0034 and ImageReferenceKey contains the Image Reference Key.
@@ -431,6 +489,9 @@ func (v *validator) isImageReferenceKeyIndicator(code int) error {
 
 // isEndorsingBankIdentifier ensures EndorsingBankIdentifier of a CheckDetailAddendumC is valid
 func (v *validator) isEndorsingBankIdentifier(code int) error {
+	if ok, err := v.checkRule("EndorsingBankIdentifier", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Depository Bank (BOFD) - this value is used when the CheckDetailAddendumC Record reflects the Return
@@ -451,6 +512,9 @@ func (v *validator) isEndorsingBankIdentifier(code int) error {
 
 // isImageIndicator ensures ImageIndicator of a ImageViewDetail is valid
 func (v *validator) isImageIndicator(code int) error {
+	if ok, err := v.checkRule("ImageIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Image view not present
@@ -468,6 +532,9 @@ func (v *validator) isImageIndicator(code int) error {
 
 // isImageViewFormatIndicator ensures ImageViewFormatIndicator of a ImageViewDetail is valid
 func (v *validator) isImageViewFormatIndicator(code string) error {
+	if ok, err := v.checkRule("ImageViewFormatIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Agreement not required:
@@ -491,6 +558,9 @@ func (v *validator) isImageViewFormatIndicator(code string) error {
 
 // isImageViewCompressionAlgorithm ensures ImageViewCompressionAlgorithm of a ImageViewDetail is valid
 func (v *validator) isImageViewCompressionAlgorithm(code string) error {
+	if ok, err := v.checkRule("ImageViewCompressionAlgorithm", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Agreement not required:
@@ -514,6 +584,9 @@ func (v *validator) isImageViewCompressionAlgorithm(code string) error {
 
 // isViewSideIndicator ensures ViewSideIndicator of a ImageViewDetail is valid
 func (v *validator) isViewSideIndicator(code int) error {
+	if ok, err := v.checkRule("ViewSideIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Front image view
@@ -527,6 +600,9 @@ func (v *validator) isViewSideIndicator(code int) error {
 
 // isViewDescriptor ensures ViewDescriptor of a ImageViewDetail is valid
 func (v *validator) isViewDescriptor(code string) error {
+	if ok, err := v.checkRule("ViewDescriptor", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Full view
@@ -566,6 +642,9 @@ func (v *validator) isViewDescriptor(code string) error {
 
 // isDigitalSignatureIndicator ensures DigitalSignatureIndicator of a ImageViewDetail is valid
 func (v *validator) isDigitalSignatureIndicator(code int) error {
+	if ok, err := v.checkRule("DigitalSignatureIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Digital Signature is not present
@@ -579,6 +658,9 @@ func (v *validator) isDigitalSignatureIndicator(code int) error {
 
 // isDigitalSignatureMethod ensures DigitalSignatureMethod of a ImageViewDetail is valid
 func (v *validator) isDigitalSignatureMethod(code string) error {
+	if ok, err := v.checkRule("DigitalSignatureMethod", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// 00: Digital Signature Algorithm (DSA) with SHA1 (ANSI X9.30)
@@ -600,6 +682,9 @@ func (v *validator) isDigitalSignatureMethod(code string) error {
 
 // isImageRecreateIndicator ensures ImageRecreateIndicator of a ImageViewDetail is valid
 func (v *validator) isImageRecreateIndicator(code int) error {
+	if ok, err := v.checkRule("ImageRecreateIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Sender can recreate the image view for the duration of the agreed upon retention time frames.
@@ -613,6 +698,9 @@ func (v *validator) isImageRecreateIndicator(code int) error {
 
 // isOverrideIndicator ensures OverrideIndicator of a ImageViewDetail is valid
 func (v *validator) isOverrideIndicator(code string) error {
+	if ok, err := v.checkRule("OverrideIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// blank/space indicates no observed image test failure present
@@ -660,6 +748,9 @@ func (v *validator) isOverrideIndicator(code string) error {
 
 // isImageViewAnalysisValid ensures generic properties of imageViewAnalysis are valid
 func (v *validator) isImageViewAnalysisValid(code string) error {
+	if ok, err := v.checkRule("ImageViewAnalysisValid", code); ok {
+		return err
+	}
 	switch code {
 	case
 		"",
@@ -678,6 +769,9 @@ func (v *validator) isImageViewAnalysisValid(code string) error {
 
 // isReturnNotificationIndicator ensures ReturnNotificationIndicator of ReturnDetail is valid
 func (v *validator) isReturnNotificationIndicator(code string) error {
+	if ok, err := v.checkRule("ReturnNotificationIndicator", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Preliminary notification
@@ -691,6 +785,9 @@ func (v *validator) isReturnNotificationIndicator(code string) error {
 
 // isTimesReturned ensures TimeReturned of ReturnDetail is valid
 func (v *validator) isTimesReturned(code int) error {
+	if ok, err := v.checkRule("TimesReturned", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// The item has been returned an unknown number of times
@@ -708,6 +805,9 @@ func (v *validator) isTimesReturned(code int) error {
 
 // isAccountTypeCode ensures AccountTypeCode of CheckItem is valid
 func (v *validator) isAccountTypeCode(code string) error {
+	if ok, err := v.checkRule("AccountTypeCode", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Unknown
@@ -730,6 +830,9 @@ func (v *validator) isAccountTypeCode(code string) error {
 
 // isSourceWorkCode ensures SourceWorkCode of CheckItem is valid
 func (v *validator) isSourceWorkCode(code string) error {
+	if ok, err := v.checkRule("SourceWorkCode", code); ok {
+		return err
+	}
 	switch code {
 	case
 		// Unknown
@@ -767,6 +870,9 @@ func (v *validator) isSourceWorkCode(code string) error {
 
 // isOwnerIdentifierIndicator ensures OwnerIdentifierIndicator of User* is valid
 func (v *validator) isOwnerIdentifierIndicator(code int) error {
+	if ok, err := v.checkRule("OwnerIdentifierIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Not Used
@@ -788,6 +894,9 @@ func (v *validator) isOwnerIdentifierIndicator(code int) error {
 
 // isEndorsementIndicator ensures EndorsementIndicator of UserPayeeEndorsement is valid
 func (v *validator) isEndorsementIndicator(code int) error {
+	if ok, err := v.checkRule("EndorsementIndicator", strconv.Itoa(code)); ok {
+		return err
+	}
 	switch code {
 	case
 		// Endorsed in Blank–Instrument becomes payable to bearer
@@ -22,6 +22,7 @@ type Writer struct {
 	lineNum            int //current line being written
 	VariableLineLength bool
 	EbcdicEncoding     bool
+	OriginalRecords    bool
 }
 
 // NewWriter returns a new Writer that writes to w.
@@ -54,7 +55,21 @@ func WriteEbcdicEncodingOption() WriterOption {
 	}
 }
 
-func (w *Writer) writeLine(record FileRecord) error {
+// WriteOriginalRecordsOption allows Writer to write records read with ReadOriginalRecordsOption exactly as they
+// were read when they are unchanged and the file is written in the encoding it was read in. Changed records are
+// written from their fields.
+func WriteOriginalRecordsOption() WriterOption {
+	return func(w *Writer) {
+		w.OriginalRecords = true
+	}
+}
+
+func (w *Writer) writeLine(record fmt.Stringer) error {
+	if w.OriginalRecords {
+		if raw, ok := originalLine(record, w.EbcdicEncoding); ok {
+			return w.writeOriginal(raw)
+		}
+	}
 	line := record.String()
 	lineLength := len(line)
 
@@ -106,6 +121,27 @@ func (w *Writer) writeLine(record FileRecord) error {
 	return nil
 }
 
+// writeOriginal writes a record exactly as it was read
+func (w *Writer) writeOriginal(raw string) error {
+	if w.VariableLineLength {
+		ctrl := make([]byte, 4)
+		binary.BigEndian.PutUint32(ctrl, uint32(len(raw)))
+		if _, err := w.w.Write(ctrl); err != nil {
+			return err
+		}
+	}
+	if _, err := w.w.WriteString(raw); err != nil {
+		return err
+	}
+	if !w.VariableLineLength {
+		if _, err := w.w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	w.lineNum++
+	return nil
+}
+
 // Writer writes a single imagecashletter.file record to w
 func (w *Writer) Write(file *File) error {
 	if file == nil {
@@ -146,9 +182,27 @@ func (w *Writer) writeCashLetter(file *File) error {
 				return err
 			}
 		}
+		for _, atd := range cl.GetAccountTotalsDetail() {
+			if err := w.writeLine(atd); err != nil {
+				return err
+			}
+		}
+		for _, nhtd := range cl.GetNonHitTotalsDetail() {
+			if err := w.writeLine(nhtd); err != nil {
+				return err
+			}
+		}
+		if err := w.writeCashLetterUserRecords(cl, 0); err != nil {
+			return err
+		}
 		if err := w.writeBundle(cl); err != nil {
 			return err
 		}
+		for _, bs := range cl.GetBoxSummary() {
+			if err := w.writeLine(bs); err != nil {
+				return err
+			}
+		}
 		for _, rns := range cl.GetRoutingNumberSummary() {
 			if err := w.writeLine(rns); err != nil {
 				return err
@@ -161,12 +215,34 @@ func (w *Writer) writeCashLetter(file *File) error {
 	return nil
 }
 
+// writeCashLetterUserRecords writes the UserRecords of a CashLetter which follow the number of Bundles
+func (w *Writer) writeCashLetterUserRecords(cl CashLetter, bundles int) error {
+	last := len(cl.GetBundles())
+	for i, ur := range cl.GetUserRecords() {
+		if userRecordPosition(cl.UserRecordPositions, i, 0, last) != bundles {
+			continue
+		}
+		if err := w.writeLine(ur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // writeBundle writes a Bundle to a CashLetter
 func (w *Writer) writeBundle(cl CashLetter) error {
-	for _, b := range cl.GetBundles() {
+	for i, b := range cl.GetBundles() {
 		if err := w.writeLine(b.GetHeader()); err != nil {
 			return err
 		}
+		for _, cr := range b.GetCredits() {
+			if err := w.writeLine(cr); err != nil {
+				return err
+			}
+		}
+		if err := w.writeUserRecords(b.GetUserRecords()); err != nil {
+			return err
+		}
 		if len(b.Checks) > 0 {
 			if err := w.writeCheckDetail(b); err != nil {
 				return err
@@ -180,6 +256,9 @@ func (w *Writer) writeBundle(cl CashLetter) error {
 		if err := w.writeLine(b.GetControl()); err != nil {
 			return err
 		}
+		if err := w.writeCashLetterUserRecords(cl, i+1); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -187,34 +266,43 @@ func (w *Writer) writeBundle(cl CashLetter) error {
 // writeCheckDetail writes a CheckDetail to a Bundle
 func (w *Writer) writeCheckDetail(b *Bundle) error {
 	for _, cd := range b.GetChecks() {
-		if err := w.writeLine(cd); err != nil {
-			return err
-		}
-		// Write CheckDetailsAddendum (A, B, C)
-		if err := w.writeCheckDetailAddendum(cd); err != nil {
-			return err
-		}
-		if err := w.writeCheckImageView(cd); err != nil {
+		if err := w.writeCheck(cd); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// writeCheck writes a CheckDetail followed by its CheckDetailAddendum, ImageView and User records
+func (w *Writer) writeCheck(cd *CheckDetail) error {
+	item := &itemUserRecords{urs: cd.GetUserRecords(), positions: cd.UserRecordPositions, records: cd.itemRecordCount()}
+	if err := w.writeItemLine(cd, item); err != nil {
+		return err
+	}
+	// Write CheckDetailsAddendum (A, B, C)
+	if err := w.writeCheckDetailAddendum(cd, item); err != nil {
+		return err
+	}
+	if err := w.writeCheckImageView(cd, item); err != nil {
+		return err
+	}
+	return w.writeItemUserRecords(item)
+}
+
 // writeCheckDetailAddendum writes a CheckDetailAddendum (A, B, C) to a CheckDetail
-func (w *Writer) writeCheckDetailAddendum(cd *CheckDetail) error {
+func (w *Writer) writeCheckDetailAddendum(cd *CheckDetail, item *itemUserRecords) error {
 	for _, cdAddendumA := range cd.GetCheckDetailAddendumA() {
-		if err := w.writeLine(&cdAddendumA); err != nil {
+		if err := w.writeItemLine(&cdAddendumA, item); err != nil {
 			return err
 		}
 	}
 	for _, cdAddendumB := range cd.GetCheckDetailAddendumB() {
-		if err := w.writeLine(&cdAddendumB); err != nil {
+		if err := w.writeItemLine(&cdAddendumB, item); err != nil {
 			return err
 		}
 	}
 	for _, cdAddendumC := range cd.GetCheckDetailAddendumC() {
-		if err := w.writeLine(&cdAddendumC); err != nil {
+		if err := w.writeItemLine(&cdAddendumC, item); err != nil {
 			return err
 		}
 	}
@@ -222,7 +310,7 @@ func (w *Writer) writeCheckDetailAddendum(cd *CheckDetail) error {
 }
 
 // writeCheckImageView writes ImageViews (Detail, Data, Analysis) to a CheckDetail
-func (w *Writer) writeCheckImageView(cd *CheckDetail) error {
+func (w *Writer) writeCheckImageView(cd *CheckDetail, item *itemUserRecords) error {
 
 	ivDetailSlice := cd.GetImageViewDetail()
 	ivDataSlice := cd.GetImageViewData()
@@ -245,18 +333,18 @@ func (w *Writer) writeCheckImageView(cd *CheckDetail) error {
 
 	// FRB asks that imageViewDetail should immediately be followed by its corresponding data and analysis
 	for i, ivDetail := range ivDetailSlice {
-		if err := w.writeLine(&ivDetail); err != nil {
+		if err := w.writeItemLine(&ivDetail, item); err != nil {
 			return err
 		}
 		if len(ivDataSlice) > 0 && len(ivDataSlice) >= i-1 {
 			ivData := ivDataSlice[i]
-			if err := w.writeLine(&ivData); err != nil {
+			if err := w.writeItemLine(&ivData, item); err != nil {
 				return err
 			}
 		}
 		if len(ivAnalysisSlice) > 0 && len(ivAnalysisSlice) >= i-1 {
 			ivAnalysis := ivAnalysisSlice[i]
-			if err := w.writeLine(&ivAnalysis); err != nil {
+			if err := w.writeItemLine(&ivAnalysis, item); err != nil {
 				return err
 			}
 		}
@@ -268,39 +356,48 @@ func (w *Writer) writeCheckImageView(cd *CheckDetail) error {
 // writeReturnDetail writes a ReturnDetail to a ReturnBundle
 func (w *Writer) writeReturnDetail(b *Bundle) error {
 	for _, rd := range b.GetReturns() {
-		if err := w.writeLine(rd); err != nil {
-			return err
-		}
-		// Write ReturnDetailAddendum (A, B, C, D)
-		if err := w.writeReturnDetailAddendum(rd); err != nil {
-			return err
-		}
-		if err := w.writeReturnImageView(rd); err != nil {
+		if err := w.writeReturn(rd); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// writeReturn writes a ReturnDetail followed by its ReturnDetailAddendum, ImageView and User records
+func (w *Writer) writeReturn(rd *ReturnDetail) error {
+	item := &itemUserRecords{urs: rd.GetUserRecords(), positions: rd.UserRecordPositions, records: rd.itemRecordCount()}
+	if err := w.writeItemLine(rd, item); err != nil {
+		return err
+	}
+	// Write ReturnDetailAddendum (A, B, C, D)
+	if err := w.writeReturnDetailAddendum(rd, item); err != nil {
+		return err
+	}
+	if err := w.writeReturnImageView(rd, item); err != nil {
+		return err
+	}
+	return w.writeItemUserRecords(item)
+}
+
 // writeReturnDetailAddendum writes a ReturnDetailAddendum (A, B, C, D) to a ReturnDetail
-func (w *Writer) writeReturnDetailAddendum(rd *ReturnDetail) error {
+func (w *Writer) writeReturnDetailAddendum(rd *ReturnDetail, item *itemUserRecords) error {
 	for _, rdAddendumA := range rd.GetReturnDetailAddendumA() {
-		if err := w.writeLine(&rdAddendumA); err != nil {
+		if err := w.writeItemLine(&rdAddendumA, item); err != nil {
 			return err
 		}
 	}
 	for _, rdAddendumB := range rd.GetReturnDetailAddendumB() {
-		if err := w.writeLine(&rdAddendumB); err != nil {
+		if err := w.writeItemLine(&rdAddendumB, item); err != nil {
 			return err
 		}
 	}
 	for _, rdAddendumC := range rd.GetReturnDetailAddendumC() {
-		if err := w.writeLine(&rdAddendumC); err != nil {
+		if err := w.writeItemLine(&rdAddendumC, item); err != nil {
 			return err
 		}
 	}
 	for _, rdAddendumD := range rd.GetReturnDetailAddendumD() {
-		if err := w.writeLine(&rdAddendumD); err != nil {
+		if err := w.writeItemLine(&rdAddendumD, item); err != nil {
 			return err
 		}
 	}
@@ -308,19 +405,70 @@ func (w *Writer) writeReturnDetailAddendum(rd *ReturnDetail) error {
 }
 
 // writeReturnImageView writes ImageViews (Detail, Data, Analysis) to a ReturnDetail
-func (w *Writer) writeReturnImageView(rd *ReturnDetail) error {
+func (w *Writer) writeReturnImageView(rd *ReturnDetail, item *itemUserRecords) error {
 	for _, ivDetail := range rd.GetImageViewDetail() {
-		if err := w.writeLine(&ivDetail); err != nil {
+		if err := w.writeItemLine(&ivDetail, item); err != nil {
 			return err
 		}
 	}
 	for _, ivData := range rd.GetImageViewData() {
-		if err := w.writeLine(&ivData); err != nil {
+		if err := w.writeItemLine(&ivData, item); err != nil {
 			return err
 		}
 	}
 	for _, ivAnalysis := range rd.GetImageViewAnalysis() {
-		if err := w.writeLine(&ivAnalysis); err != nil {
+		if err := w.writeItemLine(&ivAnalysis, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemUserRecords are the UserRecords of a CheckDetail or ReturnDetail, written following the records of the
+// item they were read after, see CheckDetail.UserRecordPositions.
+type itemUserRecords struct {
+	urs       UserRecords
+	positions []int
+	// records is the number of addendum and image view records of the item
+	records int
+	// written is the number of records of the item written, including the CheckDetail or ReturnDetail
+	written int
+}
+
+// writeItemLine writes a record of an item followed by the UserRecords at its position
+func (w *Writer) writeItemLine(record fmt.Stringer, item *itemUserRecords) error {
+	if err := w.writeLine(record); err != nil {
+		return err
+	}
+	item.written++
+	for i, ur := range item.urs {
+		if userRecordPosition(item.positions, i, item.records, item.records) != item.written-1 {
+			continue
+		}
+		if err := w.writeLine(ur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeItemUserRecords writes the UserRecords of an item which follow records that were not written
+func (w *Writer) writeItemUserRecords(item *itemUserRecords) error {
+	for i, ur := range item.urs {
+		if userRecordPosition(item.positions, i, item.records, item.records) < item.written {
+			continue
+		}
+		if err := w.writeLine(ur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeUserRecords writes the UserRecords which follow a CashLetter, Bundle or item
+func (w *Writer) writeUserRecords(urs UserRecords) error {
+	for _, ur := range urs {
+		if err := w.writeLine(ur); err != nil {
 			return err
 		}
 	}
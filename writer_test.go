@@ -320,7 +320,7 @@ func TestWriter__CollateErr(t *testing.T) {
 	var buf bytes.Buffer
 	w := NewWriter(&buf)
 
-	err := w.writeCheckImageView(cd)
+	err := w.writeCheckImageView(cd, &itemUserRecords{})
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -328,3 +328,215 @@ func TestWriter__CollateErr(t *testing.T) {
 		t.Errorf("unexpected error: %q", err)
 	}
 }
+
+// TestICLWriteTotalsRecords writes an ICL file with AccountTotalsDetail, NonHitTotalsDetail and BoxSummary
+// records
+func TestICLWriteTotalsRecords(t *testing.T) {
+	file := NewFile().SetHeader(mockFileHeader())
+
+	cd := mockCheckDetail()
+	cd.AddCheckDetailAddendumA(mockCheckDetailAddendumA())
+	cd.AddCheckDetailAddendumB(mockCheckDetailAddendumB())
+	cd.AddCheckDetailAddendumC(mockCheckDetailAddendumC())
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCheckDetail(cd)
+
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddAccountTotalsDetail(mockAccountTotalsDetail())
+	cl.AddNonHitTotalsDetail(mockNonHitTotalsDetail())
+	cl.AddBundle(bundle)
+	bs := mockBoxSummary()
+	bs.BoxBundleCount = 1
+	bs.BoxTotalAmount = cd.ItemAmount
+	cl.AddBoxSummary(bs)
+	if err := cl.Create(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if file.Control.TotalRecordCount != 13 {
+		t.Errorf("TotalRecordCount: %d", file.Control.TotalRecordCount)
+	}
+
+	tests := map[string]struct {
+		writeOpts []WriterOption
+		readOpts  []ReaderOption
+	}{
+		"ascii":  {},
+		"ebcdic": {[]WriterOption{WriteVariableLineLengthOption(), WriteEbcdicEncodingOption()}, []ReaderOption{ReadVariableLineLengthOption(), ReadEbcdicEncodingOption()}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := NewWriter(b, tc.writeOpts...).Write(file); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+
+			r := NewReader(bytes.NewReader(b.Bytes()), tc.readOpts...)
+			f, err := r.Read()
+			if err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			atd := f.CashLetters[0].GetAccountTotalsDetail()
+			if len(atd) != 1 || atd[0].String() != mockAccountTotalsDetail().String() {
+				t.Errorf("unexpected AccountTotalsDetail: %v", atd)
+			}
+			nhtd := f.CashLetters[0].GetNonHitTotalsDetail()
+			if len(nhtd) != 1 || nhtd[0].String() != mockNonHitTotalsDetail().String() {
+				t.Errorf("unexpected NonHitTotalsDetail: %v", nhtd)
+			}
+			boxes := f.CashLetters[0].GetBoxSummary()
+			if len(boxes) != 1 || boxes[0].String() != bs.String() {
+				t.Errorf("unexpected BoxSummary: %v", boxes)
+			}
+		})
+	}
+}
+
+// TestICLWriteCredit writes an ICL file with a Credit inside a Bundle
+func TestICLWriteCredit(t *testing.T) {
+	file := NewFile().SetHeader(mockFileHeader())
+
+	cd := mockCheckDetail()
+	cd.AddCheckDetailAddendumA(mockCheckDetailAddendumA())
+	cd.AddCheckDetailAddendumB(mockCheckDetailAddendumB())
+	cd.AddCheckDetailAddendumC(mockCheckDetailAddendumC())
+	bundle := NewBundle(mockBundleHeader())
+	bundle.AddCredit(mockCredit())
+	bundle.AddCheckDetail(cd)
+
+	cl := NewCashLetter(mockCashLetterHeader())
+	cl.AddBundle(bundle)
+	if err := cl.Create(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	file.AddCashLetter(cl)
+	if err := file.Create(); err != nil {
+		t.Errorf("%T: %s", err, err)
+	}
+	if file.Control.TotalRecordCount != 11 || file.Control.TotalItemCount != 2 || file.Control.FileTotalAmount != 200000 {
+		t.Errorf("unexpected FileControl: %s", file.Control.String())
+	}
+	if file.Control.CreditTotalIndicator != 1 {
+		t.Errorf("CreditTotalIndicator: %d", file.Control.CreditTotalIndicator)
+	}
+	clc := file.CashLetters[0].GetControl()
+	if clc.CashLetterItemsCount != 2 || clc.CashLetterTotalAmount != 200000 || clc.CreditTotalIndicator != 1 {
+		t.Errorf("unexpected CashLetterControl: %s", clc.String())
+	}
+
+	tests := map[string]struct {
+		writeOpts []WriterOption
+		readOpts  []ReaderOption
+	}{
+		"ascii":  {},
+		"ebcdic": {[]WriterOption{WriteVariableLineLengthOption(), WriteEbcdicEncodingOption()}, []ReaderOption{ReadVariableLineLengthOption(), ReadEbcdicEncodingOption()}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := NewWriter(b, tc.writeOpts...).Write(file); err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+
+			f, err := NewReader(bytes.NewReader(b.Bytes()), tc.readOpts...).Read()
+			if err != nil {
+				t.Fatalf("%T: %s", err, err)
+			}
+			credits := f.CashLetters[0].Bundles[0].GetCredits()
+			if len(credits) != 1 || credits[0].String() != mockCredit().String() {
+				t.Errorf("unexpected Credits: %v", credits)
+			}
+			if f.Control.String() != file.Control.String() {
+				t.Errorf("unexpected FileControl: %s", f.Control.String())
+			}
+		})
+	}
+}
+
+// TestWriter__OriginalRecords validates records read with ReadOriginalRecordsOption are written unchanged
+func TestWriter__OriginalRecords(t *testing.T) {
+	b := &bytes.Buffer{}
+	if err := NewWriter(b).Write(mockStreamFile(t)); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	// records with a filled reserved field and a lowercase field aren't written back the same from their fields
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i, line := range lines {
+		switch line[:2] {
+		case "10":
+			lines[i] = line[:79] + "X"
+		case "01":
+			lines[i] = strings.Replace(line, "US", "us", 1)
+		}
+	}
+	data := strings.Join(lines, "\n") + "\n"
+
+	file, err := NewReader(strings.NewReader(data)).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	out := &bytes.Buffer{}
+	if err := NewWriter(out).Write(&file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if out.String() == data {
+		t.Fatal("records were written unchanged without WriteOriginalRecordsOption")
+	}
+
+	file, err = NewReader(strings.NewReader(data), ReadOriginalRecordsOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if raw := string(file.CashLetters[0].CashLetterHeader.RawRecord()); raw != lines[1] {
+		t.Errorf("RawRecord: %q", raw)
+	}
+	out.Reset()
+	if err := NewWriter(out, WriteOriginalRecordsOption()).Write(&file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if out.String() != data {
+		t.Errorf("records were not written unchanged:\n%s", out.String())
+	}
+
+	// a changed record is written from its fields
+	file.CashLetters[0].CashLetterHeader.UserField = "Changed"
+	out.Reset()
+	if err := NewWriter(out, WriteOriginalRecordsOption()).Write(&file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	written := strings.Split(out.String(), "\n")
+	if written[0] != lines[0] || written[1] != file.CashLetters[0].CashLetterHeader.String() {
+		t.Errorf("unexpected records:\n%s\n%s", written[0], written[1])
+	}
+
+	// the original records are not written in another encoding
+	out.Reset()
+	if err := NewWriter(out, WriteOriginalRecordsOption(), WriteEbcdicEncodingOption()).Write(&file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if strings.Contains(out.String(), lines[0]) {
+		t.Error("ASCII record written in an EBCDIC file")
+	}
+}
+
+// TestWriter__OriginalRecordsEbcdic validates EBCDIC records are written unchanged
+func TestWriter__OriginalRecordsEbcdic(t *testing.T) {
+	fileBytes, err := ioutil.ReadFile(filepath.Join("test", "testdata", "valid-ebcdic.x937"))
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	file, err := NewReader(bytes.NewReader(fileBytes), ReadVariableLineLengthOption(), ReadEbcdicEncodingOption(), ReadOriginalRecordsOption()).Read()
+	if err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	b := &bytes.Buffer{}
+	if err := NewWriter(b, WriteVariableLineLengthOption(), WriteEbcdicEncodingOption(), WriteOriginalRecordsOption()).Write(&file); err != nil {
+		t.Fatalf("%T: %s", err, err)
+	}
+	if !bytes.Equal(fileBytes, b.Bytes()) {
+		t.Error("ICLs does not match")
+	}
+}